@@ -0,0 +1,92 @@
+package clients
+
+import (
+	"net/http"
+	"payment-service/config"
+	errGateway "payment-service/constants/error/gateway"
+	"payment-service/domain/dto"
+	"time"
+)
+
+type PaymentGateway interface {
+	Provider() string
+	SupportsCurrency(string) bool
+	CreatePaymentLink(*dto.PaymentRequest) (*dto.GatewayPaymentLink, error)
+	GetStatus(string) (*dto.GatewayNotification, error)
+	Cancel(string) error
+	Refund(string, *dto.GatewayRefundRequest) error
+	ParseWebhook(http.Header, []byte) (*dto.GatewayNotification, error)
+}
+
+// DirectCharger is implemented by gateways that can charge a payment method
+// directly and hand back the instructions instead of a hosted payment page.
+type DirectCharger interface {
+	Charge(*dto.PaymentRequest) (*dto.GatewayCharge, error)
+}
+
+// AccountLinker is implemented by gateways that can link an e-wallet account
+// so it can be charged later without the customer, e.g. for subscriptions.
+type AccountLinker interface {
+	LinkAccount(*dto.GatewayAccountLinkRequest) (*dto.GatewayAccountLink, error)
+}
+
+// Authorizer is implemented by gateways that can hold the funds of a card
+// payment and capture or release them later.
+type Authorizer interface {
+	Capture(*dto.GatewayCaptureRequest) (*dto.GatewayNotification, error)
+	Void(string) (*dto.GatewayNotification, error)
+}
+
+type IGatewayRegistry interface {
+	Get(string) (PaymentGateway, error)
+	Default() PaymentGateway
+	Route(*dto.PaymentRequest) (PaymentGateway, *dto.GatewayDecision, error)
+	SupportsCurrency(string) bool
+	Record(string, error)
+}
+
+type GatewayRegistry struct {
+	gateways        map[string]PaymentGateway
+	defaultProvider string
+	routing         config.GatewayRouting
+	health          *healthTracker
+}
+
+func NewGatewayRegistry(cfg config.Gateway, gateways ...PaymentGateway) IGatewayRegistry {
+	window := cfg.Routing.Failover.WindowInMinutes
+	if window <= 0 {
+		window = defaultFailoverWindowInMinutes
+	}
+	registry := &GatewayRegistry{
+		gateways:        make(map[string]PaymentGateway, len(gateways)),
+		defaultProvider: cfg.DefaultProvider,
+		routing:         cfg.Routing,
+		health:          newHealthTracker(time.Duration(window) * time.Minute),
+	}
+	for _, gateway := range gateways {
+		registry.gateways[gateway.Provider()] = gateway
+	}
+	return registry
+}
+
+func (g *GatewayRegistry) Get(provider string) (PaymentGateway, error) {
+	gateway, ok := g.gateways[provider]
+	if !ok {
+		return nil, errGateway.ErrGatewayNotSupported
+	}
+	return gateway, nil
+}
+
+func (g *GatewayRegistry) Default() PaymentGateway {
+	return g.gateways[g.defaultProvider]
+}
+
+// SupportsCurrency tells whether any gateway can take payments in currency.
+func (g *GatewayRegistry) SupportsCurrency(currency string) bool {
+	for _, gateway := range g.gateways {
+		if gateway.SupportsCurrency(currency) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package clients
+
+import (
+	"sync"
+	"time"
+)
+
+type outcome struct {
+	at     time.Time
+	failed bool
+}
+
+// healthTracker keeps the outcome of recent gateway calls per provider so the
+// router can move traffic away from a provider that keeps failing.
+type healthTracker struct {
+	mu       sync.Mutex
+	window   time.Duration
+	outcomes map[string][]outcome
+}
+
+func newHealthTracker(window time.Duration) *healthTracker {
+	return &healthTracker{
+		window:   window,
+		outcomes: make(map[string][]outcome),
+	}
+}
+
+func (h *healthTracker) record(provider string, failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	h.outcomes[provider] = append(h.prune(provider, now), outcome{at: now, failed: failed})
+}
+
+func (h *healthTracker) errorRate(provider string) (float64, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	outcomes := h.prune(provider, time.Now())
+	h.outcomes[provider] = outcomes
+	if len(outcomes) == 0 {
+		return 0, 0
+	}
+	failed := 0
+	for _, item := range outcomes {
+		if item.failed {
+			failed++
+		}
+	}
+	return float64(failed) / float64(len(outcomes)), len(outcomes)
+}
+
+func (h *healthTracker) prune(provider string, now time.Time) []outcome {
+	outcomes := h.outcomes[provider]
+	cutoff := now.Add(-h.window)
+	i := 0
+	for i < len(outcomes) && outcomes[i].at.Before(cutoff) {
+		i++
+	}
+	return outcomes[i:]
+}
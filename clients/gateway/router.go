@@ -0,0 +1,190 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"payment-service/config"
+	"payment-service/constants"
+	errGateway "payment-service/constants/error/gateway"
+	errPayment "payment-service/constants/error/payment"
+	"payment-service/domain/dto"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultFailoverWindowInMinutes = 5
+	defaultFailoverMinRequests     = 10
+	defaultFailoverErrorRate       = 0.5
+)
+
+// Route picks the gateway for a new payment. The first rule matching the
+// request decides the candidates, a weighted split among them is resolved by
+// hashing the order id so retries of one order land on the same provider, and
+// providers whose recent error rate is over the threshold are skipped, as are
+// those that do not take the currency of the request.
+func (g *GatewayRegistry) Route(request *dto.PaymentRequest) (PaymentGateway, *dto.GatewayDecision, error) {
+	candidates, rule, reason := g.candidates(request)
+	decision := &dto.GatewayDecision{Rule: rule}
+	currency := g.currency(request)
+
+	var fallback PaymentGateway
+	for _, provider := range candidates {
+		gateway, ok := g.gateways[provider]
+		if !ok {
+			continue
+		}
+		if !gateway.SupportsCurrency(currency) {
+			reason = fmt.Sprintf("%s; %s does not support %s", reason, provider, currency)
+			continue
+		}
+		if fallback == nil {
+			fallback = gateway
+		}
+		rate, unhealthy := g.unhealthy(provider)
+		if !unhealthy {
+			decision.Provider = provider
+			decision.Reason = reason
+			return gateway, decision, nil
+		}
+		reason = fmt.Sprintf("%s; %s error rate %.0f%% is over the threshold", reason, provider, rate*100)
+	}
+	if fallback == nil {
+		if !g.SupportsCurrency(currency) {
+			return nil, nil, errPayment.ErrCurrencyNotSupported
+		}
+		return nil, nil, errGateway.ErrGatewayNotSupported
+	}
+	decision.Provider = fallback.Provider()
+	decision.Reason = fmt.Sprintf("%s; every provider is unhealthy, keeping %s", reason, fallback.Provider())
+	return fallback, decision, nil
+}
+
+// Record counts a call towards the health of provider. Errors other than
+// ErrGatewayUnavailable are about the request, not the provider, and are left
+// out.
+func (g *GatewayRegistry) Record(provider string, err error) {
+	if err != nil && !errors.Is(err, errGateway.ErrGatewayUnavailable) {
+		return
+	}
+	g.health.record(provider, err != nil)
+}
+
+func (g *GatewayRegistry) candidates(request *dto.PaymentRequest) ([]string, *string, string) {
+	var (
+		candidates []string
+		rule       *string
+		reason     string
+	)
+	for _, item := range g.routing.Rules {
+		if len(item.Providers) == 0 || !g.matches(&item, request) {
+			continue
+		}
+		name := item.Name
+		rule = &name
+		selected, bucket := g.split(item.Providers, request.OrderID)
+		candidates = append(candidates, selected)
+		reason = fmt.Sprintf("rule %q matched", item.Name)
+		if len(item.Providers) > 1 {
+			reason = fmt.Sprintf("%s, split bucket %d selected %s", reason, bucket, selected)
+		}
+		for _, weight := range item.Providers {
+			candidates = append(candidates, weight.Provider)
+		}
+		break
+	}
+	if rule == nil {
+		reason = "no routing rule matched, using the default provider"
+	}
+
+	candidates = append(candidates, g.defaultProvider)
+	providers := make([]string, 0, len(g.gateways))
+	for provider := range g.gateways {
+		providers = append(providers, provider)
+	}
+	slices.Sort(providers)
+	candidates = append(candidates, providers...)
+
+	unique := make([]string, 0, len(candidates))
+	for _, provider := range candidates {
+		if !slices.Contains(unique, provider) {
+			unique = append(unique, provider)
+		}
+	}
+	return unique, rule, reason
+}
+
+func (g *GatewayRegistry) matches(rule *config.GatewayRoutingRule, request *dto.PaymentRequest) bool {
+	if rule.MinAmount != nil && request.Amount < *rule.MinAmount {
+		return false
+	}
+	if rule.MaxAmount != nil && request.Amount > *rule.MaxAmount {
+		return false
+	}
+	currency := g.currency(request)
+	var segment string
+	if request.CustomerDetail != nil {
+		segment = request.CustomerDetail.Segment
+	}
+	return g.contains(rule.PaymentMethods, request.PaymentMethod) &&
+		g.contains(rule.Currencies, currency) &&
+		g.contains(rule.Segments, segment)
+}
+
+func (g *GatewayRegistry) currency(request *dto.PaymentRequest) string {
+	if request.Currency == "" {
+		return constants.DefaultCurrency
+	}
+	return strings.ToUpper(request.Currency)
+}
+
+func (g *GatewayRegistry) contains(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, item := range values {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *GatewayRegistry) split(weights []config.GatewayWeight, orderID string) (string, int) {
+	total := 0
+	for _, weight := range weights {
+		total += max(weight.Weight, 0)
+	}
+	if total == 0 {
+		return weights[0].Provider, 0
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(orderID))
+	bucket := int(hash.Sum32() % uint32(total))
+	cumulative := 0
+	for _, weight := range weights {
+		cumulative += max(weight.Weight, 0)
+		if bucket < cumulative {
+			return weight.Provider, bucket
+		}
+	}
+	return weights[len(weights)-1].Provider, bucket
+}
+
+func (g *GatewayRegistry) unhealthy(provider string) (float64, bool) {
+	failover := g.routing.Failover
+	if !failover.Enabled {
+		return 0, false
+	}
+	minRequests := failover.MinRequests
+	if minRequests <= 0 {
+		minRequests = defaultFailoverMinRequests
+	}
+	threshold := failover.ErrorRateThreshold
+	if threshold <= 0 {
+		threshold = defaultFailoverErrorRate
+	}
+	rate, count := g.health.errorRate(provider)
+	return rate, count >= minRequests && rate >= threshold
+}
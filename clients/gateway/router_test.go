@@ -0,0 +1,162 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"payment-service/config"
+	"payment-service/constants"
+	errGateway "payment-service/constants/error/gateway"
+	errPayment "payment-service/constants/error/payment"
+	"payment-service/domain/dto"
+)
+
+type fakeGateway struct {
+	PaymentGateway
+	provider   string
+	currencies []string
+}
+
+func (f *fakeGateway) Provider() string {
+	return f.provider
+}
+
+func (f *fakeGateway) SupportsCurrency(currency string) bool {
+	return slices.Contains(f.currencies, currency)
+}
+
+func newRouter(routing config.GatewayRouting) *GatewayRegistry {
+	return NewGatewayRegistry(
+		config.Gateway{DefaultProvider: constants.ProviderMidtrans, Routing: routing},
+		&fakeGateway{provider: constants.ProviderMidtrans, currencies: []string{"IDR"}},
+		&fakeGateway{provider: constants.ProviderXendit, currencies: []string{"IDR", "USD"}},
+	).(*GatewayRegistry)
+}
+
+func TestRouteMatchesRules(t *testing.T) {
+	minAmount := 5000000.0
+	router := newRouter(config.GatewayRouting{Rules: []config.GatewayRoutingRule{
+		{
+			Name:      "large",
+			MinAmount: &minAmount,
+			Providers: []config.GatewayWeight{{Provider: constants.ProviderXendit, Weight: 1}},
+		},
+		{
+			Name:           "ewallet",
+			PaymentMethods: []string{"ewallet"},
+			Providers:      []config.GatewayWeight{{Provider: constants.ProviderXendit, Weight: 1}},
+		},
+	}})
+
+	tests := []struct {
+		name     string
+		request  dto.PaymentRequest
+		provider string
+		rule     string
+	}{
+		{name: "over the minimum amount", request: dto.PaymentRequest{Amount: 6000000}, provider: constants.ProviderXendit, rule: "large"},
+		{name: "method compared without case", request: dto.PaymentRequest{Amount: 10000, PaymentMethod: "EWALLET"}, provider: constants.ProviderXendit, rule: "ewallet"},
+		{name: "no rule matched", request: dto.PaymentRequest{Amount: 10000}, provider: constants.ProviderMidtrans},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway, decision, err := router.Route(&tt.request)
+			if err != nil {
+				t.Fatalf("Route unexpected error: %v", err)
+			}
+			if gateway.Provider() != tt.provider || decision.Provider != tt.provider {
+				t.Errorf("routed to %s, want %s", gateway.Provider(), tt.provider)
+			}
+			if tt.rule == "" && decision.Rule != nil || tt.rule != "" && (decision.Rule == nil || *decision.Rule != tt.rule) {
+				t.Errorf("rule = %v, want %q", decision.Rule, tt.rule)
+			}
+		})
+	}
+}
+
+func TestRouteSkipsUnsupportedCurrency(t *testing.T) {
+	router := newRouter(config.GatewayRouting{})
+
+	gateway, decision, err := router.Route(&dto.PaymentRequest{Currency: "usd"})
+	if err != nil {
+		t.Fatalf("Route unexpected error: %v", err)
+	}
+	if gateway.Provider() != constants.ProviderXendit {
+		t.Errorf("routed usd to %s, want xendit (%s)", gateway.Provider(), decision.Reason)
+	}
+
+	_, _, err = router.Route(&dto.PaymentRequest{Currency: "SGD"})
+	if !errors.Is(err, errPayment.ErrCurrencyNotSupported) {
+		t.Errorf("Route error = %v, want %v", err, errPayment.ErrCurrencyNotSupported)
+	}
+}
+
+func TestRouteSplitsByWeight(t *testing.T) {
+	router := newRouter(config.GatewayRouting{Rules: []config.GatewayRoutingRule{{
+		Name: "split",
+		Providers: []config.GatewayWeight{
+			{Provider: constants.ProviderMidtrans, Weight: 70},
+			{Provider: constants.ProviderXendit, Weight: 30},
+		},
+	}}})
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		request := &dto.PaymentRequest{OrderID: fmt.Sprintf("order-%d", i)}
+		gateway, _, err := router.Route(request)
+		if err != nil {
+			t.Fatalf("Route unexpected error: %v", err)
+		}
+		counts[gateway.Provider()]++
+
+		// Retries of one order stay with the same provider.
+		again, _, _ := router.Route(request)
+		if again.Provider() != gateway.Provider() {
+			t.Fatalf("order %s moved from %s to %s", request.OrderID, gateway.Provider(), again.Provider())
+		}
+	}
+	if counts[constants.ProviderMidtrans] < 600 || counts[constants.ProviderMidtrans] > 800 {
+		t.Errorf("split = %v, want about 70%% on midtrans", counts)
+	}
+}
+
+func TestRouteFailsOver(t *testing.T) {
+	router := newRouter(config.GatewayRouting{Failover: config.GatewayFailover{
+		Enabled:            true,
+		MinRequests:        4,
+		ErrorRateThreshold: 0.5,
+	}})
+	failure := fmt.Errorf("%w: connection refused", errGateway.ErrGatewayUnavailable)
+
+	// A request the gateway refuses says nothing about its health.
+	for i := 0; i < 4; i++ {
+		router.Record(constants.ProviderMidtrans, errPayment.ErrItemTotalMismatch)
+	}
+	if gateway, _, _ := router.Route(&dto.PaymentRequest{}); gateway.Provider() != constants.ProviderMidtrans {
+		t.Fatalf("routed to %s after refused requests, want midtrans", gateway.Provider())
+	}
+
+	for i := 0; i < 3; i++ {
+		router.Record(constants.ProviderMidtrans, failure)
+	}
+	gateway, _, _ := router.Route(&dto.PaymentRequest{})
+	if gateway.Provider() != constants.ProviderMidtrans {
+		t.Fatalf("routed to %s below the minimum request count, want midtrans", gateway.Provider())
+	}
+
+	router.Record(constants.ProviderMidtrans, failure)
+	gateway, decision, _ := router.Route(&dto.PaymentRequest{})
+	if gateway.Provider() != constants.ProviderXendit {
+		t.Errorf("routed to %s with midtrans failing, want xendit (%s)", gateway.Provider(), decision.Reason)
+	}
+
+	for i := 0; i < 4; i++ {
+		router.Record(constants.ProviderXendit, failure)
+	}
+	gateway, _, _ = router.Route(&dto.PaymentRequest{})
+	if gateway.Provider() != constants.ProviderMidtrans {
+		t.Errorf("routed to %s with every provider failing, want the default", gateway.Provider())
+	}
+}
@@ -1,16 +1,31 @@
 package clients
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
 	"github.com/midtrans/midtrans-go/snap"
 	"github.com/sirupsen/logrus"
+	"net/http"
+	gateway "payment-service/clients/gateway"
+	"payment-service/common/money"
+	"payment-service/common/utils"
+	"payment-service/constants"
+	errGateway "payment-service/constants/error/gateway"
 	errPayment "payment-service/constants/error/payment"
+	errSubscription "payment-service/constants/error/subscription"
 	"payment-service/domain/dto"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type IMidTransClient interface {
-	CreatePaymentLink(response *dto.PaymentRequest) (*MidTransData, error)
+	gateway.PaymentGateway
+	gateway.DirectCharger
+	gateway.AccountLinker
+	gateway.Authorizer
 }
 
 func NewMidTransClient(serverKey string, isProduction bool) IMidTransClient {
@@ -25,64 +40,689 @@ type MidTransClient struct {
 	IsProduction bool
 }
 
-func (m *MidTransClient) CreatePaymentLink(req *dto.PaymentRequest) (*MidTransData, error) {
-	var (
-		snapClient   snap.Client
-		isProduction = midtrans.Sandbox
-	)
+func (m *MidTransClient) environment() midtrans.EnvironmentType {
+	if m.IsProduction {
+		return midtrans.Production
+	}
+	return midtrans.Sandbox
+}
+
+func (m *MidTransClient) Provider() string {
+	return constants.ProviderMidtrans
+}
 
-	expiryDateTime := req.ExpiredAt
-	currentTime := time.Now()
+// SupportsCurrency is limited to rupiah, Midtrans settles nothing else.
+func (m *MidTransClient) SupportsCurrency(currency string) bool {
+	return strings.EqualFold(currency, constants.DefaultCurrency)
+}
 
-	duration := expiryDateTime.Sub(currentTime)
+// GetStatus fetches the current transaction status from Midtrans. The status
+// API answers with the same payload as the HTTP notification, so it is
+// normalised the same way a webhook is.
+func (m *MidTransClient) GetStatus(orderID string) (*dto.GatewayNotification, error) {
+	var coreClient coreapi.Client
+	coreClient.New(m.ServerKey, m.environment())
+	res, err := coreClient.CheckTransaction(orderID)
+	if err != nil {
+		logrus.Errorf("Error Check Transaction %s: %v", orderID, err)
+		if err.GetStatusCode() == http.StatusNotFound {
+			return nil, errPayment.ErrTransactionNotFound
+		}
+		return nil, err
+	}
+	if res.StatusCode == strconv.Itoa(http.StatusNotFound) {
+		return nil, errPayment.ErrTransactionNotFound
+	}
+
+	vaNumbers := make([]dto.VANumber, 0, len(res.VaNumbers))
+	for _, va := range res.VaNumbers {
+		vaNumbers = append(vaNumbers, dto.VANumber{
+			VANumber: va.VANumber,
+			Bank:     va.Bank,
+		})
+	}
+	paymentAmounts := make([]dto.PaymentAmount, 0, len(res.PaymentAmounts))
+	for _, amount := range res.PaymentAmounts {
+		paidAt, value := amount.PaidAt, amount.Amount
+		paymentAmounts = append(paymentAmounts, dto.PaymentAmount{
+			PaidAt: &paidAt,
+			Amount: &value,
+		})
+	}
+	return m.toNotification(&dto.Webhook{
+		VANumbers:         vaNumbers,
+		TransactionTime:   res.TransactionTime,
+		TransactionStatus: constants.PaymentStatusString(res.TransactionStatus),
+		TransactionId:     res.TransactionID,
+		StatusMessage:     res.StatusMessage,
+		StatusCode:        res.StatusCode,
+		SignatureKey:      res.SignatureKey,
+		SettlementTime:    res.SettlementTime,
+		PaymentType:       res.PaymentType,
+		PaymentAmounts:    paymentAmounts,
+		OrderId:           res.OrderID,
+		MerchantID:        res.MerchantID,
+		GrossAmount:       res.GrossAmount,
+		FraudStatus:       res.FraudStatus,
+		Currency:          res.Currency,
+		Acquirer:          res.Acquirer,
+		PermataVANumber:   res.PermataVaNumber,
+		MaskedCard:        res.MaskedCard,
+		CardType:          res.CardType,
+		Bank:              res.Bank,
+		ApprovalCode:      res.ApprovalCode,
+		ECI:               res.Eci,
+		BillKey:           res.BillKey,
+		BillerCode:        res.BillerCode,
+		Issuer:            res.Issuer,
+		Store:             res.Store,
+		PaymentCode:       res.PaymentCode,
+		RefundAmount:      res.RefundAmount,
+	}), nil
+}
+
+func (m *MidTransClient) Charge(req *dto.PaymentRequest) (*dto.GatewayCharge, error) {
+	duration := time.Until(req.ExpiredAt)
 	if duration <= 0 {
 		logrus.Errorf("Expired at is invalid")
 		return nil, errPayment.ErrExpireAtInvalid
 	}
-	expiryUnit := "minute"
-	expiryDuration := int64(duration.Minutes())
-	if duration.Hours() >= 1 {
-		expiryUnit = "hour"
-		expiryDuration = int64(duration.Hours())
-	} else if duration.Hours() >= 24 {
-		expiryUnit = "day"
-		expiryDuration = int64(duration.Hours() / 24)
-	}
 
-	if m.IsProduction {
-		isProduction = midtrans.Production
+	grossAmount, err := m.grossAmount(req)
+	if err != nil {
+		return nil, err
 	}
-	snapClient.New(m.ServerKey, isProduction)
-	midRequest := &snap.Request{
+	chargeRequest := &coreapi.ChargeReq{
 		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  req.OrderID,
-			GrossAmt: int64(req.Amount),
-		},
-		CustomerDetail: &midtrans.CustomerDetails{
-			FName: req.CustomerDetail.Name,
-			Email: req.CustomerDetail.Email,
-			Phone: req.CustomerDetail.Phone,
-		},
-		Items: &[]midtrans.ItemDetails{
-			{
-				ID:    req.ItemDetails[0].ID,
-				Name:  req.ItemDetails[0].Name,
-				Price: int64(req.ItemDetails[0].Amount),
-				Qty:   int32(req.ItemDetails[0].Quantity),
-			},
+			OrderID:  req.GatewayOrderID,
+			GrossAmt: grossAmount,
 		},
-		Expiry: &snap.ExpiryDetails{
-			Unit:     expiryUnit,
-			Duration: expiryDuration,
+		CustomerDetails: m.customer(req.CustomerDetail),
+		Items:           m.items(req.ItemDetails),
+		CustomExpiry: &coreapi.CustomExpiry{
+			ExpiryDuration: int(duration.Minutes()),
+			Unit:           "minute",
 		},
 	}
-	res, err := snapClient.CreateTransaction(midRequest)
+	chargeRequest.CustomField1 = req.CustomField1
+	chargeRequest.CustomField2 = req.CustomField2
+	chargeRequest.CustomField3 = req.CustomField3
+	switch req.PaymentMethod {
+	case constants.PaymentMethodBCAVA, constants.PaymentMethodBNIVA,
+		constants.PaymentMethodBRIVA, constants.PaymentMethodPermataVA:
+		chargeRequest.PaymentType = coreapi.PaymentTypeBankTransfer
+		chargeRequest.BankTransfer = &coreapi.BankTransferDetails{
+			Bank: midtrans.Bank(strings.TrimSuffix(req.PaymentMethod, "_va")),
+		}
+	case constants.PaymentMethodQRIS:
+		chargeRequest.PaymentType = coreapi.PaymentTypeQris
+		chargeRequest.Qris = &coreapi.QrisDetails{Acquirer: "gopay"}
+	case constants.PaymentMethodGopay:
+		chargeRequest.PaymentType = coreapi.PaymentTypeGopay
+		chargeRequest.Gopay = &coreapi.GopayDetails{}
+		if req.PaymentToken != "" {
+			optionToken, tokenErr := m.gopayOptionToken(req.PaymentToken)
+			if tokenErr != nil {
+				return nil, tokenErr
+			}
+			chargeRequest.Gopay.AccountID = req.PaymentToken
+			chargeRequest.Gopay.PaymentOptionToken = optionToken
+			chargeRequest.Gopay.Recurring = true
+		}
+	case constants.PaymentMethodCard:
+		if req.PaymentToken == "" {
+			return nil, errPayment.ErrPaymentMethodInvalid
+		}
+		chargeRequest.PaymentType = coreapi.PaymentTypeCreditCard
+		chargeRequest.CreditCard = &coreapi.CreditCardDetails{
+			TokenID:     req.PaymentToken,
+			SaveTokenID: req.CustomerID != nil,
+		}
+		if req.Authorize {
+			chargeRequest.CreditCard.Type = "authorize"
+		}
+	default:
+		return nil, errPayment.ErrPaymentMethodInvalid
+	}
+
+	var coreClient coreapi.Client
+	coreClient.New(m.ServerKey, m.environment())
+	res, midErr := coreClient.ChargeTransaction(chargeRequest)
+	if midErr != nil {
+		logrus.Errorf("Error Charge Transaction: %v", midErr)
+		return nil, m.unavailable(midErr)
+	}
+	if res.StatusCode != strconv.Itoa(http.StatusCreated) && res.StatusCode != strconv.Itoa(http.StatusOK) {
+		logrus.Errorf("Error Charge Transaction: %s %s", res.StatusCode, res.StatusMessage)
+		if statusCode, _ := strconv.Atoi(res.StatusCode); statusCode >= http.StatusInternalServerError {
+			return nil, fmt.Errorf("midtrans charge: %w: %s", errGateway.ErrGatewayUnavailable, res.StatusMessage)
+		}
+		return nil, fmt.Errorf("midtrans charge: %s", res.StatusMessage)
+	}
+
+	charge := &dto.GatewayCharge{TransactionID: res.TransactionID}
+	if res.ExpiryTime != "" {
+		expiredAt, parseErr := time.ParseInLocation(time.DateTime, res.ExpiryTime, time.Local)
+		if parseErr == nil {
+			charge.ExpiredAt = &expiredAt
+		}
+	}
+	instruction := &charge.Instruction
+	if len(res.VaNumbers) > 0 {
+		instruction.VANumber = &res.VaNumbers[0].VANumber
+		instruction.Bank = &res.VaNumbers[0].Bank
+	} else if res.PermataVaNumber != "" {
+		bank := string(midtrans.BankPermata)
+		instruction.VANumber = &res.PermataVaNumber
+		instruction.Bank = &bank
+	}
+	if res.QRString != "" {
+		instruction.QRString = &res.QRString
+	}
+	for _, action := range res.Actions {
+		url := action.URL
+		switch action.Name {
+		case "generate-qr-code":
+			instruction.QRImageURL = &url
+		case "deeplink-redirect":
+			instruction.Deeplink = &url
+		}
+	}
+	return charge, nil
+}
+
+func (m *MidTransClient) items(details []dto.ItemDetail) *[]midtrans.ItemDetails {
+	if len(details) == 0 {
+		return nil
+	}
+	items := make([]midtrans.ItemDetails, 0, len(details))
+	for _, item := range details {
+		items = append(items, midtrans.ItemDetails{
+			ID:    item.ID,
+			Name:  item.Name,
+			Price: m.amount(item.Amount),
+			Qty:   int32(item.Quantity),
+		})
+	}
+	return &items
+}
+
+func (m *MidTransClient) Cancel(orderID string) error {
+	var coreClient coreapi.Client
+	coreClient.New(m.ServerKey, m.environment())
+	_, err := coreClient.CancelTransaction(orderID)
+	if err == nil {
+		return nil
+	}
+	// Only card transactions can be cancelled, pending payments of the other
+	// methods are closed by expiring them instead.
+	logrus.Warnf("Cancel Transaction %s: %v, expiring instead", orderID, err)
+	_, err = coreClient.ExpireTransaction(orderID)
+	if err != nil {
+		logrus.Errorf("Error Expire Transaction %s: %v", orderID, err)
+		if err.GetStatusCode() == http.StatusNotFound {
+			return errPayment.ErrTransactionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (m *MidTransClient) Refund(orderID string, req *dto.GatewayRefundRequest) error {
+	var coreClient coreapi.Client
+	coreClient.New(m.ServerKey, m.environment())
+	_, err := coreClient.RefundTransaction(orderID, &coreapi.RefundReq{
+		RefundKey: req.RefundKey,
+		Amount:    m.amount(req.Amount),
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		logrus.Errorf("Error Refund Transaction %s: %v", orderID, err)
+		return err
+	}
+	return nil
+}
+
+// Capture takes the amount of an authorised card payment, an amount below the
+// authorised one releases the rest of the hold.
+func (m *MidTransClient) Capture(req *dto.GatewayCaptureRequest) (*dto.GatewayNotification, error) {
+	amount := money.FromMajor(req.Amount, constants.DefaultCurrency)
+	if !amount.Round(0).Equal(amount) {
+		return nil, errPayment.ErrAmountInvalid
+	}
+
+	var coreClient coreapi.Client
+	coreClient.New(m.ServerKey, m.environment())
+	res, midErr := coreClient.CaptureTransaction(&coreapi.CaptureReq{
+		TransactionID: req.TransactionID,
+		GrossAmt:      amount.Major(),
+	})
+	if midErr != nil {
+		logrus.Errorf("Error Capture Transaction %s: %v", req.TransactionID, midErr)
+		return nil, midErr
+	}
+	if res.StatusCode != strconv.Itoa(http.StatusOK) {
+		logrus.Errorf("Error Capture Transaction: %s %s", res.StatusCode, res.StatusMessage)
+		return nil, fmt.Errorf("midtrans capture: %s", res.StatusMessage)
+	}
+	return m.responseNotification(res), nil
+}
+
+// Void releases the hold of an authorised card payment, unlike Cancel it
+// never falls back to expiring the transaction.
+func (m *MidTransClient) Void(orderID string) (*dto.GatewayNotification, error) {
+	var coreClient coreapi.Client
+	coreClient.New(m.ServerKey, m.environment())
+	res, midErr := coreClient.CancelTransaction(orderID)
+	if midErr != nil {
+		logrus.Errorf("Error Void Transaction %s: %v", orderID, midErr)
+		if midErr.GetStatusCode() == http.StatusNotFound {
+			return nil, errPayment.ErrTransactionNotFound
+		}
+		return nil, midErr
+	}
+	if res.StatusCode != strconv.Itoa(http.StatusOK) {
+		logrus.Errorf("Error Void Transaction: %s %s", res.StatusCode, res.StatusMessage)
+		return nil, fmt.Errorf("midtrans void: %s", res.StatusMessage)
+	}
+	return m.responseNotification(res), nil
+}
+
+// responseNotification reads a capture or cancel response, which carries the
+// new status of the transaction just like its notification will.
+func (m *MidTransClient) responseNotification(res *coreapi.ChargeResponse) *dto.GatewayNotification {
+	return m.toNotification(&dto.Webhook{
+		TransactionTime:   res.TransactionTime,
+		TransactionStatus: constants.PaymentStatusString(res.TransactionStatus),
+		TransactionId:     res.TransactionID,
+		StatusMessage:     res.StatusMessage,
+		StatusCode:        res.StatusCode,
+		PaymentType:       res.PaymentType,
+		OrderId:           res.OrderID,
+		GrossAmount:       res.GrossAmount,
+		FraudStatus:       res.FraudStatus,
+		Currency:          res.Currency,
+		MaskedCard:        res.MaskedCard,
+		CardType:          res.CardType,
+		Bank:              res.Bank,
+		ApprovalCode:      res.ApprovalCode,
+		ECI:               res.Eci,
+	})
+}
+
+// ParseWebhook verifies the notification signature, which Midtrans computes as
+// SHA512(order_id + status_code + gross_amount + server key).
+func (m *MidTransClient) ParseWebhook(_ http.Header, body []byte) (*dto.GatewayNotification, error) {
+	var webhook dto.Webhook
+	err := json.Unmarshal(body, &webhook)
+	if err != nil {
+		return nil, errGateway.ErrWebhookPayloadInvalid
+	}
+	signature := utils.GenerateSHA512(webhook.OrderId + webhook.StatusCode + webhook.GrossAmount + m.ServerKey)
+	if signature != webhook.SignatureKey {
+		logrus.Errorf("Invalid Midtrans signature for order %s", webhook.OrderId)
+		return nil, errGateway.ErrWebhookSignatureInvalid
+	}
+	return m.toNotification(&webhook), nil
+}
+
+// methodDetail normalises the payment type specific fields Midtrans sends,
+// only bank transfers carry va_numbers, the other types have their own.
+func (m *MidTransClient) methodDetail(webhook *dto.Webhook) dto.PaymentMethodDetail {
+	detail := dto.PaymentMethodDetail{Type: webhook.PaymentType}
+	switch webhook.PaymentType {
+	case "bank_transfer":
+		if len(webhook.VANumbers) > 0 {
+			detail.Channel = webhook.VANumbers[0].Bank
+			detail.Reference = webhook.VANumbers[0].VANumber
+		} else if webhook.PermataVANumber != "" {
+			detail.Channel = string(midtrans.BankPermata)
+			detail.Reference = webhook.PermataVANumber
+		}
+	case "echannel":
+		detail.Channel = string(midtrans.BankMandiri)
+		detail.Reference = fmt.Sprintf("%s/%s", webhook.BillerCode, webhook.BillKey)
+	case "credit_card":
+		detail.Channel = webhook.Bank
+		detail.Reference = webhook.MaskedCard
+		detail.ApprovalCode = webhook.ApprovalCode
+	case "qris":
+		detail.Channel = webhook.Issuer
+		if detail.Channel == "" {
+			detail.Channel = webhook.Acquirer
+		}
+	case "gopay", "shopeepay":
+		detail.Channel = webhook.PaymentType
+	case "cstore":
+		detail.Channel = webhook.Store
+		detail.Reference = webhook.PaymentCode
+	default:
+		detail.Channel = webhook.Issuer
+	}
+	return detail
+}
+
+func (m *MidTransClient) LinkAccount(req *dto.GatewayAccountLinkRequest) (*dto.GatewayAccountLink, error) {
+	partner := &coreapi.GopayPartnerDetails{
+		PhoneNumber: m.localPhoneNumber(req.Phone),
+		CountryCode: "62",
+	}
+	if req.RedirectURL != nil {
+		partner.RedirectURL = *req.RedirectURL
+	}
+
+	var coreClient coreapi.Client
+	coreClient.New(m.ServerKey, m.environment())
+	res, midErr := coreClient.LinkPaymentAccount(&coreapi.PaymentAccountReq{
+		PaymentType:  coreapi.PaymentTypeGopay,
+		GopayPartner: partner,
+	})
+	if midErr != nil {
+		logrus.Errorf("Error Link Payment Account: %v", midErr)
+		return nil, midErr
+	}
+
+	link := &dto.GatewayAccountLink{
+		AccountID: res.AccountId,
+		Status:    res.AccountStatus,
+	}
+	for _, action := range res.Actions {
+		url := action.URL
+		if action.Name == "activation-link-url" || link.ActivationURL == nil {
+			link.ActivationURL = &url
+		}
+	}
+	return link, nil
+}
+
+// gopayOptionToken picks the wallet of a linked GoPay account, its token is
+// required next to the account id for every recurring charge.
+func (m *MidTransClient) gopayOptionToken(accountID string) (string, error) {
+	var coreClient coreapi.Client
+	coreClient.New(m.ServerKey, m.environment())
+	res, midErr := coreClient.GetPaymentAccount(accountID)
+	if midErr != nil {
+		logrus.Errorf("Error Get Payment Account %s: %v", accountID, midErr)
+		return "", midErr
+	}
+	if res.AccountStatus != "ENABLED" {
+		return "", errSubscription.ErrPaymentAccountNotActive
+	}
+	for _, option := range res.Metadata.PaymentOptions {
+		if option.Name == "GOPAY_WALLET" && option.Active {
+			return option.Token, nil
+		}
+	}
+	return "", errSubscription.ErrPaymentAccountNotActive
+}
+
+// localPhoneNumber strips the country prefix GoPay linking expects in
+// country_code instead.
+func (m *MidTransClient) localPhoneNumber(phone string) string {
+	phone = strings.TrimPrefix(strings.TrimSpace(phone), "+")
+	phone = strings.TrimPrefix(phone, "62")
+	return strings.TrimPrefix(phone, "0")
+}
+
+func (m *MidTransClient) paymentAmounts(amounts []dto.PaymentAmount) []dto.GatewayPaidAmount {
+	paidAmounts := make([]dto.GatewayPaidAmount, 0, len(amounts))
+	for _, amount := range amounts {
+		if amount.Amount == nil {
+			continue
+		}
+		paidAmount := dto.GatewayPaidAmount{Amount: *amount.Amount}
+		if amount.PaidAt != nil {
+			paidAt, err := time.ParseInLocation(time.DateTime, *amount.PaidAt, time.Local)
+			if err == nil {
+				paidAmount.PaidAt = &paidAt
+			}
+		}
+		paidAmounts = append(paidAmounts, paidAmount)
+	}
+	return paidAmounts
+}
+
+func (m *MidTransClient) toNotification(webhook *dto.Webhook) *dto.GatewayNotification {
+	notification := &dto.GatewayNotification{
+		Provider:          constants.ProviderMidtrans,
+		OrderID:           webhook.OrderId,
+		TransactionID:     webhook.TransactionId,
+		TransactionStatus: m.transactionStatus(webhook),
+		PaymentType:       webhook.PaymentType,
+		Acquirer:          webhook.Acquirer,
+		GrossAmount:       webhook.GrossAmount,
+		Currency:          webhook.Currency,
+		PaymentAmounts:    m.paymentAmounts(webhook.PaymentAmounts),
+		RefundAmount:      webhook.RefundAmount,
+		MethodDetail:      m.methodDetail(webhook),
+	}
+	detail := notification.MethodDetail
+	switch detail.Type {
+	case "bank_transfer":
+		notification.VANumber = &detail.Reference
+		notification.Bank = &detail.Channel
+	case "credit_card":
+		if detail.Channel != "" {
+			notification.Bank = &detail.Channel
+		}
+	}
+	if webhook.SettlementTime != "" {
+		settlementTime, err := time.ParseInLocation(time.DateTime, webhook.SettlementTime, time.Local)
+		if err == nil {
+			notification.PaidAt = &settlementTime
+		}
+	}
+	if webhook.SavedTokenID != "" {
+		notification.SavedCard = m.savedCard(webhook)
+	}
+	return notification
+}
+
+// transactionStatus maps the card statuses Midtrans uses on top of the common
+// ones: authorize holds the funds and capture takes them, unless the fraud
+// detection challenged the transaction.
+func (m *MidTransClient) transactionStatus(webhook *dto.Webhook) constants.PaymentStatusString {
+	switch webhook.TransactionStatus {
+	case "authorize":
+		return constants.AuthorizedString
+	case "capture":
+		if webhook.FraudStatus == "challenge" {
+			return constants.PendingString
+		}
+		return constants.SettlementString
+	}
+	return webhook.TransactionStatus
+}
+
+func (m *MidTransClient) savedCard(webhook *dto.Webhook) *dto.GatewaySavedCard {
+	card := &dto.GatewaySavedCard{
+		Token:      webhook.SavedTokenID,
+		MaskedCard: webhook.MaskedCard,
+		Brand:      m.cardBrand(webhook.MaskedCard),
+		Bank:       webhook.Bank,
+		CardType:   webhook.CardType,
+	}
+	if webhook.SavedTokenExpiry != "" {
+		expiredAt, err := time.ParseInLocation(time.DateTime, webhook.SavedTokenExpiry, time.Local)
+		if err == nil {
+			card.ExpiredAt = &expiredAt
+		}
+	}
+	return card
+}
+
+// cardBrand reads the network from the first digits of the masked card
+// number since Midtrans does not send it in the notification.
+func (m *MidTransClient) cardBrand(maskedCard string) string {
+	switch {
+	case strings.HasPrefix(maskedCard, "4"):
+		return "visa"
+	case strings.HasPrefix(maskedCard, "5"), strings.HasPrefix(maskedCard, "2"):
+		return "mastercard"
+	case strings.HasPrefix(maskedCard, "34"), strings.HasPrefix(maskedCard, "37"):
+		return "amex"
+	case strings.HasPrefix(maskedCard, "35"):
+		return "jcb"
+	default:
+		return "unknown"
+	}
+}
+
+func (m *MidTransClient) CreatePaymentLink(req *dto.PaymentRequest) (*dto.GatewayPaymentLink, error) {
+	var snapClient snap.Client
+
+	expiry, err := m.expiry(req)
 	if err != nil {
-		logrus.Errorf("Error Create Transaction: %v", err)
 		return nil, err
 	}
-	return &MidTransData{
+	items := m.items(req.ItemDetails)
+	grossAmount, err := m.grossAmount(req)
+	if err != nil {
+		return nil, err
+	}
+	if m.itemTotal(items) != grossAmount {
+		logrus.Errorf("Item total does not match gross amount %d", grossAmount)
+		return nil, errPayment.ErrItemTotalMismatch
+	}
+
+	snapClient.New(m.ServerKey, m.environment())
+	midRequest := &snap.Request{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  req.GatewayOrderID,
+			GrossAmt: grossAmount,
+		},
+		CustomerDetail: m.customer(req.CustomerDetail),
+		Items:          items,
+		Expiry:         expiry,
+	}
+	for _, enabledPayment := range req.EnabledPayments {
+		midRequest.EnabledPayments = append(midRequest.EnabledPayments, snap.SnapPaymentType(enabledPayment))
+	}
+	if req.CustomField1 != nil {
+		midRequest.CustomField1 = *req.CustomField1
+	}
+	if req.CustomField2 != nil {
+		midRequest.CustomField2 = *req.CustomField2
+	}
+	if req.CustomField3 != nil {
+		midRequest.CustomField3 = *req.CustomField3
+	}
+	if req.FinishURL != nil {
+		midRequest.Callbacks = &snap.Callbacks{Finish: *req.FinishURL}
+	}
+	if req.CallbackURL != nil {
+		midRequest.Gopay = &snap.GopayDetails{
+			EnableCallback: true,
+			CallbackUrl:    *req.CallbackURL,
+		}
+		midRequest.ShopeePay = &snap.ShopeePayDetails{CallbackUrl: *req.CallbackURL}
+	}
+	if req.CustomerID != nil {
+		midRequest.UserId = req.CustomerID.String()
+		midRequest.CreditCard = &snap.CreditCardDetails{SaveCard: true}
+	}
+	if req.Authorize {
+		if midRequest.CreditCard == nil {
+			midRequest.CreditCard = &snap.CreditCardDetails{}
+		}
+		midRequest.CreditCard.Type = "authorize"
+		midRequest.CreditCard.Secure = true
+		midRequest.EnabledPayments = []snap.SnapPaymentType{snap.PaymentTypeCreditCard}
+	}
+
+	res, midErr := snapClient.CreateTransaction(midRequest)
+	if midErr != nil {
+		logrus.Errorf("Error Create Transaction: %v", midErr)
+		return nil, m.unavailable(midErr)
+	}
+	return &dto.GatewayPaymentLink{
+		Reference:   res.Token,
 		RedirectURL: res.RedirectURL,
-		Token:       res.Token,
 	}, nil
 }
+
+// unavailable marks the errors of a Midtrans that could not be reached, the
+// client reports those without a status code, or that failed on its side.
+func (m *MidTransClient) unavailable(err *midtrans.Error) error {
+	statusCode := err.GetStatusCode()
+	if statusCode == 0 || statusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %v", errGateway.ErrGatewayUnavailable, err)
+	}
+	return err
+}
+
+// expiry counts the payment lifetime in minutes from the custom start time
+// when one is given, otherwise from now, Midtrans then starts the clock at the
+// transaction time.
+func (m *MidTransClient) expiry(req *dto.PaymentRequest) (*snap.ExpiryDetails, error) {
+	expiry := &snap.ExpiryDetails{Unit: "minute"}
+	start := time.Now()
+	if req.ExpiryStartTime != nil {
+		start = *req.ExpiryStartTime
+		expiry.StartTime = start.Format("2006-01-02 15:04:05 -0700")
+	}
+	expiry.Duration = int64(req.ExpiredAt.Sub(start).Minutes())
+	if expiry.Duration <= 0 {
+		logrus.Errorf("Expired at is invalid")
+		return nil, errPayment.ErrExpireAtInvalid
+	}
+	return expiry, nil
+}
+
+func (m *MidTransClient) customer(detail *dto.CustomerDetail) *midtrans.CustomerDetails {
+	if detail == nil {
+		return nil
+	}
+	return &midtrans.CustomerDetails{
+		FName:    detail.Name,
+		Email:    detail.Email,
+		Phone:    detail.Phone,
+		BillAddr: m.address(detail.BillingAddress),
+		ShipAddr: m.address(detail.ShippingAddress),
+	}
+}
+
+func (m *MidTransClient) address(address *dto.Address) *midtrans.CustomerAddress {
+	if address == nil {
+		return nil
+	}
+	return &midtrans.CustomerAddress{
+		FName:       address.FirstName,
+		LName:       address.LastName,
+		Phone:       address.Phone,
+		Address:     address.Address,
+		City:        address.City,
+		Postcode:    address.PostalCode,
+		CountryCode: address.CountryCode,
+	}
+}
+
+// amount converts to the whole rupiah Midtrans expects, rounding instead of
+// truncating so 9999.999 is not sent as 9999.
+func (m *MidTransClient) amount(value float64) int64 {
+	return int64(money.FromMajor(value, constants.DefaultCurrency).Round(0).Major())
+}
+
+// grossAmount rejects amounts Midtrans would round, a notification for the
+// rounded amount would no longer match the stored payment.
+func (m *MidTransClient) grossAmount(req *dto.PaymentRequest) (int64, error) {
+	if req.Currency != "" && req.Currency != constants.DefaultCurrency {
+		return 0, errPayment.ErrCurrencyNotSupported
+	}
+	amount := money.FromMajor(req.Amount, constants.DefaultCurrency)
+	if !amount.Round(0).Equal(amount) {
+		return 0, errPayment.ErrAmountInvalid
+	}
+	return int64(amount.Major()), nil
+}
+
+func (m *MidTransClient) itemTotal(items *[]midtrans.ItemDetails) int64 {
+	var total int64
+	if items == nil {
+		return total
+	}
+	for _, item := range *items {
+		total += item.Price * int64(item.Qty)
+	}
+	return total
+}
@@ -0,0 +1,169 @@
+package clients
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/midtrans/midtrans-go"
+	errGateway "payment-service/constants/error/gateway"
+	errPayment "payment-service/constants/error/payment"
+	"payment-service/domain/dto"
+)
+
+func TestExpiry(t *testing.T) {
+	client := &MidTransClient{}
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+
+	expiry, err := client.expiry(&dto.PaymentRequest{ExpiryStartTime: &start, ExpiredAt: start.Add(90 * time.Minute)})
+	if err != nil {
+		t.Fatalf("expiry unexpected error: %v", err)
+	}
+	if expiry.Unit != "minute" || expiry.Duration != 90 || expiry.StartTime != "2024-05-01 10:00:00 +0700" {
+		t.Errorf("expiry = %+v, want 90 minutes from 2024-05-01 10:00:00 +0700", expiry)
+	}
+
+	_, err = client.expiry(&dto.PaymentRequest{ExpiryStartTime: &start, ExpiredAt: start})
+	if !errors.Is(err, errPayment.ErrExpireAtInvalid) {
+		t.Errorf("expiry error = %v, want %v", err, errPayment.ErrExpireAtInvalid)
+	}
+}
+
+func TestCustomer(t *testing.T) {
+	client := &MidTransClient{}
+	if client.customer(nil) != nil {
+		t.Error("customer(nil) is not nil")
+	}
+
+	customer := client.customer(&dto.CustomerDetail{
+		Name:           "Budi",
+		Email:          "budi@example.com",
+		BillingAddress: &dto.Address{FirstName: "Budi", Address: "Jl. Sudirman 1", City: "Jakarta", CountryCode: "IDN"},
+	})
+	if customer.FName != "Budi" || customer.Email != "budi@example.com" {
+		t.Errorf("customer = %+v, want Budi", customer)
+	}
+	if customer.BillAddr == nil || customer.BillAddr.City != "Jakarta" || customer.BillAddr.CountryCode != "IDN" {
+		t.Errorf("billing address = %+v, want Jakarta IDN", customer.BillAddr)
+	}
+	if customer.ShipAddr != nil {
+		t.Errorf("shipping address = %+v, want none", customer.ShipAddr)
+	}
+}
+
+func TestItemsRoundToWholeRupiah(t *testing.T) {
+	client := &MidTransClient{}
+	items := client.items([]dto.ItemDetail{
+		{ID: "a", Name: "A", Amount: 9999.999, Quantity: 2},
+		{ID: "b", Name: "B", Amount: 500, Quantity: 1},
+	})
+	if (*items)[0].Price != 10000 || (*items)[0].Qty != 2 {
+		t.Errorf("first item = %+v, want 2 x 10000", (*items)[0])
+	}
+	if total := client.itemTotal(items); total != 20500 {
+		t.Errorf("item total = %d, want 20500", total)
+	}
+}
+
+func TestCreatePaymentLinkRejectsItemMismatch(t *testing.T) {
+	client := &MidTransClient{}
+	_, err := client.CreatePaymentLink(&dto.PaymentRequest{
+		Amount:      10000,
+		ItemDetails: []dto.ItemDetail{{ID: "a", Name: "A", Amount: 9000, Quantity: 1}},
+		ExpiredAt:   time.Now().Add(time.Hour),
+	})
+	if !errors.Is(err, errPayment.ErrItemTotalMismatch) {
+		t.Errorf("CreatePaymentLink error = %v, want %v", err, errPayment.ErrItemTotalMismatch)
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	client := &MidTransClient{}
+	tests := []struct {
+		name       string
+		statusCode int
+		want       bool
+	}{
+		{name: "not reached", statusCode: 0, want: true},
+		{name: "server error", statusCode: http.StatusServiceUnavailable, want: true},
+		{name: "request refused", statusCode: http.StatusBadRequest},
+		{name: "unauthorized", statusCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.unavailable(&midtrans.Error{Message: tt.name, StatusCode: tt.statusCode})
+			if got := errors.Is(err, errGateway.ErrGatewayUnavailable); got != tt.want {
+				t.Errorf("unavailable(%d) = %v, want %v", tt.statusCode, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestToNotificationPaymentTypes(t *testing.T) {
+	client := &MidTransClient{}
+	tests := []struct {
+		name     string
+		webhook  dto.Webhook
+		want     dto.PaymentMethodDetail
+		wantBank string
+		wantVA   string
+	}{
+		{
+			name:     "bank transfer",
+			webhook:  dto.Webhook{PaymentType: "bank_transfer", VANumbers: []dto.VANumber{{VANumber: "8808123", Bank: "bca"}}},
+			want:     dto.PaymentMethodDetail{Type: "bank_transfer", Channel: "bca", Reference: "8808123"},
+			wantBank: "bca",
+			wantVA:   "8808123",
+		},
+		{
+			name:     "permata",
+			webhook:  dto.Webhook{PaymentType: "bank_transfer", PermataVANumber: "8778001"},
+			want:     dto.PaymentMethodDetail{Type: "bank_transfer", Channel: "permata", Reference: "8778001"},
+			wantBank: "permata",
+			wantVA:   "8778001",
+		},
+		{
+			name:    "mandiri bill payment",
+			webhook: dto.Webhook{PaymentType: "echannel", BillerCode: "70012", BillKey: "123456"},
+			want:    dto.PaymentMethodDetail{Type: "echannel", Channel: "mandiri", Reference: "70012/123456"},
+		},
+		{
+			name:     "credit card",
+			webhook:  dto.Webhook{PaymentType: "credit_card", Bank: "bni", MaskedCard: "481111-1114", ApprovalCode: "1578"},
+			want:     dto.PaymentMethodDetail{Type: "credit_card", Channel: "bni", Reference: "481111-1114", ApprovalCode: "1578"},
+			wantBank: "bni",
+		},
+		{
+			name:    "qris without issuer",
+			webhook: dto.Webhook{PaymentType: "qris", Acquirer: "gopay"},
+			want:    dto.PaymentMethodDetail{Type: "qris", Channel: "gopay"},
+		},
+		{
+			name:    "convenience store",
+			webhook: dto.Webhook{PaymentType: "cstore", Store: "indomaret", PaymentCode: "25129"},
+			want:    dto.PaymentMethodDetail{Type: "cstore", Channel: "indomaret", Reference: "25129"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := client.toNotification(&tt.webhook)
+			if notification.MethodDetail != tt.want {
+				t.Errorf("method detail = %+v, want %+v", notification.MethodDetail, tt.want)
+			}
+			if got := stringValue(notification.Bank); got != tt.wantBank {
+				t.Errorf("bank = %q, want %q", got, tt.wantBank)
+			}
+			if got := stringValue(notification.VANumber); got != tt.wantVA {
+				t.Errorf("va number = %q, want %q", got, tt.wantVA)
+			}
+		})
+	}
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,60 @@
+package clients
+
+type XenditInvoiceRequest struct {
+	ExternalID         string          `json:"external_id"`
+	Amount             float64         `json:"amount"`
+	Description        string          `json:"description,omitempty"`
+	InvoiceDuration    int64           `json:"invoice_duration"`
+	Currency           string          `json:"currency"`
+	PayerEmail         string          `json:"payer_email,omitempty"`
+	Customer           *XenditCustomer `json:"customer,omitempty"`
+	Items              []XenditItem    `json:"items,omitempty"`
+	Fees               []XenditFee     `json:"fees,omitempty"`
+	SuccessRedirectURL string          `json:"success_redirect_url,omitempty"`
+	FailureRedirectURL string          `json:"failure_redirect_url,omitempty"`
+}
+
+type XenditCustomer struct {
+	GivenNames   string `json:"given_names"`
+	Email        string `json:"email,omitempty"`
+	MobileNumber string `json:"mobile_number,omitempty"`
+}
+
+type XenditItem struct {
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+type XenditFee struct {
+	Type  string  `json:"type"`
+	Value float64 `json:"value"`
+}
+
+type XenditInvoice struct {
+	ID                 string  `json:"id"`
+	ExternalID         string  `json:"external_id"`
+	Status             string  `json:"status"`
+	Amount             float64 `json:"amount"`
+	PaidAmount         float64 `json:"paid_amount"`
+	Currency           string  `json:"currency"`
+	InvoiceURL         string  `json:"invoice_url"`
+	ExpiryDate         string  `json:"expiry_date"`
+	PaidAt             string  `json:"paid_at"`
+	PaymentMethod      string  `json:"payment_method"`
+	PaymentChannel     string  `json:"payment_channel"`
+	PaymentDestination string  `json:"payment_destination"`
+	BankCode           string  `json:"bank_code"`
+}
+
+type XenditRefundRequest struct {
+	InvoiceID   string  `json:"invoice_id"`
+	ReferenceID string  `json:"reference_id,omitempty"`
+	Amount      float64 `json:"amount"`
+	Reason      string  `json:"reason"`
+}
+
+type XenditErrorResponse struct {
+	ErrorCode string `json:"error_code"`
+	Message   string `json:"message"`
+}
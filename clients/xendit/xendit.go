@@ -0,0 +1,281 @@
+package clients
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"github.com/parnurzeal/gorequest"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"payment-service/clients/config"
+	gateway "payment-service/clients/gateway"
+	"payment-service/constants"
+	errGateway "payment-service/constants/error/gateway"
+	errPayment "payment-service/constants/error/payment"
+	"payment-service/domain/dto"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const callbackTokenHeader = "x-callback-token"
+
+type IXenditClient interface {
+	gateway.PaymentGateway
+}
+
+type XenditClient struct {
+	client        config.IClientConfig
+	callbackToken string
+}
+
+func NewXenditClient(client config.IClientConfig, callbackToken string) IXenditClient {
+	return &XenditClient{
+		client:        client,
+		callbackToken: callbackToken,
+	}
+}
+
+func (x *XenditClient) Provider() string {
+	return constants.ProviderXendit
+}
+
+// supportedCurrencies are those Xendit invoices can be issued in.
+var supportedCurrencies = []string{"IDR", "PHP", "THB", "VND", "MYR", "USD"}
+
+func (x *XenditClient) SupportsCurrency(currency string) bool {
+	return slices.Contains(supportedCurrencies, strings.ToUpper(currency))
+}
+
+func (x *XenditClient) request(method, path string) *gorequest.SuperAgent {
+	return x.client.Client().Clone().
+		SetBasicAuth(x.client.SignatureKey(), "").
+		CustomMethod(method, fmt.Sprintf("%s%s", x.client.BaseUrl(), path))
+}
+
+func (x *XenditClient) end(request *gorequest.SuperAgent, result any) error {
+	res, body, errs := request.EndBytes()
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %v", errGateway.ErrGatewayUnavailable, errs[0])
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return errPayment.ErrTransactionNotFound
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		var response XenditErrorResponse
+		_ = json.Unmarshal(body, &response)
+		if res.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("xendit response: %w: %s %s", errGateway.ErrGatewayUnavailable, response.ErrorCode, response.Message)
+		}
+		return fmt.Errorf("xendit response: %s %s", response.ErrorCode, response.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(body, result)
+}
+
+func (x *XenditClient) CreatePaymentLink(req *dto.PaymentRequest) (*dto.GatewayPaymentLink, error) {
+	duration := time.Until(req.ExpiredAt)
+	if duration <= 0 {
+		logrus.Errorf("Expired at is invalid")
+		return nil, errPayment.ErrExpireAtInvalid
+	}
+
+	invoiceRequest := &XenditInvoiceRequest{
+		ExternalID:      req.GatewayOrderID,
+		Amount:          req.Amount,
+		InvoiceDuration: int64(duration.Seconds()),
+		Currency:        req.Currency,
+	}
+	if invoiceRequest.Currency == "" {
+		invoiceRequest.Currency = constants.DefaultCurrency
+	}
+	if req.Description != nil {
+		invoiceRequest.Description = *req.Description
+	}
+	if req.FinishURL != nil {
+		invoiceRequest.SuccessRedirectURL = *req.FinishURL
+	}
+	if req.CustomerDetail != nil {
+		invoiceRequest.PayerEmail = req.CustomerDetail.Email
+		invoiceRequest.Customer = &XenditCustomer{
+			GivenNames:   req.CustomerDetail.Name,
+			Email:        req.CustomerDetail.Email,
+			MobileNumber: req.CustomerDetail.Phone,
+		}
+	}
+	for _, item := range req.ItemDetails {
+		// Invoice items must be priced above zero, discounts are listed as
+		// fees with a negative value instead.
+		if item.Amount < 0 {
+			invoiceRequest.Fees = append(invoiceRequest.Fees, XenditFee{
+				Type:  "Discount",
+				Value: item.Amount * float64(item.Quantity),
+			})
+			continue
+		}
+		invoiceRequest.Items = append(invoiceRequest.Items, XenditItem{
+			Name:     item.Name,
+			Quantity: item.Quantity,
+			Price:    item.Amount,
+		})
+	}
+
+	var invoice XenditInvoice
+	err := x.end(x.request(http.MethodPost, "/v2/invoices").Send(invoiceRequest), &invoice)
+	if err != nil {
+		logrus.Errorf("Error Create Invoice: %v", err)
+		return nil, err
+	}
+	return &dto.GatewayPaymentLink{
+		Reference:   invoice.ID,
+		RedirectURL: invoice.InvoiceURL,
+	}, nil
+}
+
+// findInvoice looks an invoice up by the external id it was created with,
+// Xendit keys every other invoice endpoint on its own invoice id.
+func (x *XenditClient) findInvoice(orderID string) (*XenditInvoice, error) {
+	var invoices []XenditInvoice
+	path := fmt.Sprintf("/v2/invoices?external_id=%s", url.QueryEscape(orderID))
+	err := x.end(x.request(http.MethodGet, path), &invoices)
+	if err != nil {
+		logrus.Errorf("Error Get Invoice %s: %v", orderID, err)
+		return nil, err
+	}
+	if len(invoices) == 0 {
+		return nil, errPayment.ErrTransactionNotFound
+	}
+	return &invoices[len(invoices)-1], nil
+}
+
+func (x *XenditClient) GetStatus(orderID string) (*dto.GatewayNotification, error) {
+	invoice, err := x.findInvoice(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return x.toNotification(invoice), nil
+}
+
+func (x *XenditClient) Cancel(orderID string) error {
+	invoice, err := x.findInvoice(orderID)
+	if err != nil {
+		return err
+	}
+	err = x.end(x.request(http.MethodPost, fmt.Sprintf("/invoices/%s/expire!", invoice.ID)), nil)
+	if err != nil {
+		logrus.Errorf("Error Expire Invoice %s: %v", orderID, err)
+		return err
+	}
+	return nil
+}
+
+func (x *XenditClient) Refund(orderID string, req *dto.GatewayRefundRequest) error {
+	invoice, err := x.findInvoice(orderID)
+	if err != nil {
+		return err
+	}
+	reason := req.Reason
+	if reason == "" {
+		reason = "OTHERS"
+	}
+	err = x.end(x.request(http.MethodPost, "/refunds").Send(&XenditRefundRequest{
+		InvoiceID:   invoice.ID,
+		ReferenceID: req.RefundKey,
+		Amount:      req.Amount,
+		Reason:      reason,
+	}), nil)
+	if err != nil {
+		logrus.Errorf("Error Refund Invoice %s: %v", orderID, err)
+		return err
+	}
+	return nil
+}
+
+// ParseWebhook verifies the callback token Xendit sends with every invoice
+// callback against the one configured on the dashboard.
+func (x *XenditClient) ParseWebhook(header http.Header, body []byte) (*dto.GatewayNotification, error) {
+	token := header.Get(callbackTokenHeader)
+	if x.callbackToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(x.callbackToken)) != 1 {
+		logrus.Errorf("Invalid Xendit callback token")
+		return nil, errGateway.ErrWebhookSignatureInvalid
+	}
+	var invoice XenditInvoice
+	err := json.Unmarshal(body, &invoice)
+	if err != nil || invoice.ExternalID == "" {
+		return nil, errGateway.ErrWebhookPayloadInvalid
+	}
+	return x.toNotification(&invoice), nil
+}
+
+func (x *XenditClient) toNotification(invoice *XenditInvoice) *dto.GatewayNotification {
+	detail := x.methodDetail(invoice)
+	notification := &dto.GatewayNotification{
+		Provider:          constants.ProviderXendit,
+		OrderID:           invoice.ExternalID,
+		TransactionID:     invoice.ID,
+		TransactionStatus: x.mapStatus(invoice.Status),
+		PaymentType:       detail.Type,
+		Acquirer:          strings.ToLower(invoice.PaymentChannel),
+		GrossAmount:       strconv.FormatFloat(invoice.Amount, 'f', 2, 64),
+		Currency:          invoice.Currency,
+		MethodDetail:      detail,
+	}
+	switch detail.Type {
+	case "bank_transfer":
+		notification.VANumber = &detail.Reference
+		notification.Bank = &detail.Channel
+	case "credit_card":
+		if detail.Channel != "" {
+			notification.Bank = &detail.Channel
+		}
+	}
+	if invoice.PaidAt != "" {
+		paidAt, err := time.Parse(time.RFC3339, invoice.PaidAt)
+		if err == nil {
+			notification.PaidAt = &paidAt
+		}
+	}
+	return notification
+}
+
+// paymentTypes maps Xendit invoice payment methods onto the payment types
+// Midtrans uses so both providers read the same downstream.
+var paymentTypes = map[string]string{
+	"BANK_TRANSFER": "bank_transfer",
+	"CREDIT_CARD":   "credit_card",
+	"EWALLET":       "ewallet",
+	"QR_CODE":       "qris",
+	"RETAIL_OUTLET": "cstore",
+	"DIRECT_DEBIT":  "direct_debit",
+	"PAYLATER":      "paylater",
+}
+
+func (x *XenditClient) methodDetail(invoice *XenditInvoice) dto.PaymentMethodDetail {
+	detail := dto.PaymentMethodDetail{
+		Type:      paymentTypes[strings.ToUpper(invoice.PaymentMethod)],
+		Channel:   strings.ToLower(invoice.PaymentChannel),
+		Reference: invoice.PaymentDestination,
+	}
+	if detail.Type == "" {
+		detail.Type = strings.ToLower(invoice.PaymentMethod)
+	}
+	if detail.Type == "bank_transfer" && invoice.BankCode != "" {
+		detail.Channel = strings.ToLower(invoice.BankCode)
+	}
+	return detail
+}
+
+func (x *XenditClient) mapStatus(status string) constants.PaymentStatusString {
+	switch strings.ToUpper(status) {
+	case "PAID", "SETTLED":
+		return constants.SettlementString
+	case "EXPIRED":
+		return constants.ExpireString
+	default:
+		return constants.PendingString
+	}
+}
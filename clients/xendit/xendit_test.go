@@ -0,0 +1,94 @@
+package clients
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"payment-service/constants"
+	errGateway "payment-service/constants/error/gateway"
+)
+
+const paidInvoice = `{
+	"id": "inv-1",
+	"external_id": "order-2",
+	"status": "PAID",
+	"amount": 150000,
+	"currency": "IDR",
+	"paid_at": "2024-05-01T03:00:00.000Z",
+	"payment_method": "BANK_TRANSFER",
+	"payment_channel": "BCA",
+	"payment_destination": "8808123456"
+}`
+
+func callbackHeader(token string) http.Header {
+	header := http.Header{}
+	header.Set(callbackTokenHeader, token)
+	return header
+}
+
+func TestParseWebhook(t *testing.T) {
+	client := &XenditClient{callbackToken: "secret"}
+
+	notification, err := client.ParseWebhook(callbackHeader("secret"), []byte(paidInvoice))
+	if err != nil {
+		t.Fatalf("ParseWebhook unexpected error: %v", err)
+	}
+	if notification.Provider != constants.ProviderXendit || notification.OrderID != "order-2" ||
+		notification.TransactionID != "inv-1" {
+		t.Errorf("notification = %+v, want invoice inv-1 of order-2 from xendit", notification)
+	}
+	if notification.TransactionStatus != constants.SettlementString {
+		t.Errorf("status = %s, want %s", notification.TransactionStatus, constants.SettlementString)
+	}
+	// Virtual account invoices have no bank code, the channel names the bank.
+	if notification.Bank == nil || *notification.Bank != "bca" ||
+		notification.VANumber == nil || *notification.VANumber != "8808123456" {
+		t.Errorf("bank = %v, va number = %v, want bca 8808123456", notification.Bank, notification.VANumber)
+	}
+	if notification.PaidAt == nil || notification.PaidAt.Format("2006-01-02 15:04") != "2024-05-01 03:00" {
+		t.Errorf("paid at = %v, want 2024-05-01 03:00", notification.PaidAt)
+	}
+	if notification.GrossAmount != "150000.00" {
+		t.Errorf("gross amount = %s, want 150000.00", notification.GrossAmount)
+	}
+}
+
+func TestParseWebhookErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		saved string
+		body  string
+		want  error
+	}{
+		{name: "wrong token", token: "other", saved: "secret", body: paidInvoice, want: errGateway.ErrWebhookSignatureInvalid},
+		{name: "token not configured", token: "", saved: "", body: paidInvoice, want: errGateway.ErrWebhookSignatureInvalid},
+		{name: "invalid json", token: "secret", saved: "secret", body: "{", want: errGateway.ErrWebhookPayloadInvalid},
+		{name: "missing external id", token: "secret", saved: "secret", body: `{"id":"inv-1"}`, want: errGateway.ErrWebhookPayloadInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &XenditClient{callbackToken: tt.saved}
+			_, err := client.ParseWebhook(callbackHeader(tt.token), []byte(tt.body))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ParseWebhook error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapStatus(t *testing.T) {
+	client := &XenditClient{}
+	tests := map[string]constants.PaymentStatusString{
+		"PAID":    constants.SettlementString,
+		"settled": constants.SettlementString,
+		"EXPIRED": constants.ExpireString,
+		"PENDING": constants.PendingString,
+	}
+	for status, want := range tests {
+		if got := client.mapStatus(status); got != want {
+			t.Errorf("mapStatus(%s) = %s, want %s", status, got, want)
+		}
+	}
+}
@@ -5,7 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"payment-service/clients"
+	clientConfig "payment-service/clients/config"
+	gatewayClient "payment-service/clients/gateway"
 	midtransClient "payment-service/clients/midtrans"
+	xenditClient "payment-service/clients/xendit"
+	"payment-service/common/checkout"
+	"payment-service/common/fx"
 	"payment-service/common/gcs"
 	"payment-service/common/response"
 	"payment-service/config"
@@ -13,7 +18,9 @@ import (
 	"payment-service/controllers/http"
 	kafkaClient "payment-service/controllers/kafka"
 	"payment-service/domain/models"
+	"payment-service/jobs"
 	"payment-service/middlewares"
+	"payment-service/migrations"
 	"payment-service/repositories"
 	"payment-service/routes"
 	"payment-service/services"
@@ -25,43 +32,24 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
+	"gorm.io/gorm"
 )
 
 var command = &cobra.Command{
 	Use:   "serve",
 	Short: "Start the server",
 	Run: func(cmd *cobra.Command, args []string) {
-		_ = godotenv.Load()
-		config.Init()
-		db, err := config.InitDatabase()
+		db := initDatabase()
+		err := checkout.Validate()
 		if err != nil {
 			panic(err)
 		}
-		loc, err := time.LoadLocation("Asia/Jakarta")
-
-		if err != nil {
-			panic(err)
-		}
-		time.Local = loc
-
-		err = db.AutoMigrate(
-			&models.Payment{},
-			&models.PaymentHistory{},
-		)
-		if err != nil {
-			panic(err)
-		}
-
-		gcs := initGCS()
-		kafka := kafkaClient.NewKafkaRegistry(config.Config.Kafka.Brokers)
-		midtrans := midtransClient.NewMidTransClient(
-			config.Config.Midtrans.ServerKey,
-			config.Config.Midtrans.IsProduction)
 		client := clients.NewClientRegistry()
-		repository := repositories.NewRepositoryRegistry(db)
-		service := services.NewServiceRegistry(repository, gcs, kafka, midtrans)
+		service := initServices(db)
 
 		controller := controllers.NewControllerRegistry(service)
+		job := jobs.NewJobRegistry(service)
+		job.Start(cmd.Context())
 
 		router := gin.Default()
 		router.Use(middlewares.HandlePanic())
@@ -82,7 +70,7 @@ var command = &cobra.Command{
 		router.Use(func(context *gin.Context) {
 			context.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 			context.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH")
-			context.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, x-service-name, x-apikey, x-request-at")
+			context.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, x-service-name, x-apikey, x-request-at, idempotency-key")
 			if context.Request.Method == "OPTIONS" {
 				context.AbortWithStatus(204)
 				return
@@ -99,7 +87,7 @@ var command = &cobra.Command{
 		router.Use(middlewares.RateLimiter(lmt))
 
 		group := router.Group("/api/v1")
-		route := routes.NewRouteRegistry(group, controller, client)
+		route := routes.NewRouteRegistry(group, controller, client, service)
 		route.Serve()
 
 		port := fmt.Sprintf(":%d", config.Config.Port)
@@ -111,12 +99,97 @@ var command = &cobra.Command{
 }
 
 func Run() {
+	command.AddCommand(reconcileCommand)
+	command.AddCommand(settlementCommand)
 	err := command.Execute()
 	if err != nil {
 		panic(err)
 	}
 }
 
+func initDatabase() *gorm.DB {
+	_ = godotenv.Load()
+	config.Init()
+	db, err := config.InitDatabase()
+	if err != nil {
+		panic(err)
+	}
+	loc, err := time.LoadLocation("Asia/Jakarta")
+
+	if err != nil {
+		panic(err)
+	}
+	time.Local = loc
+
+	err = migrations.RunBeforeAutoMigrate(db)
+	if err != nil {
+		panic(err)
+	}
+	err = db.AutoMigrate(
+		&models.Payment{},
+		&models.PaymentHistory{},
+		&models.PaymentTransaction{},
+		&models.StatusReconciliation{},
+		&models.StatusDiscrepancy{},
+		&models.SettlementReconciliation{},
+		&models.SettlementReconciliationLine{},
+		&models.PaymentExport{},
+		&models.IdempotencyKey{},
+		&models.SubscriptionPlan{},
+		&models.Subscription{},
+		&models.PaymentMethod{},
+		&models.PaymentSplit{},
+		&models.LedgerAccount{},
+		&models.JournalEntry{},
+		&models.LedgerPosting{},
+		&models.PayoutBatch{},
+		&models.Payout{},
+		&models.Voucher{},
+		&models.VoucherRedemption{},
+		&models.PaymentLink{},
+	)
+	if err != nil {
+		panic(err)
+	}
+	err = migrations.Run(db)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+func initServices(db *gorm.DB) services.IServiceRegistry {
+	gcs := initGCS()
+	kafka := kafkaClient.NewKafkaRegistry(config.Config.Kafka.Brokers)
+	repository := repositories.NewRepositoryRegistry(db)
+	return services.NewServiceRegistry(repository, gcs, kafka, initGateway(), initFX())
+}
+
+func initGateway() gatewayClient.IGatewayRegistry {
+	midtrans := midtransClient.NewMidTransClient(
+		config.Config.Midtrans.ServerKey,
+		config.Config.Midtrans.IsProduction)
+	xendit := xenditClient.NewXenditClient(
+		clientConfig.NewClientConfig(
+			clientConfig.WithBaseUrl(config.Config.Xendit.BaseURL),
+			clientConfig.WithSignatureKey(config.Config.Xendit.SecretKey),
+		),
+		config.Config.Xendit.CallbackToken)
+	gateway := config.Config.Gateway
+	if gateway.DefaultProvider == "" {
+		gateway.DefaultProvider = constants.ProviderMidtrans
+	}
+	return gatewayClient.NewGatewayRegistry(gateway, midtrans, xendit)
+}
+
+func initFX() fx.IRateProvider {
+	rates := config.Config.FX
+	if rates.Provider == fx.SourceFile {
+		return fx.NewFileProvider(rates.File)
+	}
+	return fx.NewStaticProvider(fx.ReportingCurrency(), rates.Rates)
+}
+
 func initGCS() gcs.IGSClient {
 	decode, err := base64.StdEncoding.DecodeString(config.Config.GCSPrivateKey)
 	if err != nil {
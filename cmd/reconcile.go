@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"payment-service/domain/dto"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	reconcileStartDate string
+	reconcileEndDate   string
+	reconcileOrderIDs  []string
+)
+
+var reconcileCommand = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Reconcile payment status with Midtrans",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := initDatabase()
+		request, err := reconcileRequest()
+		if err != nil {
+			panic(err)
+		}
+		service := initServices(db)
+
+		report, err := service.GetReconciliation().Reconcile(cmd.Context(), request)
+		if err != nil {
+			panic(err)
+		}
+		printReconcileReport(report)
+	},
+}
+
+func init() {
+	reconcileCommand.Flags().StringVar(&reconcileStartDate, "start-date", "", "start date (YYYY-MM-DD), inclusive")
+	reconcileCommand.Flags().StringVar(&reconcileEndDate, "end-date", "", "end date (YYYY-MM-DD), inclusive")
+	reconcileCommand.Flags().StringSliceVar(&reconcileOrderIDs, "order-id", nil, "order id to reconcile, can be repeated")
+}
+
+func reconcileRequest() (*dto.ReconcileRequest, error) {
+	request := &dto.ReconcileRequest{OrderIDs: reconcileOrderIDs}
+	if reconcileStartDate != "" {
+		startDate, err := time.ParseInLocation(time.DateOnly, reconcileStartDate, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		request.StartDate = &startDate
+	}
+	if reconcileEndDate != "" {
+		endDate, err := time.ParseInLocation(time.DateOnly, reconcileEndDate, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		endDate = endDate.AddDate(0, 0, 1)
+		request.EndDate = &endDate
+	}
+	return request, nil
+}
+
+func printReconcileReport(report *dto.ReconcileReport) {
+	fmt.Printf("Reconciliation %s\n", report.UUID)
+	fmt.Printf("Checked: %d, Discrepancies: %d, Applied: %d\n\n",
+		report.TotalChecked, report.TotalDiscrepancies, report.TotalApplied)
+	if len(report.Discrepancies) == 0 {
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "ORDER ID\tLOCAL\tMIDTRANS\tACTION\tNOTE")
+	for _, item := range report.Discrepancies {
+		note := ""
+		if item.Note != nil {
+			note = *item.Note
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+			item.OrderID, item.LocalStatus, item.GatewayStatus, strings.ToUpper(string(item.Action)), note)
+	}
+	_ = writer.Flush()
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"payment-service/domain/dto"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var settlementFile string
+
+var settlementCommand = &cobra.Command{
+	Use:   "settlement-import",
+	Short: "Import a Midtrans settlement report and reconcile it with payments",
+	Run: func(cmd *cobra.Command, args []string) {
+		db := initDatabase()
+		file, err := os.Open(settlementFile)
+		if err != nil {
+			panic(err)
+		}
+		defer file.Close()
+
+		service := initServices(db)
+		result, err := service.GetSettlement().Import(cmd.Context(), &dto.SettlementImportRequest{
+			FileName: filepath.Base(settlementFile),
+			Source:   dto.SettlementSourceCLI,
+			File:     file,
+		})
+		if err != nil {
+			panic(err)
+		}
+		printSettlementReconciliation(result)
+	},
+}
+
+func init() {
+	settlementCommand.Flags().StringVar(&settlementFile, "file", "", "path to the Midtrans settlement csv")
+	_ = settlementCommand.MarkFlagRequired("file")
+}
+
+func printSettlementReconciliation(result *dto.SettlementReconciliationResponse) {
+	fmt.Printf("Settlement reconciliation %s (%s)\n", result.UUID, result.FileName)
+	fmt.Printf("Rows: %d, Matched: %d, Amount mismatch: %d, Status mismatch: %d, Missing: %d, Duplicated: %d\n\n",
+		result.TotalRows, result.TotalMatched, result.TotalAmountMismatch,
+		result.TotalStatusMismatch, result.TotalMissing, result.TotalDuplicated)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "ROW\tORDER ID\tREPORT AMOUNT\tPAYMENT AMOUNT\tRESULT\tNOTE")
+	for _, line := range result.Lines {
+		if line.Result == dto.SettlementMatched {
+			continue
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			intValue(line.RowNumber), stringValue(line.OrderID), floatValue(line.ReportAmount),
+			floatValue(line.PaymentAmount), line.Result, stringValue(line.Note))
+	}
+	_ = writer.Flush()
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return "-"
+	}
+	return *value
+}
+
+func intValue(value *int) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *value)
+}
+
+func floatValue(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *value)
+}
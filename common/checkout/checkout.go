@@ -0,0 +1,59 @@
+package checkout
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"payment-service/config"
+	errCheckout "payment-service/constants/error/checkout"
+	"strings"
+)
+
+// Validate refuses to start without a secret, tokens signed with an empty key
+// could be made by anyone.
+func Validate() error {
+	if secret() == "" {
+		return errCheckout.ErrCheckoutSecretMissing
+	}
+	return nil
+}
+
+func secret() string {
+	if config.Config.Checkout.TokenSecret != "" {
+		return config.Config.Checkout.TokenSecret
+	}
+	return config.Config.SignatureKey
+}
+
+// Token signs the uuid of a payment, whoever holds it may see the status of
+// that payment without logging in.
+func Token(paymentUUID string) (string, error) {
+	key := secret()
+	if key == "" {
+		return "", errCheckout.ErrCheckoutSecretMissing
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(paymentUUID))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func Verify(paymentUUID, token string) bool {
+	expected, err := Token(paymentUUID)
+	if err != nil || token == "" {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(token))
+}
+
+// StatusURL is relative to the service when checkout.baseURL is unset, and
+// empty when no token can be signed.
+func StatusURL(paymentUUID string) string {
+	token, err := Token(paymentUUID)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/pay/%s/status?token=%s",
+		strings.TrimRight(config.Config.Checkout.BaseURL, "/"), paymentUUID, url.QueryEscape(token))
+}
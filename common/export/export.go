@@ -0,0 +1,91 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var contentTypes = map[string]string{
+	FormatCSV:  "text/csv",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+type IWriter interface {
+	Write([]string) error
+	Close() error
+}
+
+func NewWriter(format string, writer io.Writer) (IWriter, error) {
+	switch format {
+	case FormatCSV:
+		return &CSVWriter{writer: csv.NewWriter(writer)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(writer)
+	}
+	return nil, fmt.Errorf("unsupported export format %s", format)
+}
+
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+type CSVWriter struct {
+	writer *csv.Writer
+}
+
+func (c *CSVWriter) Write(record []string) error {
+	return c.writer.Write(record)
+}
+
+func (c *CSVWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type XLSXWriter struct {
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	writer io.Writer
+	row    int
+}
+
+func newXLSXWriter(writer io.Writer) (IWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &XLSXWriter{file: file, stream: stream, writer: writer}, nil
+}
+
+func (x *XLSXWriter) Write(record []string) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, 0, len(record))
+	for _, value := range record {
+		values = append(values, value)
+	}
+	return x.stream.SetRow(cell, values)
+}
+
+func (x *XLSXWriter) Close() error {
+	defer func() {
+		_ = x.file.Close()
+	}()
+	err := x.stream.Flush()
+	if err != nil {
+		return err
+	}
+	return x.file.Write(x.writer)
+}
@@ -0,0 +1,133 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	"os"
+	"payment-service/config"
+	"payment-service/constants"
+	errFX "payment-service/constants/error/fx"
+	"strings"
+	"time"
+)
+
+const (
+	SourceStatic = "static"
+	SourceFile   = "file"
+)
+
+// ReportingCurrency is the currency reports convert payments into.
+func ReportingCurrency() string {
+	if config.Config.FX.ReportingCurrency == "" {
+		return constants.DefaultCurrency
+	}
+	return strings.ToUpper(config.Config.FX.ReportingCurrency)
+}
+
+// Rate is the number of units of To for one unit of From.
+type Rate struct {
+	From   string
+	To     string
+	Value  float64
+	Source string
+	AsOf   time.Time
+}
+
+type IRateProvider interface {
+	Rate(ctx context.Context, from, to string) (*Rate, error)
+}
+
+// table holds rates against a base currency and derives cross rates from
+// them.
+type table struct {
+	base  string
+	rates map[string]float64
+	asOf  time.Time
+}
+
+func (t *table) rate(from, to, source string) (*Rate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	rate := &Rate{From: from, To: to, Value: 1, Source: source, AsOf: t.asOf}
+	if from == to {
+		return rate, nil
+	}
+	fromRate, err := t.lookup(from)
+	if err != nil {
+		return nil, err
+	}
+	toRate, err := t.lookup(to)
+	if err != nil {
+		return nil, err
+	}
+	rate.Value = fromRate / toRate
+	return rate, nil
+}
+
+func (t *table) lookup(currency string) (float64, error) {
+	if currency == t.base {
+		return 1, nil
+	}
+	value, ok := t.rates[currency]
+	if !ok {
+		return 0, errFX.ErrRateNotFound
+	}
+	if value <= 0 {
+		return 0, errFX.ErrRateInvalid
+	}
+	return value, nil
+}
+
+func newTable(base string, rates map[string]float64, asOf time.Time) *table {
+	normalized := make(map[string]float64, len(rates))
+	for currency, value := range rates {
+		normalized[strings.ToUpper(currency)] = value
+	}
+	return &table{base: strings.ToUpper(base), rates: normalized, asOf: asOf}
+}
+
+type StaticProvider struct {
+	table *table
+}
+
+// NewStaticProvider serves fixed rates, each in units of base for one unit of
+// the currency it is keyed by.
+func NewStaticProvider(base string, rates map[string]float64) IRateProvider {
+	return &StaticProvider{table: newTable(base, rates, time.Now())}
+}
+
+func (s *StaticProvider) Rate(_ context.Context, from, to string) (*Rate, error) {
+	return s.table.rate(from, to, SourceStatic)
+}
+
+type rateFile struct {
+	Base  string             `json:"base"`
+	AsOf  time.Time          `json:"asOf"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+type FileProvider struct {
+	path string
+}
+
+// NewFileProvider reads the rates from a JSON file such as
+// {"base": "IDR", "asOf": "2024-01-01T00:00:00Z", "rates": {"SGD": 11800}}.
+// The file is read on every lookup so it can be replaced while running.
+func NewFileProvider(path string) IRateProvider {
+	return &FileProvider{path: path}
+}
+
+func (f *FileProvider) Rate(_ context.Context, from, to string) (*Rate, error) {
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		logrus.Errorf("failed to read exchange rates from %s: %v", f.path, err)
+		return nil, errFX.ErrRateUnavailable
+	}
+	var file rateFile
+	err = json.Unmarshal(content, &file)
+	if err != nil {
+		logrus.Errorf("failed to parse exchange rates from %s: %v", f.path, err)
+		return nil, errFX.ErrRateUnavailable
+	}
+	return newTable(file.Base, file.Rates, file.AsOf).rate(from, to, SourceFile)
+}
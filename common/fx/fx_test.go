@@ -0,0 +1,64 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	errFX "payment-service/constants/error/fx"
+)
+
+func TestStaticProvider(t *testing.T) {
+	provider := NewStaticProvider("idr", map[string]float64{"sgd": 11800, "USD": 15900, "EUR": 0})
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want float64
+		err  error
+	}{
+		{name: "same currency", from: "USD", to: "usd", want: 1},
+		{name: "into the base", from: "SGD", to: "IDR", want: 11800},
+		{name: "from the base", from: "IDR", to: "SGD", want: 1.0 / 11800},
+		{name: "cross rate", from: "USD", to: "SGD", want: 15900.0 / 11800},
+		{name: "unknown currency", from: "JPY", to: "IDR", err: errFX.ErrRateNotFound},
+		{name: "invalid rate", from: "EUR", to: "IDR", err: errFX.ErrRateInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := provider.Rate(context.Background(), tt.from, tt.to)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Rate error = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && (rate.Value != tt.want || rate.Source != SourceStatic) {
+				t.Errorf("Rate = %+v, want %v from %s", rate, tt.want, SourceStatic)
+			}
+		})
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	provider := NewFileProvider(path)
+	if _, err := provider.Rate(context.Background(), "SGD", "IDR"); !errors.Is(err, errFX.ErrRateUnavailable) {
+		t.Errorf("Rate without a file error = %v, want %v", err, errFX.ErrRateUnavailable)
+	}
+
+	// The file is read again on every lookup, so a replaced file takes effect.
+	for _, want := range []string{"11800", "11900"} {
+		content := `{"base": "IDR", "asOf": "2024-01-01T00:00:00Z", "rates": {"SGD": ` + want + `}}`
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		rate, err := provider.Rate(context.Background(), "SGD", "IDR")
+		if err != nil {
+			t.Fatalf("Rate unexpected error: %v", err)
+		}
+		if strconv.FormatFloat(rate.Value, 'f', -1, 64) != want || rate.Source != SourceFile || rate.AsOf.Year() != 2024 {
+			t.Errorf("Rate = %+v, want %s from the file as of 2024", rate, want)
+		}
+	}
+}
@@ -33,6 +33,7 @@ type GSClient struct {
 
 type IGSClient interface {
 	UploadFile(context.Context, string, []byte) (string, error)
+	UploadReader(context.Context, string, io.Reader) (string, error)
 }
 
 func NewGSClient(json ServiceAccountKeyJson, bucketName string) IGSClient {
@@ -43,19 +44,24 @@ func NewGSClient(json ServiceAccountKeyJson, bucketName string) IGSClient {
 }
 
 func (g *GSClient) UploadFile(ctx context.Context, filename string, data []byte) (string, error) {
-	var (
-		contentType      = "application/octet-stream"
-		timeoutInSeconds = 60
-	)
+	return g.upload(ctx, filename, bytes.NewBuffer(data), 60)
+}
+
+func (g *GSClient) UploadReader(ctx context.Context, filename string, reader io.Reader) (string, error) {
+	return g.upload(ctx, filename, reader, 600)
+}
+
+func (g *GSClient) upload(ctx context.Context, filename string, reader io.Reader, timeoutInSeconds int) (string, error) {
+	var contentType = "application/octet-stream"
 	client, err := g.createClient(ctx)
 	if err != nil {
-		logrus.Error("failed to create GS client: %v", err)
+		logrus.Errorf("failed to create GS client: %v", err)
 		return "", err
 	}
 	defer func(client *storage.Client) {
 		err := client.Close()
 		if err != nil {
-			logrus.Error("failed to close GS client: %v", err)
+			logrus.Errorf("failed to close GS client: %v", err)
 			return
 		}
 	}(client)
@@ -65,23 +71,22 @@ func (g *GSClient) UploadFile(ctx context.Context, filename string, data []byte)
 
 	bucket := client.Bucket(g.BucketName)
 	obj := bucket.Object(filename)
-	buffer := bytes.NewBuffer(data)
 
 	writer := obj.NewWriter(ctx)
 	writer.ChunkSize = 0
-	_, err = io.Copy(writer, buffer)
+	_, err = io.Copy(writer, reader)
 	if err != nil {
-		logrus.Error("failed to copy GS object: %v", err)
+		logrus.Errorf("failed to copy GS object: %v", err)
 		return "", err
 	}
 	err = writer.Close()
 	if err != nil {
-		logrus.Error("failed to close Writer: %v", err)
+		logrus.Errorf("failed to close Writer: %v", err)
 		return "", err
 	}
 	_, err = obj.Update(ctx, storage.ObjectAttrsToUpdate{ContentType: contentType})
 	if err != nil {
-		logrus.Error("failed to update : %v", err)
+		logrus.Errorf("failed to update : %v", err)
 		return "", err
 	}
 	url := fmt.Sprintf("https://storage.googleapis.com/%s/%s", g.BucketName, filename)
@@ -92,13 +97,13 @@ func (g *GSClient) createClient(ctx context.Context) (*storage.Client, error) {
 	reqBodyBytes := new(bytes.Buffer)
 	err := json.NewEncoder(reqBodyBytes).Encode(g.ServiceAccountKeyJson)
 	if err != nil {
-		logrus.Error("failed to encode service account key :%v", err)
+		logrus.Errorf("failed to encode service account key :%v", err)
 		return nil, err
 	}
 	jsonByte := reqBodyBytes.Bytes()
 	client, err := storage.NewClient(ctx, option.WithCredentialsJSON(jsonByte))
 	if err != nil {
-		logrus.Error("failed to create client :%v", err)
+		logrus.Errorf("failed to create client :%v", err)
 		return nil, err
 	}
 	return client, nil
@@ -0,0 +1,211 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+const defaultExponent = 2
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrInvalidAmount    = errors.New("money: invalid amount")
+)
+
+// exponents follows ISO 4217, the number of minor units in one major unit is
+// 10^exponent. Currencies not listed use two decimals.
+var exponents = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"EUR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+}
+
+var symbols = map[string]string{
+	"IDR": "Rp.",
+	"SGD": "S$",
+	"MYR": "RM",
+	"USD": "US$",
+}
+
+// Money is an amount in the minor unit of its currency, so every calculation
+// on it is exact.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func Exponent(currency string) int {
+	exponent, ok := exponents[strings.ToUpper(currency)]
+	if !ok {
+		return defaultExponent
+	}
+	return exponent
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// FromMajor converts a major unit amount, rounding half away from zero to the
+// nearest minor unit.
+func FromMajor(value float64, currency string) Money {
+	scale := math.Pow10(Exponent(currency))
+	return New(int64(math.Round(value*scale)), currency)
+}
+
+// Parse reads a decimal string such as the gross_amount of a gateway
+// notification without going through a float. Thousands separators are
+// ignored and fraction digits beyond the currency exponent are rounded.
+func Parse(value, currency string) (Money, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", "")
+	if value == "" {
+		return Money{}, ErrInvalidAmount
+	}
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimLeft(value, "+-")
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	exponent := Exponent(currency)
+	roundUp := false
+	if len(fraction) > exponent {
+		roundUp = fraction[exponent] >= '5'
+		fraction = fraction[:exponent]
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+	if roundUp {
+		amount++
+	}
+	if negative {
+		amount = -amount
+	}
+	return New(amount, currency), nil
+}
+
+func (m Money) Major() float64 {
+	return float64(m.Amount) / math.Pow10(Exponent(m.Currency))
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) Equal(other Money) bool {
+	return m.Currency == other.Currency && m.Amount == other.Amount
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return New(m.Amount+other.Amount, m.Currency), nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return New(m.Amount-other.Amount, m.Currency), nil
+}
+
+func (m Money) Multiply(quantity int64) Money {
+	return New(m.Amount*quantity, m.Currency)
+}
+
+// MultiplyRate applies a rate such as a fee or tax percentage and rounds the
+// result half away from zero.
+func (m Money) MultiplyRate(rate float64) Money {
+	return New(int64(math.Round(float64(m.Amount)*rate)), m.Currency)
+}
+
+// Round rounds to the given number of fraction digits, Round(0) gives whole
+// major units as gateways that do not accept cents expect.
+func (m Money) Round(digits int) Money {
+	exponent := Exponent(m.Currency)
+	if digits >= exponent {
+		return m
+	}
+	step := int64(math.Pow10(exponent - digits))
+	remainder := m.Amount % step
+	amount := m.Amount - remainder
+	if remainder*2 >= step {
+		amount += step
+	} else if remainder*2 <= -step {
+		amount -= step
+	}
+	return New(amount, m.Currency)
+}
+
+// Convert changes the amount into another currency at a rate in units of that
+// currency for one unit of this one.
+func (m Money) Convert(rate float64, currency string) Money {
+	return FromMajor(m.Major()*rate, currency)
+}
+
+// Allocate splits the amount by ratios without losing a minor unit, the
+// remainder goes to the first parts.
+func (m Money) Allocate(ratios ...int64) []Money {
+	var total int64
+	for _, ratio := range ratios {
+		total += ratio
+	}
+	parts := make([]Money, len(ratios))
+	if total == 0 {
+		for i := range parts {
+			parts[i] = New(0, m.Currency)
+		}
+		return parts
+	}
+	remainder := m.Amount
+	for i, ratio := range ratios {
+		parts[i] = New(m.Amount*ratio/total, m.Currency)
+		remainder -= parts[i].Amount
+	}
+	for i := 0; remainder > 0 && i < len(parts); i++ {
+		parts[i].Amount++
+		remainder--
+	}
+	return parts
+}
+
+// String renders the plain decimal value, e.g. 10000.00.
+func (m Money) String() string {
+	return strconv.FormatFloat(m.Major(), 'f', Exponent(m.Currency), 64)
+}
+
+// Format renders the amount for people, rupiah keeps the Rp. 10.000 style of
+// the invoices and other currencies use their symbol, or their code, with
+// grouped digits and all of their decimals, e.g. S$ 1,250.50.
+func (m Money) Format() string {
+	prefix, ok := symbols[m.Currency]
+	if !ok {
+		prefix = m.Currency
+	}
+	if m.Currency == "IDR" {
+		value := humanize.CommafWithDigits(m.Major(), 0)
+		return fmt.Sprintf("%s %s", prefix, strings.ReplaceAll(value, ",", "."))
+	}
+	format := "#,###." + strings.Repeat("#", Exponent(m.Currency))
+	return fmt.Sprintf("%s %s", prefix, humanize.FormatFloat(format, m.Major()))
+}
@@ -0,0 +1,147 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFromMajor(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		currency string
+		want     Money
+	}{
+		{name: "rupiah", value: 10000, currency: "idr", want: Money{Amount: 1000000, Currency: "IDR"}},
+		{name: "rounds half away from zero", value: 0.125, currency: "USD", want: Money{Amount: 13, Currency: "USD"}},
+		{name: "negative", value: -0.125, currency: "USD", want: Money{Amount: -13, Currency: "USD"}},
+		{name: "zero exponent", value: 1500.4, currency: "JPY", want: Money{Amount: 1500, Currency: "JPY"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromMajor(tt.value, tt.currency)
+			if got != tt.want {
+				t.Errorf("FromMajor(%v, %s) = %+v, want %+v", tt.value, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		currency string
+		want     int64
+		wantErr  bool
+	}{
+		{name: "gross amount", value: "10000.00", currency: "IDR", want: 1000000},
+		{name: "no fraction", value: "10000", currency: "IDR", want: 1000000},
+		{name: "thousands separator", value: "1,250.5", currency: "SGD", want: 125050},
+		{name: "rounds extra digits", value: "0.125", currency: "USD", want: 13},
+		{name: "drops extra digits", value: "0.124", currency: "USD", want: 12},
+		{name: "zero exponent", value: "1500", currency: "JPY", want: 1500},
+		{name: "negative", value: "-5.50", currency: "USD", want: -550},
+		{name: "leading dot", value: ".5", currency: "USD", want: 50},
+		{name: "empty", value: " ", currency: "IDR", wantErr: true},
+		{name: "not a number", value: "abc", currency: "IDR", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value, tt.currency)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAmount) {
+					t.Fatalf("Parse(%q) error = %v, want %v", tt.value, err, ErrInvalidAmount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.value, err)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.value, got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestArithmeticRejectsCurrencyMismatch(t *testing.T) {
+	idr := New(100, "IDR")
+	usd := New(100, "USD")
+	if _, err := idr.Add(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if _, err := idr.Sub(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if idr.Equal(usd) {
+		t.Error("Equal across currencies = true, want false")
+	}
+
+	got, err := idr.Sub(New(150, "IDR"))
+	if err != nil {
+		t.Fatalf("Sub unexpected error: %v", err)
+	}
+	if got.Amount != -50 || !got.IsNegative() {
+		t.Errorf("Sub = %+v, want -50", got)
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		digits int
+		want   int64
+	}{
+		{name: "rounds up", amount: 1000050, digits: 0, want: 1000100},
+		{name: "rounds down", amount: 1000049, digits: 0, want: 1000000},
+		{name: "negative rounds away from zero", amount: -150, digits: 0, want: -200},
+		{name: "digits beyond exponent", amount: 1000049, digits: 2, want: 1000049},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.amount, "IDR").Round(tt.digits)
+			if got.Amount != tt.want {
+				t.Errorf("Round(%d) of %d = %d, want %d", tt.digits, tt.amount, got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	parts := New(100, "IDR").Allocate(1, 1, 1)
+	want := []int64{34, 33, 33}
+	var total int64
+	for i, part := range parts {
+		if part.Amount != want[i] {
+			t.Errorf("part %d = %d, want %d", i, part.Amount, want[i])
+		}
+		total += part.Amount
+	}
+	if total != 100 {
+		t.Errorf("parts add up to %d, want 100", total)
+	}
+
+	for i, part := range New(100, "IDR").Allocate(0, 0) {
+		if !part.IsZero() {
+			t.Errorf("part %d of zero ratios = %d, want 0", i, part.Amount)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: New(1000000, "IDR"), want: "Rp. 10.000"},
+		{money: New(125050, "SGD"), want: "S$ 1,250.50"},
+		{money: New(1500, "JPY"), want: "JPY 1,500"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.Format(); got != tt.want {
+			t.Errorf("Format(%+v) = %q, want %q", tt.money, got, tt.want)
+		}
+	}
+}
@@ -8,7 +8,7 @@ import (
 	"strings"
 )
 
-func GeneratePDFFromHTML(htmlTemplate string, data any) ([]byte, error) {
+func RenderHTML(htmlTemplate string, data any) ([]byte, error) {
 	funcMap := template.FuncMap{
 		"add1": add1,
 	}
@@ -21,7 +21,15 @@ func GeneratePDFFromHTML(htmlTemplate string, data any) ([]byte, error) {
 	if err := template.Execute(&filledTemplate, data); err != nil {
 		return nil, err
 	}
-	htmlContent := filledTemplate.String()
+	return filledTemplate.Bytes(), nil
+}
+
+func GeneratePDFFromHTML(htmlTemplate string, data any) ([]byte, error) {
+	filledTemplate, err := RenderHTML(htmlTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	htmlContent := string(filledTemplate)
 	pdfGenerator, err := wkhtmltopdf.NewPDFGenerator()
 	if err != nil {
 		logrus.Errorf("failed to create pdf generator: %v", err)
@@ -2,14 +2,12 @@ package utils
 
 import (
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
-	"fmt"
-	"github.com/dustin/go-humanize"
 	"math"
 	"os"
 	"reflect"
 	"strconv"
-	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -67,13 +65,12 @@ func GenerateSHA256(inputString string) string {
 	return hashString
 }
 
-func RupiahFormat(amount *float64) string {
-	stringValue := "0"
-	if amount != nil {
-		humanizeValue := humanize.CommafWithDigits(*amount, 0)
-		stringValue = strings.ReplaceAll(humanizeValue, ",", ".")
-	}
-	return fmt.Sprintf("Rp. %s", stringValue)
+func GenerateSHA512(inputString string) string {
+	hash := sha512.New()
+	hash.Write([]byte(inputString))
+	hashBytes := hash.Sum(nil)
+	hashString := hex.EncodeToString(hashBytes)
+	return hashString
 }
 
 func BindFromJSON(dest any, filename, path string) error {
@@ -33,6 +33,18 @@ type AppConfig struct {
 	GCSBucketName              string          `json:"gcsBucketName"`
 	Kafka                      Kafka           `json:"kafka"`
 	Midtrans                   Midtrans        `json:"midtrans"`
+	Xendit                     Xendit          `json:"xendit"`
+	Gateway                    Gateway         `json:"gateway"`
+	Reconciliation             Reconciliation  `json:"reconciliation"`
+	Export                     Export          `json:"export"`
+	Idempotency                Idempotency     `json:"idempotency"`
+	Subscription               Subscription    `json:"subscription"`
+	Authorization              Authorization   `json:"authorization"`
+	Payout                     Payout          `json:"payout"`
+	Tax                        Tax             `json:"tax"`
+	FX                         FX              `json:"fx"`
+	PaymentLink                PaymentLink     `json:"paymentLink"`
+	Checkout                   Checkout        `json:"checkout"`
 }
 
 type Database struct {
@@ -61,6 +73,10 @@ type Kafka struct {
 	TimeoutInMS int      `json:"timeoutInMS"`
 	MaxRetry    int      `json:"maxRetry"`
 	Topic       string   `json:"topic"`
+	AlertTopic  string   `json:"alertTopic"`
+	// SubscriptionTopic receives the subscription lifecycle events, they go
+	// to Topic when it is empty.
+	SubscriptionTopic string `json:"subscriptionTopic"`
 }
 
 type Midtrans struct {
@@ -69,6 +85,126 @@ type Midtrans struct {
 	IsProduction bool   `json:"isProduction"`
 }
 
+type Xendit struct {
+	BaseURL       string `json:"baseURL"`
+	SecretKey     string `json:"secretKey"`
+	CallbackToken string `json:"callbackToken"`
+}
+
+type Gateway struct {
+	DefaultProvider string         `json:"defaultProvider"`
+	Routing         GatewayRouting `json:"routing"`
+	Fees            []GatewayFee   `json:"fees"`
+}
+
+// GatewayFee is the MDR a provider deducts for a payment type, a fee without
+// PaymentType applies to the payment types that have none of their own.
+type GatewayFee struct {
+	Provider    string  `json:"provider"`
+	PaymentType string  `json:"paymentType"`
+	Percentage  float64 `json:"percentage"`
+	Fixed       float64 `json:"fixed"`
+}
+
+type GatewayRouting struct {
+	Rules    []GatewayRoutingRule `json:"rules"`
+	Failover GatewayFailover      `json:"failover"`
+}
+
+type GatewayRoutingRule struct {
+	Name           string          `json:"name"`
+	MinAmount      *float64        `json:"minAmount"`
+	MaxAmount      *float64        `json:"maxAmount"`
+	PaymentMethods []string        `json:"paymentMethods"`
+	Currencies     []string        `json:"currencies"`
+	Segments       []string        `json:"segments"`
+	Providers      []GatewayWeight `json:"providers"`
+}
+
+type GatewayWeight struct {
+	Provider string `json:"provider"`
+	Weight   int    `json:"weight"`
+}
+
+type GatewayFailover struct {
+	Enabled            bool    `json:"enabled"`
+	WindowInMinutes    int     `json:"windowInMinutes"`
+	MinRequests        int     `json:"minRequests"`
+	ErrorRateThreshold float64 `json:"errorRateThreshold"`
+}
+
+type Reconciliation struct {
+	Enabled         bool `json:"enabled"`
+	IntervalMinutes int  `json:"intervalMinutes"`
+	LookbackHours   int  `json:"lookbackHours"`
+}
+
+type Export struct {
+	AsyncRowThreshold int64 `json:"asyncRowThreshold"`
+	FormatAmount      bool  `json:"formatAmount"`
+}
+
+type Idempotency struct {
+	TTLInHours               int `json:"ttlInHours"`
+	CleanupIntervalInMinutes int `json:"cleanupIntervalInMinutes"`
+}
+
+type Subscription struct {
+	Enabled               bool  `json:"enabled"`
+	IntervalMinutes       int   `json:"intervalMinutes"`
+	ChargeExpiryInMinutes int   `json:"chargeExpiryInMinutes"`
+	RetryScheduleInHours  []int `json:"retryScheduleInHours"`
+}
+
+type Authorization struct {
+	Enabled         bool `json:"enabled"`
+	IntervalMinutes int  `json:"intervalMinutes"`
+	VoidAfterHours  int  `json:"voidAfterHours"`
+}
+
+type Payout struct {
+	Enabled         bool    `json:"enabled"`
+	IntervalMinutes int     `json:"intervalMinutes"`
+	MinimumAmount   float64 `json:"minimumAmount"`
+}
+
+// Tax is the PPN charged on payments, Rate is a percentage. Inclusive item
+// amounts already contain the tax, otherwise it is added on top of them.
+type Tax struct {
+	Enabled          bool     `json:"enabled"`
+	Rate             float64  `json:"rate"`
+	Inclusive        bool     `json:"inclusive"`
+	ExemptCategories []string `json:"exemptCategories"`
+	MerchantName     string   `json:"merchantName"`
+	MerchantNPWP     string   `json:"merchantNPWP"`
+	MerchantAddress  string   `json:"merchantAddress"`
+}
+
+// FX configures where exchange rates come from. Rates are in units of
+// ReportingCurrency for one unit of the listed currency.
+type FX struct {
+	Provider          string             `json:"provider"`
+	ReportingCurrency string             `json:"reportingCurrency"`
+	Rates             map[string]float64 `json:"rates"`
+	File              string             `json:"file"`
+}
+
+// PaymentLink builds the shared URL of a link from BaseURL and its code, the
+// payment of every use expires after PaymentExpiryInMinutes. A payer can use
+// links at most UsesPerMinute times a minute.
+type PaymentLink struct {
+	BaseURL                string `json:"baseURL"`
+	PaymentExpiryInMinutes int    `json:"paymentExpiryInMinutes"`
+	UsesPerMinute          int    `json:"usesPerMinute"`
+}
+
+// Checkout builds the status page URL handed to customers from BaseURL, its
+// token is signed with TokenSecret or with SignatureKey when that is unset.
+type Checkout struct {
+	BaseURL     string `json:"baseURL"`
+	TokenSecret string `json:"tokenSecret"`
+}
+
 func Init() {
 	err := utils.BindFromJSON(&Config, "config.json", ".")
 	if err != nil {
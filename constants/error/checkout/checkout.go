@@ -0,0 +1,12 @@
+package error
+
+import "errors"
+
+var (
+	ErrCheckoutTokenInvalid  = errors.New("checkout token is invalid")
+	ErrCheckoutSecretMissing = errors.New("checkout token secret is not configured")
+)
+
+var CheckoutErrors = []error{
+	ErrCheckoutTokenInvalid,
+}
@@ -1,18 +1,48 @@
 package error
 
 import (
+	errCheckout "payment-service/constants/error/checkout"
+	errFX "payment-service/constants/error/fx"
+	errGateway "payment-service/constants/error/gateway"
+	errIdempotency "payment-service/constants/error/idempotency"
+	errLedger "payment-service/constants/error/ledger"
 	errPayment "payment-service/constants/error/payment"
+	errPaymentLink "payment-service/constants/error/payment_link"
+	errReconciliation "payment-service/constants/error/reconciliation"
+	errSettlement "payment-service/constants/error/settlement"
+	errSubscription "payment-service/constants/error/subscription"
+	errVoucher "payment-service/constants/error/voucher"
 )
 
 func ErrMapping(err error) bool {
 	var (
-		GeneralErrors = GeneralErrors
-		PaymentErrors = errPayment.PaymentErrors
+		GeneralErrors        = GeneralErrors
+		PaymentErrors        = errPayment.PaymentErrors
+		ReconciliationErrors = errReconciliation.ReconciliationErrors
+		SettlementErrors     = errSettlement.SettlementErrors
+		IdempotencyErrors    = errIdempotency.IdempotencyErrors
+		GatewayErrors        = errGateway.GatewayErrors
+		SubscriptionErrors   = errSubscription.SubscriptionErrors
+		LedgerErrors         = errLedger.LedgerErrors
+		VoucherErrors        = errVoucher.VoucherErrors
+		FXErrors             = errFX.FXErrors
+		PaymentLinkErrors    = errPaymentLink.PaymentLinkErrors
+		CheckoutErrors       = errCheckout.CheckoutErrors
 	)
 
 	allErrors := make([]error, 0)
 	allErrors = append(allErrors, GeneralErrors...)
 	allErrors = append(allErrors, PaymentErrors...)
+	allErrors = append(allErrors, ReconciliationErrors...)
+	allErrors = append(allErrors, SettlementErrors...)
+	allErrors = append(allErrors, IdempotencyErrors...)
+	allErrors = append(allErrors, GatewayErrors...)
+	allErrors = append(allErrors, SubscriptionErrors...)
+	allErrors = append(allErrors, LedgerErrors...)
+	allErrors = append(allErrors, VoucherErrors...)
+	allErrors = append(allErrors, FXErrors...)
+	allErrors = append(allErrors, PaymentLinkErrors...)
+	allErrors = append(allErrors, CheckoutErrors...)
 
 	for _, item := range allErrors {
 		if err.Error() == item.Error() {
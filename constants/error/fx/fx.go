@@ -0,0 +1,15 @@
+package error
+
+import "errors"
+
+var (
+	ErrRateNotFound    = errors.New("exchange rate for the currency is not available")
+	ErrRateInvalid     = errors.New("exchange rate must be positive")
+	ErrRateUnavailable = errors.New("exchange rates could not be loaded")
+)
+
+var FXErrors = []error{
+	ErrRateNotFound,
+	ErrRateInvalid,
+	ErrRateUnavailable,
+}
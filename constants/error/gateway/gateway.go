@@ -0,0 +1,19 @@
+package error
+
+import "errors"
+
+var (
+	ErrGatewayNotSupported     = errors.New("payment gateway is not supported")
+	ErrWebhookSignatureInvalid = errors.New("invalid webhook signature")
+	ErrWebhookPayloadInvalid   = errors.New("invalid webhook payload")
+
+	// ErrGatewayUnavailable wraps the errors of a gateway that cannot be
+	// reached or fails on its side, only those count towards failover.
+	ErrGatewayUnavailable = errors.New("payment gateway is unavailable")
+)
+
+var GatewayErrors = []error{
+	ErrGatewayNotSupported,
+	ErrWebhookSignatureInvalid,
+	ErrWebhookPayloadInvalid,
+}
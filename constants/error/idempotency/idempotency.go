@@ -0,0 +1,15 @@
+package error
+
+import "errors"
+
+var (
+	ErrIdempotencyKeyTooLong    = errors.New("idempotency key must not exceed 255 characters")
+	ErrIdempotencyKeyMismatch   = errors.New("idempotency key was already used with a different request body")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+)
+
+var IdempotencyErrors = []error{
+	ErrIdempotencyKeyTooLong,
+	ErrIdempotencyKeyMismatch,
+	ErrIdempotencyKeyInProgress,
+}
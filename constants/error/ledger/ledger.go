@@ -0,0 +1,29 @@
+package error
+
+import "errors"
+
+var (
+	ErrEntryUnbalanced      = errors.New("journal entry debits and credits do not balance")
+	ErrEntryLineInvalid     = errors.New("journal entry line must either debit or credit a positive amount")
+	ErrSplitInvalid         = errors.New("split must have either a positive amount or a percentage")
+	ErrSplitFeeInvalid      = errors.New("split fee must not exceed the split amount")
+	ErrSplitExceedsAmount   = errors.New("total of splits exceeds the payment amount")
+	ErrNoPayableBalance     = errors.New("no merchant balance is due for payout")
+	ErrPayoutBatchNotFound  = errors.New("payout batch not found")
+	ErrPayoutBatchCompleted = errors.New("payout batch file has already been uploaded")
+	ErrAccountNotFound      = errors.New("ledger account not found")
+	ErrStatementRange       = errors.New("statement end date must not be before its start date")
+)
+
+var LedgerErrors = []error{
+	ErrEntryUnbalanced,
+	ErrEntryLineInvalid,
+	ErrSplitInvalid,
+	ErrSplitFeeInvalid,
+	ErrSplitExceedsAmount,
+	ErrNoPayableBalance,
+	ErrPayoutBatchNotFound,
+	ErrPayoutBatchCompleted,
+	ErrAccountNotFound,
+	ErrStatementRange,
+}
@@ -3,11 +3,41 @@ package error
 import "errors"
 
 var (
-	ErrPaymentNotFound = errors.New("payment not found")
-	ErrExpireAtInvalid = errors.New("expired time must be greater than current time")
+	ErrPaymentNotFound       = errors.New("payment not found")
+	ErrExpireAtInvalid       = errors.New("expired time must be greater than current time")
+	ErrTransactionNotFound   = errors.New("transaction not found at payment gateway")
+	ErrSummaryRangeInvalid   = errors.New("end date must not be before start date")
+	ErrExportNotFound        = errors.New("payment export not found")
+	ErrPaymentAlreadySettled = errors.New("order has already been paid")
+	ErrPaymentMethodInvalid  = errors.New("payment method is not supported")
+	ErrItemTotalMismatch     = errors.New("total of item details does not match amount")
+	ErrPaymentUnderReview    = errors.New("payment of this order is under review")
+	ErrAmountInvalid         = errors.New("amount must be a whole number for this currency")
+	ErrCurrencyNotSupported  = errors.New("currency is not supported by the payment gateway")
+	ErrSavedMethodNotFound   = errors.New("saved payment method not found")
+	ErrSavedMethodExpired    = errors.New("saved payment method has expired")
+	ErrPaymentAuthorized     = errors.New("payment of this order is authorized, capture or void it first")
+	ErrPaymentNotAuthorized  = errors.New("payment is not authorized")
+	ErrCaptureAmountInvalid  = errors.New("capture amount must not exceed the authorized amount")
+	ErrAuthorizeNotSupported = errors.New("only card payments can be authorized")
 )
 
 var PaymentErrors = []error{
 	ErrPaymentNotFound,
 	ErrExpireAtInvalid,
+	ErrTransactionNotFound,
+	ErrSummaryRangeInvalid,
+	ErrExportNotFound,
+	ErrPaymentAlreadySettled,
+	ErrPaymentMethodInvalid,
+	ErrItemTotalMismatch,
+	ErrPaymentUnderReview,
+	ErrAmountInvalid,
+	ErrCurrencyNotSupported,
+	ErrSavedMethodNotFound,
+	ErrSavedMethodExpired,
+	ErrPaymentAuthorized,
+	ErrPaymentNotAuthorized,
+	ErrCaptureAmountInvalid,
+	ErrAuthorizeNotSupported,
 }
@@ -0,0 +1,25 @@
+package error
+
+import "errors"
+
+var (
+	ErrPaymentLinkNotFound       = errors.New("payment link not found")
+	ErrPaymentLinkInactive       = errors.New("payment link is no longer active")
+	ErrPaymentLinkExpired        = errors.New("payment link has expired")
+	ErrPaymentLinkUsedUp         = errors.New("payment link has reached its maximum number of uses")
+	ErrPaymentLinkAmountRequired = errors.New("amount is required for this payment link")
+	ErrPaymentLinkAmountRange    = errors.New("amount is outside the range allowed by the payment link")
+	ErrPaymentLinkBoundsInvalid  = errors.New("minimum amount must not exceed the maximum amount")
+	ErrPaymentLinkExpireInvalid  = errors.New("payment link must expire in the future")
+)
+
+var PaymentLinkErrors = []error{
+	ErrPaymentLinkNotFound,
+	ErrPaymentLinkInactive,
+	ErrPaymentLinkExpired,
+	ErrPaymentLinkUsedUp,
+	ErrPaymentLinkAmountRequired,
+	ErrPaymentLinkAmountRange,
+	ErrPaymentLinkBoundsInvalid,
+	ErrPaymentLinkExpireInvalid,
+}
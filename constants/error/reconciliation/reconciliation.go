@@ -0,0 +1,15 @@
+package error
+
+import "errors"
+
+var (
+	ErrReconcileFilterRequired = errors.New("date range or order ids is required")
+	ErrReconcileRangeInvalid   = errors.New("start date must be before end date")
+	ErrReconcileInProgress     = errors.New("another reconciliation is already running")
+)
+
+var ReconciliationErrors = []error{
+	ErrReconcileFilterRequired,
+	ErrReconcileRangeInvalid,
+	ErrReconcileInProgress,
+}
@@ -0,0 +1,19 @@
+package error
+
+import "errors"
+
+var (
+	ErrSettlementNotFound       = errors.New("settlement reconciliation not found")
+	ErrSettlementFileRequired   = errors.New("settlement file is required")
+	ErrSettlementFileInvalid    = errors.New("settlement file must be a csv file")
+	ErrSettlementColumnNotFound = errors.New("settlement file is missing order id or amount column")
+	ErrSettlementRowInvalid     = errors.New("settlement file contains an invalid row")
+)
+
+var SettlementErrors = []error{
+	ErrSettlementNotFound,
+	ErrSettlementFileRequired,
+	ErrSettlementFileInvalid,
+	ErrSettlementColumnNotFound,
+	ErrSettlementRowInvalid,
+}
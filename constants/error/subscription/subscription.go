@@ -0,0 +1,23 @@
+package error
+
+import "errors"
+
+var (
+	ErrPlanNotFound            = errors.New("subscription plan not found")
+	ErrPlanCodeExist           = errors.New("subscription plan code already exists")
+	ErrSubscriptionNotFound    = errors.New("subscription not found")
+	ErrSubscriptionCancelled   = errors.New("subscription is already cancelled")
+	ErrPaymentTokenRequired    = errors.New("payment token is required for card subscriptions")
+	ErrPhoneNumberRequired     = errors.New("phone number is required to link a gopay account")
+	ErrPaymentAccountNotActive = errors.New("linked payment account is not active")
+)
+
+var SubscriptionErrors = []error{
+	ErrPlanNotFound,
+	ErrPlanCodeExist,
+	ErrSubscriptionNotFound,
+	ErrSubscriptionCancelled,
+	ErrPaymentTokenRequired,
+	ErrPhoneNumberRequired,
+	ErrPaymentAccountNotActive,
+}
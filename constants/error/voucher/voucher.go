@@ -0,0 +1,33 @@
+package error
+
+import "errors"
+
+var (
+	ErrVoucherNotFound         = errors.New("voucher not found")
+	ErrVoucherCodeExist        = errors.New("voucher code already exists")
+	ErrVoucherInactive         = errors.New("voucher is not active")
+	ErrVoucherNotValid         = errors.New("voucher is not valid at this time")
+	ErrVoucherPeriodInvalid    = errors.New("voucher must end after it starts")
+	ErrVoucherValueInvalid     = errors.New("voucher percentage must not exceed 100")
+	ErrVoucherCurrency         = errors.New("voucher does not apply to the payment currency")
+	ErrVoucherMinSpend         = errors.New("payment amount is below the minimum spend of the voucher")
+	ErrVoucherUsageLimit       = errors.New("voucher has been used up")
+	ErrVoucherUserLimit        = errors.New("voucher has been used up for this customer")
+	ErrVoucherCustomerRequired = errors.New("voucher can only be used by a signed in customer")
+	ErrVoucherCoversAmount     = errors.New("voucher discount must be less than the payment amount")
+)
+
+var VoucherErrors = []error{
+	ErrVoucherNotFound,
+	ErrVoucherCodeExist,
+	ErrVoucherInactive,
+	ErrVoucherNotValid,
+	ErrVoucherPeriodInvalid,
+	ErrVoucherValueInvalid,
+	ErrVoucherCurrency,
+	ErrVoucherMinSpend,
+	ErrVoucherUsageLimit,
+	ErrVoucherUserLimit,
+	ErrVoucherCustomerRequired,
+	ErrVoucherCoversAmount,
+}
@@ -0,0 +1,6 @@
+package constants
+
+const (
+	FeeSourceSchedule = "schedule"
+	FeeSourceReport   = "report"
+)
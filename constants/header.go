@@ -3,8 +3,10 @@ package constants
 import "net/textproto"
 
 var (
-	XServiceName  = textproto.CanonicalMIMEHeaderKey("x-service-name")
-	XApiKey       = textproto.CanonicalMIMEHeaderKey("x-api-key")
-	XRequestAt    = textproto.CanonicalMIMEHeaderKey("x-request-at")
-	Authorization = textproto.CanonicalMIMEHeaderKey("Authorization")
+	XServiceName       = textproto.CanonicalMIMEHeaderKey("x-service-name")
+	XApiKey            = textproto.CanonicalMIMEHeaderKey("x-api-key")
+	XRequestAt         = textproto.CanonicalMIMEHeaderKey("x-request-at")
+	Authorization      = textproto.CanonicalMIMEHeaderKey("Authorization")
+	IdempotencyKey     = textproto.CanonicalMIMEHeaderKey("Idempotency-Key")
+	IdempotentReplayed = textproto.CanonicalMIMEHeaderKey("Idempotent-Replayed")
 )
@@ -0,0 +1,37 @@
+package constants
+
+type LedgerAccountType string
+
+const (
+	AccountAsset     LedgerAccountType = "asset"
+	AccountLiability LedgerAccountType = "liability"
+	AccountRevenue   LedgerAccountType = "revenue"
+	AccountExpense   LedgerAccountType = "expense"
+
+	AccountGatewayReceivable = "gateway_receivable"
+	AccountMerchantPayable   = "merchant_payable"
+	AccountPlatformRevenue   = "platform_revenue"
+	AccountFeeRevenue        = "fee_revenue"
+	AccountGatewayFee        = "gateway_fee"
+	AccountPayoutClearing    = "payout_clearing"
+
+	JournalSettlement = "settlement"
+	JournalFee        = "fee"
+	JournalGatewayFee = "gateway_fee"
+	JournalRefund     = "refund"
+	JournalPayout     = "payout"
+
+	PayoutBatchCreated  = "created"
+	PayoutBatchUploaded = "uploaded"
+	PayoutBatchFailed   = "failed"
+)
+
+func (t LedgerAccountType) String() string {
+	return string(t)
+}
+
+// IsDebitNormal tells whether debits increase the balance of the account,
+// which holds for assets and expenses, the other types grow with credits.
+func (t LedgerAccountType) IsDebitNormal() bool {
+	return t == AccountAsset || t == AccountExpense
+}
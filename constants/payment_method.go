@@ -0,0 +1,11 @@
+package constants
+
+const (
+	PaymentMethodBCAVA     = "bca_va"
+	PaymentMethodBNIVA     = "bni_va"
+	PaymentMethodBRIVA     = "bri_va"
+	PaymentMethodPermataVA = "permata_va"
+	PaymentMethodQRIS      = "qris"
+	PaymentMethodGopay     = "gopay"
+	PaymentMethodCard      = "credit_card"
+)
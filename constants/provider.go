@@ -0,0 +1,6 @@
+package constants
+
+const (
+	ProviderMidtrans = "midtrans"
+	ProviderXendit   = "xendit"
+)
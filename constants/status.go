@@ -4,29 +4,61 @@ type PaymentStatus int
 type PaymentStatusString string
 
 const (
-	Initial    PaymentStatus = 0
-	Pending    PaymentStatus = 100
+	Initial PaymentStatus = 0
+	Pending PaymentStatus = 100
+	// PartiallyPaid keeps a payment that accepts installments open until the
+	// paid amount reaches its amount.
+	PartiallyPaid PaymentStatus = 150
+	// Authorized holds the funds of a card payment until they are captured
+	// or the authorisation is voided.
+	Authorized PaymentStatus = 170
 	Settlement PaymentStatus = 200
-	Expire     PaymentStatus = 300
+	// PartiallyRefunded and Refunded follow a settlement once the gateway
+	// returned part or all of the money.
+	PartiallyRefunded PaymentStatus = 250
+	Expire            PaymentStatus = 300
+	Cancel            PaymentStatus = 400
+	// NeedsReview parks a payment whose notification disagrees with it until
+	// an admin looks at it.
+	NeedsReview PaymentStatus = 500
+	Refunded    PaymentStatus = 600
 
-	InitialString    PaymentStatusString = "initial"
-	PendingString    PaymentStatusString = "pending"
-	SettlementString PaymentStatusString = "settlement"
-	ExpireString     PaymentStatusString = "expire"
+	InitialString       PaymentStatusString = "initial"
+	PendingString       PaymentStatusString = "pending"
+	PartiallyPaidString PaymentStatusString = "partially_paid"
+	AuthorizedString    PaymentStatusString = "authorized"
+	SettlementString    PaymentStatusString = "settlement"
+	PartialRefundString PaymentStatusString = "partial_refund"
+	ExpireString        PaymentStatusString = "expire"
+	CancelString        PaymentStatusString = "cancel"
+	NeedsReviewString   PaymentStatusString = "needs_review"
+	RefundString        PaymentStatusString = "refund"
 )
 
 var mapStatusStringToInt = map[PaymentStatusString]PaymentStatus{
-	InitialString:    Initial,
-	PendingString:    Pending,
-	SettlementString: Settlement,
-	ExpireString:     Expire,
+	InitialString:       Initial,
+	PendingString:       Pending,
+	PartiallyPaidString: PartiallyPaid,
+	AuthorizedString:    Authorized,
+	SettlementString:    Settlement,
+	PartialRefundString: PartiallyRefunded,
+	ExpireString:        Expire,
+	CancelString:        Cancel,
+	NeedsReviewString:   NeedsReview,
+	RefundString:        Refunded,
 }
 
 var mapStatusIntToString = map[PaymentStatus]PaymentStatusString{
-	Initial:    InitialString,
-	Pending:    PendingString,
-	Settlement: SettlementString,
-	Expire:     ExpireString,
+	Initial:           InitialString,
+	Pending:           PendingString,
+	PartiallyPaid:     PartiallyPaidString,
+	Authorized:        AuthorizedString,
+	Settlement:        SettlementString,
+	PartiallyRefunded: PartialRefundString,
+	Expire:            ExpireString,
+	Cancel:            CancelString,
+	NeedsReview:       NeedsReviewString,
+	Refunded:          RefundString,
 }
 
 func (p PaymentStatusString) String() string {
@@ -43,3 +75,11 @@ func (p PaymentStatus) GetStatusString() PaymentStatusString {
 func (p PaymentStatusString) GetStatusInt() PaymentStatus {
 	return mapStatusStringToInt[p]
 }
+
+func (p PaymentStatus) IsActive() bool {
+	return p == Initial || p == Pending
+}
+
+func (p PaymentStatus) IsRefund() bool {
+	return p == PartiallyRefunded || p == Refunded
+}
@@ -0,0 +1,18 @@
+package constants
+
+type SubscriptionStatus string
+
+const (
+	SubscriptionActive    SubscriptionStatus = "active"
+	SubscriptionPastDue   SubscriptionStatus = "past_due"
+	SubscriptionCancelled SubscriptionStatus = "cancelled"
+
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+	IntervalYear  = "year"
+)
+
+func (s SubscriptionStatus) String() string {
+	return string(s)
+}
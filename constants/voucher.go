@@ -0,0 +1,10 @@
+package constants
+
+const (
+	VoucherPercentage = "percentage"
+	VoucherFixed      = "fixed"
+
+	RedemptionReserved = "reserved"
+	RedemptionRedeemed = "redeemed"
+	RedemptionReleased = "released"
+)
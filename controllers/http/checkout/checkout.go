@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+	"payment-service/common/response"
+	"payment-service/common/utils"
+	errConstant "payment-service/constants/error"
+	"payment-service/domain/dto"
+	"payment-service/services"
+)
+
+const statusTemplatePath = "templates/checkout_status.html"
+
+type ICheckoutController interface {
+	GetStatus(ctx *gin.Context)
+}
+
+func NewCheckoutController(service services.IServiceRegistry) ICheckoutController {
+	return &CheckoutController{service: service}
+}
+
+type CheckoutController struct {
+	service services.IServiceRegistry
+}
+
+type statusPage struct {
+	Payment *dto.CheckoutStatusResponse
+	Error   string
+}
+
+// GetStatus renders the status page for browsers and answers apps that ask
+// for application/json with the same data.
+func (c *CheckoutController) GetStatus(ctx *gin.Context) {
+	asJSON := ctx.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON
+
+	result, err := c.service.GetCheckout().GetStatus(ctx, ctx.Param("uuid"), ctx.Query("token"))
+	if err != nil {
+		if !asJSON {
+			message := errConstant.ErrInternalServerError.Error()
+			if errConstant.ErrMapping(err) {
+				message = err.Error()
+			}
+			c.render(ctx, http.StatusBadRequest, &statusPage{Error: message})
+			return
+		}
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	if !asJSON {
+		c.render(ctx, http.StatusOK, &statusPage{Payment: result})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (c *CheckoutController) render(ctx *gin.Context, code int, page *statusPage) {
+	htmlTemplate, err := os.ReadFile(statusTemplatePath)
+	if err == nil {
+		var html []byte
+		html, err = utils.RenderHTML(string(htmlTemplate), page)
+		if err == nil {
+			ctx.Header("Cache-Control", "no-store")
+			ctx.Data(code, "text/html; charset=utf-8", html)
+			return
+		}
+	}
+	logrus.Errorf("failed to render checkout status page: %v", err)
+	ctx.String(http.StatusInternalServerError, errConstant.ErrInternalServerError.Error())
+}
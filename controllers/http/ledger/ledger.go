@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	errValidation "payment-service/common/error"
+	"payment-service/common/response"
+	"payment-service/domain/dto"
+	"payment-service/services"
+)
+
+type ILedgerController interface {
+	GetMerchantBalances(ctx *gin.Context)
+	GetMerchantBalance(ctx *gin.Context)
+	GetTrialBalance(ctx *gin.Context)
+	GetAccountStatement(ctx *gin.Context)
+}
+
+func NewLedgerController(service services.IServiceRegistry) ILedgerController {
+	return &LedgerController{service: service}
+}
+
+type LedgerController struct {
+	service services.IServiceRegistry
+}
+
+func (l *LedgerController) GetMerchantBalances(ctx *gin.Context) {
+	var merchantID *string
+	if value := ctx.Query("merchantID"); value != "" {
+		merchantID = &value
+	}
+	l.balances(ctx, merchantID)
+}
+
+func (l *LedgerController) GetMerchantBalance(ctx *gin.Context) {
+	merchantID := ctx.Param("merchantID")
+	l.balances(ctx, &merchantID)
+}
+
+func (l *LedgerController) balances(ctx *gin.Context, merchantID *string) {
+	result, err := l.service.GetLedger().GetMerchantBalances(ctx, merchantID)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (l *LedgerController) GetTrialBalance(ctx *gin.Context) {
+	var param dto.TrialBalanceParam
+
+	err := ctx.ShouldBindQuery(&param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(param); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusUnprocessableEntity,
+			Message: &errMessage,
+			Data:    errResponse,
+			Gin:     ctx,
+		})
+		return
+	}
+
+	result, err := l.service.GetLedger().GetTrialBalance(ctx, &param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (l *LedgerController) GetAccountStatement(ctx *gin.Context) {
+	var param dto.AccountStatementParam
+
+	err := ctx.ShouldBindQuery(&param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(param); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusUnprocessableEntity,
+			Message: &errMessage,
+			Data:    errResponse,
+			Gin:     ctx,
+		})
+		return
+	}
+
+	result, err := l.service.GetLedger().GetAccountStatement(ctx, ctx.Param("uuid"), &param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
@@ -1,19 +1,34 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"io"
 	"net/http"
 	errValidation "payment-service/common/error"
+	"payment-service/common/export"
 	"payment-service/common/response"
+	"payment-service/constants"
 	"payment-service/domain/dto"
+	"payment-service/middlewares"
 	"payment-service/services"
+	"time"
 )
 
 type IPaymentController interface {
 	GetAllWithPagination(ctx *gin.Context)
 	GetByUUID(ctx *gin.Context)
+	GetReviewQueue(ctx *gin.Context)
+	Summary(ctx *gin.Context)
+	Export(ctx *gin.Context)
+	GetExportByUUID(ctx *gin.Context)
 	Create(ctx *gin.Context)
+	Charge(ctx *gin.Context)
+	Capture(ctx *gin.Context)
+	Void(ctx *gin.Context)
 	Webhook(ctx *gin.Context)
 }
 
@@ -67,6 +82,48 @@ func (p *PaymentController) GetAllWithPagination(ctx *gin.Context) {
 	})
 }
 
+func (p *PaymentController) GetReviewQueue(ctx *gin.Context) {
+	var param dto.PaymentReviewParam
+
+	err := ctx.ShouldBindQuery(&param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(param); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusUnprocessableEntity,
+			Message: &errMessage,
+			Data:    errResponse,
+			Gin:     ctx,
+		})
+		return
+	}
+
+	result, err := p.service.GetPayment().GetReviewQueue(ctx, &param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
 func (p *PaymentController) GetByUUID(ctx *gin.Context) {
 	uuid := ctx.Param("uuid")
 	result, err := p.service.GetPayment().GetByUUID(ctx, uuid)
@@ -85,6 +142,130 @@ func (p *PaymentController) GetByUUID(ctx *gin.Context) {
 	})
 }
 
+func (p *PaymentController) Summary(ctx *gin.Context) {
+	var param dto.PaymentSummaryParam
+
+	err := ctx.ShouldBindQuery(&param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(param); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusUnprocessableEntity,
+			Message: &errMessage,
+			Data:    errResponse,
+			Gin:     ctx,
+		})
+		return
+	}
+
+	result, err := p.service.GetPayment().Summary(ctx, &param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (p *PaymentController) Export(ctx *gin.Context) {
+	var param dto.PaymentExportParam
+
+	err := ctx.ShouldBindQuery(&param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(param); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusUnprocessableEntity,
+			Message: &errMessage,
+			Data:    errResponse,
+			Gin:     ctx,
+		})
+		return
+	}
+
+	isAsync, err := p.service.GetExport().IsAsync(ctx, &param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	if isAsync {
+		result, err := p.service.GetExport().CreateAsync(ctx, &param)
+		if err != nil {
+			response.HttpResponse(response.ParamHTTPResp{
+				Code: http.StatusBadRequest,
+				Err:  err,
+				Gin:  ctx,
+			})
+			return
+		}
+		response.HttpResponse(response.ParamHTTPResp{
+			Data: result,
+			Gin:  ctx,
+			Code: http.StatusAccepted,
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("payments-%s.%s", time.Now().Format("20060102150405"), param.Format)
+	ctx.Header("Content-Type", export.ContentType(param.Format))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Status(http.StatusOK)
+	err = p.service.GetExport().Export(ctx, &param, ctx.Writer)
+	if err != nil {
+		logrus.Errorf("failed to export payments: %v", err)
+		_ = ctx.Error(err)
+	}
+}
+
+func (p *PaymentController) GetExportByUUID(ctx *gin.Context) {
+	uuid := ctx.Param("uuid")
+	result, err := p.service.GetExport().GetByUUID(ctx, uuid)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
 func (p *PaymentController) Create(ctx *gin.Context) {
 	var request dto.PaymentRequest
 	err := ctx.ShouldBindJSON(&request)
@@ -109,6 +290,9 @@ func (p *PaymentController) Create(ctx *gin.Context) {
 		})
 		return
 	}
+	if user := middlewares.CurrentUser(ctx); user != nil {
+		request.CustomerID = &user.UUID
+	}
 	result, err := p.service.GetPayment().Create(ctx, &request)
 	if err != nil {
 		response.HttpResponse(response.ParamHTTPResp{
@@ -125,8 +309,8 @@ func (p *PaymentController) Create(ctx *gin.Context) {
 	})
 }
 
-func (p *PaymentController) Webhook(ctx *gin.Context) {
-	var request dto.Webhook
+func (p *PaymentController) Charge(ctx *gin.Context) {
+	var request dto.ChargeRequest
 	err := ctx.ShouldBindJSON(&request)
 	if err != nil {
 		response.HttpResponse(response.ParamHTTPResp{
@@ -136,7 +320,56 @@ func (p *PaymentController) Webhook(ctx *gin.Context) {
 		})
 		return
 	}
-	err = p.service.GetPayment().Webhook(ctx, &request)
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusUnprocessableEntity,
+			Message: &errMessage,
+			Data:    errResponse,
+			Gin:     ctx,
+		})
+		return
+	}
+	if user := middlewares.CurrentUser(ctx); user != nil {
+		request.CustomerID = &user.UUID
+	}
+	result, err := p.service.GetPayment().Charge(ctx, &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusCreated,
+	})
+}
+
+func (p *PaymentController) Webhook(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+	if provider == "" {
+		provider = constants.ProviderMidtrans
+	}
+	body, err := ctx.GetRawData()
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	err = p.service.GetPayment().Webhook(ctx, provider, &dto.WebhookRequest{
+		Header: ctx.Request.Header,
+		Body:   body,
+	})
 	if err != nil {
 		response.HttpResponse(response.ParamHTTPResp{
 			Code: http.StatusBadRequest,
@@ -151,3 +384,60 @@ func (p *PaymentController) Webhook(ctx *gin.Context) {
 		Gin:  ctx,
 	})
 }
+
+func (p *PaymentController) Capture(ctx *gin.Context) {
+	var request dto.CapturePaymentRequest
+	err := ctx.ShouldBindJSON(&request)
+	if err != nil && !errors.Is(err, io.EOF) {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusUnprocessableEntity,
+			Message: &errMessage,
+			Data:    errResponse,
+			Gin:     ctx,
+		})
+		return
+	}
+	result, err := p.service.GetPayment().Capture(ctx, ctx.Param("uuid"), &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (p *PaymentController) Void(ctx *gin.Context) {
+	result, err := p.service.GetPayment().Void(ctx, ctx.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	errValidation "payment-service/common/error"
+	"payment-service/common/response"
+	"payment-service/domain/dto"
+	"payment-service/middlewares"
+	"payment-service/services"
+)
+
+type IPaymentLinkController interface {
+	GetAllWithPagination(ctx *gin.Context)
+	GetByUUID(ctx *gin.Context)
+	Create(ctx *gin.Context)
+	Deactivate(ctx *gin.Context)
+	GetByCode(ctx *gin.Context)
+	Use(ctx *gin.Context)
+}
+
+func NewPaymentLinkController(service services.IServiceRegistry) IPaymentLinkController {
+	return &PaymentLinkController{service: service}
+}
+
+type PaymentLinkController struct {
+	service services.IServiceRegistry
+}
+
+func (p *PaymentLinkController) validationError(ctx *gin.Context, err error) {
+	errMessage := http.StatusText(http.StatusUnprocessableEntity)
+	errResponse := errValidation.ErrValidationResponse(err)
+	response.HttpResponse(response.ParamHTTPResp{
+		Err:     err,
+		Code:    http.StatusUnprocessableEntity,
+		Message: &errMessage,
+		Data:    errResponse,
+		Gin:     ctx,
+	})
+}
+
+func (p *PaymentLinkController) GetAllWithPagination(ctx *gin.Context) {
+	var param dto.PaymentLinkRequestParam
+	err := ctx.ShouldBindQuery(&param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(param); err != nil {
+		p.validationError(ctx, err)
+		return
+	}
+	result, err := p.service.GetPaymentLink().GetAllWithPagination(ctx, &param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (p *PaymentLinkController) GetByUUID(ctx *gin.Context) {
+	result, err := p.service.GetPaymentLink().GetByUUID(ctx, ctx.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (p *PaymentLinkController) Create(ctx *gin.Context) {
+	var request dto.PaymentLinkRequest
+	err := ctx.ShouldBindJSON(&request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		p.validationError(ctx, err)
+		return
+	}
+	if user := middlewares.CurrentUser(ctx); user != nil {
+		request.CreatedBy = &user.UUID
+	}
+	result, err := p.service.GetPaymentLink().Create(ctx, &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusCreated,
+	})
+}
+
+func (p *PaymentLinkController) Deactivate(ctx *gin.Context) {
+	result, err := p.service.GetPaymentLink().Deactivate(ctx, ctx.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (p *PaymentLinkController) GetByCode(ctx *gin.Context) {
+	result, err := p.service.GetPaymentLink().GetByCode(ctx, ctx.Param("code"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (p *PaymentLinkController) Use(ctx *gin.Context) {
+	var request dto.PaymentLinkUseRequest
+	err := ctx.ShouldBindJSON(&request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		p.validationError(ctx, err)
+		return
+	}
+	result, err := p.service.GetPaymentLink().Use(ctx, ctx.Param("code"), &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusCreated,
+	})
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"payment-service/common/response"
+	"payment-service/middlewares"
+	"payment-service/services"
+)
+
+type IPaymentMethodController interface {
+	GetAll(ctx *gin.Context)
+	Delete(ctx *gin.Context)
+}
+
+func NewPaymentMethodController(service services.IServiceRegistry) IPaymentMethodController {
+	return &PaymentMethodController{service: service}
+}
+
+type PaymentMethodController struct {
+	service services.IServiceRegistry
+}
+
+func (p *PaymentMethodController) GetAll(ctx *gin.Context) {
+	user := middlewares.CurrentUser(ctx)
+	result, err := p.service.GetPaymentMethod().GetAll(ctx, user.UUID)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (p *PaymentMethodController) Delete(ctx *gin.Context) {
+	user := middlewares.CurrentUser(ctx)
+	err := p.service.GetPaymentMethod().Delete(ctx, ctx.Param("uuid"), user.UUID)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
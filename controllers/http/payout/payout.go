@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"payment-service/common/response"
+	"payment-service/services"
+)
+
+type IPayoutController interface {
+	CreateBatch(ctx *gin.Context)
+	GetBatch(ctx *gin.Context)
+	UploadBatch(ctx *gin.Context)
+}
+
+func NewPayoutController(service services.IServiceRegistry) IPayoutController {
+	return &PayoutController{service: service}
+}
+
+type PayoutController struct {
+	service services.IServiceRegistry
+}
+
+func (p *PayoutController) CreateBatch(ctx *gin.Context) {
+	result, err := p.service.GetPayout().CreateBatch(ctx)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (p *PayoutController) GetBatch(ctx *gin.Context) {
+	result, err := p.service.GetPayout().GetBatchByUUID(ctx, ctx.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (p *PayoutController) UploadBatch(ctx *gin.Context) {
+	result, err := p.service.GetPayout().UploadBatch(ctx, ctx.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
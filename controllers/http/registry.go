@@ -1,7 +1,15 @@
 package controllers
 
 import (
+	controllers9 "payment-service/controllers/http/checkout"
+	controllers5 "payment-service/controllers/http/ledger"
 	controllers "payment-service/controllers/http/payment"
+	controllers8 "payment-service/controllers/http/payment_link"
+	controllers4 "payment-service/controllers/http/payment_method"
+	controllers6 "payment-service/controllers/http/payout"
+	controllers2 "payment-service/controllers/http/settlement"
+	controllers3 "payment-service/controllers/http/subscription"
+	controllers7 "payment-service/controllers/http/voucher"
 	"payment-service/services"
 )
 
@@ -13,8 +21,48 @@ func (r *Registry) GetPayment() controllers.IPaymentController {
 	return controllers.NewPaymentController(r.service)
 }
 
+func (r *Registry) GetSettlement() controllers2.ISettlementController {
+	return controllers2.NewSettlementController(r.service)
+}
+
+func (r *Registry) GetSubscription() controllers3.ISubscriptionController {
+	return controllers3.NewSubscriptionController(r.service)
+}
+
+func (r *Registry) GetPaymentMethod() controllers4.IPaymentMethodController {
+	return controllers4.NewPaymentMethodController(r.service)
+}
+
+func (r *Registry) GetLedger() controllers5.ILedgerController {
+	return controllers5.NewLedgerController(r.service)
+}
+
+func (r *Registry) GetPayout() controllers6.IPayoutController {
+	return controllers6.NewPayoutController(r.service)
+}
+
+func (r *Registry) GetVoucher() controllers7.IVoucherController {
+	return controllers7.NewVoucherController(r.service)
+}
+
+func (r *Registry) GetPaymentLink() controllers8.IPaymentLinkController {
+	return controllers8.NewPaymentLinkController(r.service)
+}
+
+func (r *Registry) GetCheckout() controllers9.ICheckoutController {
+	return controllers9.NewCheckoutController(r.service)
+}
+
 type IControllerRegistry interface {
 	GetPayment() controllers.IPaymentController
+	GetSettlement() controllers2.ISettlementController
+	GetSubscription() controllers3.ISubscriptionController
+	GetPaymentMethod() controllers4.IPaymentMethodController
+	GetLedger() controllers5.ILedgerController
+	GetPayout() controllers6.IPayoutController
+	GetVoucher() controllers7.IVoucherController
+	GetPaymentLink() controllers8.IPaymentLinkController
+	GetCheckout() controllers9.ICheckoutController
 }
 
 func NewControllerRegistry(service services.IServiceRegistry) IControllerRegistry {
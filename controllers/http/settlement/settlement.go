@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"path/filepath"
+	errValidation "payment-service/common/error"
+	"payment-service/common/response"
+	errConstant "payment-service/constants/error"
+	errSettlement "payment-service/constants/error/settlement"
+	"payment-service/domain/dto"
+	"payment-service/services"
+	"strings"
+)
+
+const maxSettlementFileSize = 10 << 20
+
+type ISettlementController interface {
+	GetAllWithPagination(ctx *gin.Context)
+	GetByUUID(ctx *gin.Context)
+	Import(ctx *gin.Context)
+}
+
+func NewSettlementController(service services.IServiceRegistry) ISettlementController {
+	return &SettlementController{service: service}
+}
+
+type SettlementController struct {
+	service services.IServiceRegistry
+}
+
+func (s *SettlementController) GetAllWithPagination(ctx *gin.Context) {
+	var param dto.SettlementReconciliationRequestParam
+
+	err := ctx.ShouldBindQuery(&param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(param); err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Err:     err,
+			Code:    http.StatusUnprocessableEntity,
+			Message: &errMessage,
+			Data:    errResponse,
+			Gin:     ctx,
+		})
+		return
+	}
+
+	result, err := s.service.GetSettlement().GetAllWithPagination(ctx, &param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (s *SettlementController) GetByUUID(ctx *gin.Context) {
+	uuid := ctx.Param("uuid")
+	result, err := s.service.GetSettlement().GetByUUID(ctx, uuid)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (s *SettlementController) Import(ctx *gin.Context) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  errSettlement.ErrSettlementFileRequired,
+			Gin:  ctx,
+		})
+		return
+	}
+	if !strings.EqualFold(filepath.Ext(fileHeader.Filename), ".csv") {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusUnprocessableEntity,
+			Err:  errSettlement.ErrSettlementFileInvalid,
+			Gin:  ctx,
+		})
+		return
+	}
+	if fileHeader.Size > maxSettlementFileSize {
+		errMessage := errConstant.ErrSizeTooBig.Error()
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusRequestEntityTooLarge,
+			Err:     errConstant.ErrSizeTooBig,
+			Message: &errMessage,
+			Gin:     ctx,
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	defer file.Close()
+
+	result, err := s.service.GetSettlement().Import(ctx, &dto.SettlementImportRequest{
+		FileName: fileHeader.Filename,
+		Source:   dto.SettlementSourceUpload,
+		File:     file,
+	})
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusCreated,
+	})
+}
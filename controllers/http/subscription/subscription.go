@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"net/http"
+	clients "payment-service/clients/user"
+	errValidation "payment-service/common/error"
+	"payment-service/common/response"
+	"payment-service/constants"
+	"payment-service/domain/dto"
+	"payment-service/middlewares"
+	"payment-service/services"
+)
+
+type ISubscriptionController interface {
+	GetPlans(ctx *gin.Context)
+	CreatePlan(ctx *gin.Context)
+	GetAllWithPagination(ctx *gin.Context)
+	GetByUUID(ctx *gin.Context)
+	Create(ctx *gin.Context)
+	Cancel(ctx *gin.Context)
+}
+
+func NewSubscriptionController(service services.IServiceRegistry) ISubscriptionController {
+	return &SubscriptionController{service: service}
+}
+
+type SubscriptionController struct {
+	service services.IServiceRegistry
+}
+
+// user returns the authenticated user and, for a customer, the customer id
+// their access is restricted to.
+func (s *SubscriptionController) user(ctx *gin.Context) (*clients.UserData, *uuid.UUID) {
+	user := middlewares.CurrentUser(ctx)
+	if user == nil || user.Role == constants.Admin {
+		return user, nil
+	}
+	return user, &user.UUID
+}
+
+func (s *SubscriptionController) validationError(ctx *gin.Context, err error) {
+	errMessage := http.StatusText(http.StatusUnprocessableEntity)
+	errResponse := errValidation.ErrValidationResponse(err)
+	response.HttpResponse(response.ParamHTTPResp{
+		Err:     err,
+		Code:    http.StatusUnprocessableEntity,
+		Message: &errMessage,
+		Data:    errResponse,
+		Gin:     ctx,
+	})
+}
+
+func (s *SubscriptionController) GetPlans(ctx *gin.Context) {
+	result, err := s.service.GetSubscription().GetPlans(ctx)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (s *SubscriptionController) CreatePlan(ctx *gin.Context) {
+	var request dto.SubscriptionPlanRequest
+	err := ctx.ShouldBindJSON(&request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		s.validationError(ctx, err)
+		return
+	}
+	result, err := s.service.GetSubscription().CreatePlan(ctx, &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusCreated,
+	})
+}
+
+func (s *SubscriptionController) GetAllWithPagination(ctx *gin.Context) {
+	var param dto.SubscriptionRequestParam
+	err := ctx.ShouldBindQuery(&param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(param); err != nil {
+		s.validationError(ctx, err)
+		return
+	}
+	_, param.CustomerID = s.user(ctx)
+	result, err := s.service.GetSubscription().GetAllWithPagination(ctx, &param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (s *SubscriptionController) GetByUUID(ctx *gin.Context) {
+	_, customerID := s.user(ctx)
+	result, err := s.service.GetSubscription().GetByUUID(ctx, ctx.Param("uuid"), customerID)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (s *SubscriptionController) Create(ctx *gin.Context) {
+	var request dto.SubscriptionRequest
+	err := ctx.ShouldBindJSON(&request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		s.validationError(ctx, err)
+		return
+	}
+	user, _ := s.user(ctx)
+	if user != nil {
+		request.CustomerID = user.UUID
+		request.CustomerName = user.Name
+		request.CustomerEmail = user.Email
+		if request.Phone == "" {
+			request.Phone = user.PhoneNumber
+		}
+	}
+	result, err := s.service.GetSubscription().Create(ctx, &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusCreated,
+	})
+}
+
+func (s *SubscriptionController) Cancel(ctx *gin.Context) {
+	var request dto.CancelSubscriptionRequest
+	if ctx.Request.ContentLength > 0 {
+		err := ctx.ShouldBindJSON(&request)
+		if err != nil {
+			response.HttpResponse(response.ParamHTTPResp{
+				Code: http.StatusBadRequest,
+				Err:  err,
+				Gin:  ctx,
+			})
+			return
+		}
+	}
+	validate := validator.New()
+	if err := validate.Struct(request); err != nil {
+		s.validationError(ctx, err)
+		return
+	}
+	_, customerID := s.user(ctx)
+	result, err := s.service.GetSubscription().Cancel(ctx, ctx.Param("uuid"), customerID, &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	errValidation "payment-service/common/error"
+	"payment-service/common/response"
+	"payment-service/domain/dto"
+	"payment-service/services"
+)
+
+type IVoucherController interface {
+	GetAllWithPagination(ctx *gin.Context)
+	GetByUUID(ctx *gin.Context)
+	Create(ctx *gin.Context)
+	Deactivate(ctx *gin.Context)
+}
+
+func NewVoucherController(service services.IServiceRegistry) IVoucherController {
+	return &VoucherController{service: service}
+}
+
+type VoucherController struct {
+	service services.IServiceRegistry
+}
+
+func (v *VoucherController) validationError(ctx *gin.Context, err error) {
+	errMessage := http.StatusText(http.StatusUnprocessableEntity)
+	errResponse := errValidation.ErrValidationResponse(err)
+	response.HttpResponse(response.ParamHTTPResp{
+		Err:     err,
+		Code:    http.StatusUnprocessableEntity,
+		Message: &errMessage,
+		Data:    errResponse,
+		Gin:     ctx,
+	})
+}
+
+func (v *VoucherController) GetAllWithPagination(ctx *gin.Context) {
+	var param dto.VoucherRequestParam
+	err := ctx.ShouldBindQuery(&param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(param); err != nil {
+		v.validationError(ctx, err)
+		return
+	}
+	result, err := v.service.GetVoucher().GetAllWithPagination(ctx, &param)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (v *VoucherController) GetByUUID(ctx *gin.Context) {
+	result, err := v.service.GetVoucher().GetByUUID(ctx, ctx.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
+
+func (v *VoucherController) Create(ctx *gin.Context) {
+	var request dto.VoucherRequest
+	err := ctx.ShouldBindJSON(&request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	if err = validate.Struct(request); err != nil {
+		v.validationError(ctx, err)
+		return
+	}
+	result, err := v.service.GetVoucher().Create(ctx, &request)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusCreated,
+	})
+}
+
+func (v *VoucherController) Deactivate(ctx *gin.Context) {
+	result, err := v.service.GetVoucher().Deactivate(ctx, ctx.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Data: result,
+		Gin:  ctx,
+		Code: http.StatusOK,
+	})
+}
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"payment-service/constants"
+	"time"
+)
+
+// CheckoutStatusResponse is what the customer sees after paying, amounts are
+// formatted for people as well since the page shows them as is.
+type CheckoutStatusResponse struct {
+	UUID              uuid.UUID                     `json:"uuid"`
+	OrderID           uuid.UUID                     `json:"orderId"`
+	Description       *string                       `json:"description"`
+	Amount            float64                       `json:"amount"`
+	AmountText        string                        `json:"amountText"`
+	OutstandingAmount float64                       `json:"outstandingAmount"`
+	OutstandingText   string                        `json:"outstandingText"`
+	Currency          string                        `json:"currency"`
+	Status            constants.PaymentStatusString `json:"status"`
+	IsPaid            bool                          `json:"isPaid"`
+	IsOpen            bool                          `json:"isOpen"`
+	PaymentLink       string                        `json:"paymentLink,omitempty"`
+	PaymentMethod     *string                       `json:"paymentMethod,omitempty"`
+	Bank              *string                       `json:"bank,omitempty"`
+	VANumber          *string                       `json:"vaNumber,omitempty"`
+	QRImageURL        *string                       `json:"qrImageUrl,omitempty"`
+	Deeplink          *string                       `json:"deeplink,omitempty"`
+	Instructions      []string                      `json:"instructions,omitempty"`
+	ExpiredAt         *time.Time                    `json:"expiredAt,omitempty"`
+	ExpiresInSeconds  int64                         `json:"expiresInSeconds"`
+	PaidAt            *time.Time                    `json:"paidAt,omitempty"`
+	InvoiceLink       *string                       `json:"invoiceLink,omitempty"`
+}
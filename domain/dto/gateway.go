@@ -0,0 +1,106 @@
+package dto
+
+import (
+	"net/http"
+	"payment-service/constants"
+	"time"
+)
+
+type GatewayPaymentLink struct {
+	Reference   string
+	RedirectURL string
+}
+
+type GatewayRefundRequest struct {
+	RefundKey string
+	Amount    float64
+	Reason    string
+}
+
+type GatewayCaptureRequest struct {
+	TransactionID string
+	Amount        float64
+}
+
+type GatewayNotification struct {
+	Provider          string
+	OrderID           string
+	TransactionID     string
+	TransactionStatus constants.PaymentStatusString
+	PaymentType       string
+	VANumber          *string
+	Bank              *string
+	Acquirer          string
+	GrossAmount       string
+	Currency          string
+	PaymentAmounts    []GatewayPaidAmount
+	RefundAmount      string
+	PaidAt            *time.Time
+	MethodDetail      PaymentMethodDetail
+	SavedCard         *GatewaySavedCard
+}
+
+// GatewaySavedCard is a card the gateway saved for the customer of the payment,
+// its token charges the card again without the card details.
+type GatewaySavedCard struct {
+	Token      string
+	MaskedCard string
+	Brand      string
+	Bank       string
+	CardType   string
+	ExpiredAt  *time.Time
+}
+
+// GatewayPaidAmount is one transfer towards a payment that accepts partial
+// payments, gateways list every transfer made so far in each notification.
+type GatewayPaidAmount struct {
+	Amount string
+	PaidAt *time.Time
+}
+
+// PaymentMethodDetail is the provider independent view of how a payment was
+// made: the type (bank_transfer, credit_card, qris, ...), the channel such as
+// the bank, issuer or store, and the customer facing reference such as the VA
+// number, masked card or bill key.
+type PaymentMethodDetail struct {
+	Type         string
+	Channel      string
+	Reference    string
+	ApprovalCode string
+}
+
+type WebhookRequest struct {
+	Header http.Header
+	Body   []byte
+}
+
+type GatewayDecision struct {
+	Provider string
+	Rule     *string
+	Reason   string
+}
+
+type GatewayCharge struct {
+	TransactionID string
+	ExpiredAt     *time.Time
+	Instruction   PaymentInstruction
+}
+
+type GatewayAccountLinkRequest struct {
+	Phone       string
+	RedirectURL *string
+}
+
+type GatewayAccountLink struct {
+	AccountID     string
+	Status        string
+	ActivationURL *string
+}
+
+type PaymentInstruction struct {
+	VANumber   *string
+	Bank       *string
+	QRString   *string
+	QRImageURL *string
+	Deeplink   *string
+}
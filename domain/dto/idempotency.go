@@ -0,0 +1,17 @@
+package dto
+
+type IdempotencyRequest struct {
+	Scope       string
+	Key         string
+	RequestHash string
+}
+
+type IdempotencyResponse struct {
+	StatusCode int
+	Body       []byte
+}
+
+type IdempotencyResult struct {
+	Replay   bool
+	Response *IdempotencyResponse
+}
@@ -8,15 +8,29 @@ type InvoiceRequest struct {
 type InvoiceData struct {
 	PaymentDetail InvoicePaymentDetail `json:"paymentDetail"`
 	Items         []InvoiceItem        `json:"items"`
+	Tax           *InvoiceTax          `json:"tax,omitempty"`
 	Total         string               `json:"total"`
 }
 
+type InvoiceTax struct {
+	Label           string `json:"label"`
+	Base            string `json:"base"`
+	Amount          string `json:"amount"`
+	MerchantName    string `json:"merchantName"`
+	MerchantNPWP    string `json:"merchantNpwp"`
+	MerchantAddress string `json:"merchantAddress"`
+	CustomerName    string `json:"customerName"`
+	CustomerNPWP    string `json:"customerNpwp"`
+}
+
 type InvoicePaymentDetail struct {
-	BankName      string `json:"bankName"`
-	PaymentMethod string `json:"paymentMethod"`
-	VANumber      string `json:"vaNumber"`
-	Date          string `json:"date"`
-	IsPaid        bool   `json:"isPaid"`
+	PaymentMethod  string `json:"paymentMethod"`
+	ChannelLabel   string `json:"channelLabel"`
+	Channel        string `json:"channel"`
+	ReferenceLabel string `json:"referenceLabel"`
+	Reference      string `json:"reference"`
+	Date           string `json:"date"`
+	IsPaid         bool   `json:"isPaid"`
 }
 
 type InvoiceItem struct {
@@ -18,14 +18,67 @@ type KafkaData struct {
 	OrderID   uuid.UUID  `json:"orderID"`
 	PaymentID uuid.UUID  `json:"paymentID"`
 	Status    string     `json:"status"`
+	Currency  string     `json:"currency"`
 	ExpiredAt time.Time  `json:"expiredAt"`
 	PaidAt    *time.Time `json:"paidAt"`
+	// PaidAmount and OutstandingAmount are only set for payments that accept
+	// partial payments.
+	PaidAmount        string `json:"paidAmount,omitempty"`
+	OutstandingAmount string `json:"outstandingAmount,omitempty"`
+	// RefundAmount is the total refunded so far, set for refund events.
+	RefundAmount string `json:"refundAmount,omitempty"`
 }
 type KafkaBody struct {
 	Type string     `json:"type"`
 	Data *KafkaData `json:"data"`
 }
 
+type KafkaAlertData struct {
+	OrderID          uuid.UUID `json:"orderID"`
+	PaymentID        uuid.UUID `json:"paymentID"`
+	Provider         string    `json:"provider"`
+	Reason           string    `json:"reason"`
+	Amount           string    `json:"amount"`
+	Currency         string    `json:"currency"`
+	NotifiedAmount   string    `json:"notifiedAmount"`
+	NotifiedCurrency string    `json:"notifiedCurrency"`
+}
+
+type KafkaAlertBody struct {
+	Type string          `json:"type"`
+	Data *KafkaAlertData `json:"data"`
+}
+
+type KafkaAlertMessage struct {
+	Event    KafkaEvent     `json:"event"`
+	MetaData KafkaMetaData  `json:"metadata"`
+	Body     KafkaAlertBody `json:"body"`
+}
+
+type KafkaSubscriptionData struct {
+	SubscriptionID     uuid.UUID  `json:"subscriptionID"`
+	CustomerID         uuid.UUID  `json:"customerID"`
+	PlanCode           string     `json:"planCode"`
+	Status             string     `json:"status"`
+	Cycle              int        `json:"cycle"`
+	CurrentPeriodStart *time.Time `json:"currentPeriodStart"`
+	CurrentPeriodEnd   *time.Time `json:"currentPeriodEnd"`
+	NextBillingAt      *time.Time `json:"nextBillingAt"`
+	PaymentID          *uuid.UUID `json:"paymentID,omitempty"`
+	Reason             *string    `json:"reason,omitempty"`
+}
+
+type KafkaSubscriptionBody struct {
+	Type string                 `json:"type"`
+	Data *KafkaSubscriptionData `json:"data"`
+}
+
+type KafkaSubscriptionMessage struct {
+	Event    KafkaEvent            `json:"event"`
+	MetaData KafkaMetaData         `json:"metadata"`
+	Body     KafkaSubscriptionBody `json:"body"`
+}
+
 type KafkaMessage struct {
 	Event    KafkaEvent    `json:"event"`
 	MetaData KafkaMetaData `json:"metadata"`
@@ -0,0 +1,132 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"payment-service/constants"
+	"time"
+)
+
+type LedgerAccountRequest struct {
+	Code       string
+	Name       string
+	Type       constants.LedgerAccountType
+	MerchantID *string
+	Currency   string
+}
+
+// JournalLineRequest debits or credits one account, only one of Debit and
+// Credit is set. The account is either an existing AccountID or opened from
+// Account when it does not exist yet.
+type JournalLineRequest struct {
+	AccountID uint
+	Account   LedgerAccountRequest
+	Debit     int64
+	Credit    int64
+}
+
+type JournalEntryRequest struct {
+	Reference     string
+	Kind          string
+	PaymentID     *uint
+	PayoutBatchID *uint
+	Currency      string
+	Description   *string
+	PostedAt      time.Time
+	Lines         []JournalLineRequest
+}
+
+// LedgerBalanceFilter narrows the accounts summed up, PostedBefore leaves out
+// the entries posted from that time on.
+type LedgerBalanceFilter struct {
+	AccountID    *uint
+	CodePrefix   *string
+	MerchantID   *string
+	Currency     *string
+	PostedBefore *time.Time
+}
+
+type LedgerAccountBalance struct {
+	AccountID  uint
+	UUID       uuid.UUID
+	Code       string
+	Name       string
+	Type       constants.LedgerAccountType
+	MerchantID *string
+	Currency   string
+	Debit      int64
+	Credit     int64
+}
+
+type MerchantBalanceResponse struct {
+	MerchantID string  `json:"merchantID"`
+	Balance    float64 `json:"balance"`
+	Currency   string  `json:"currency"`
+}
+
+type TrialBalanceParam struct {
+	Date     *time.Time `form:"date" time_format:"2006-01-02"`
+	Currency *string    `form:"currency" validate:"omitempty,len=3"`
+}
+
+type TrialBalanceLine struct {
+	AccountUUID uuid.UUID                   `json:"accountUUID"`
+	Code        string                      `json:"code"`
+	Name        string                      `json:"name"`
+	Type        constants.LedgerAccountType `json:"type"`
+	Currency    string                      `json:"currency"`
+	Debit       float64                     `json:"debit"`
+	Credit      float64                     `json:"credit"`
+	Balance     float64                     `json:"balance"`
+}
+
+type TrialBalanceTotal struct {
+	Currency string  `json:"currency"`
+	Debit    float64 `json:"debit"`
+	Credit   float64 `json:"credit"`
+	Balanced bool    `json:"balanced"`
+}
+
+type TrialBalanceResponse struct {
+	Date     *time.Time          `json:"date,omitempty"`
+	Accounts []TrialBalanceLine  `json:"accounts"`
+	Totals   []TrialBalanceTotal `json:"totals"`
+}
+
+type AccountStatementParam struct {
+	StartDate time.Time `form:"startDate" time_format:"2006-01-02" validate:"required"`
+	EndDate   time.Time `form:"endDate" time_format:"2006-01-02" validate:"required"`
+}
+
+type LedgerStatementRow struct {
+	EntryUUID   uuid.UUID
+	Reference   string
+	Kind        string
+	Description *string
+	PostedAt    time.Time
+	Debit       int64
+	Credit      int64
+}
+
+type AccountStatementLine struct {
+	EntryUUID   uuid.UUID `json:"entryUUID"`
+	Reference   string    `json:"reference"`
+	Kind        string    `json:"kind"`
+	Description *string   `json:"description"`
+	PostedAt    time.Time `json:"postedAt"`
+	Debit       float64   `json:"debit"`
+	Credit      float64   `json:"credit"`
+	Balance     float64   `json:"balance"`
+}
+
+type AccountStatementResponse struct {
+	AccountUUID    uuid.UUID                   `json:"accountUUID"`
+	Code           string                      `json:"code"`
+	Name           string                      `json:"name"`
+	Type           constants.LedgerAccountType `json:"type"`
+	Currency       string                      `json:"currency"`
+	StartDate      time.Time                   `json:"startDate"`
+	EndDate        time.Time                   `json:"endDate"`
+	OpeningBalance float64                     `json:"openingBalance"`
+	ClosingBalance float64                     `json:"closingBalance"`
+	Lines          []AccountStatementLine      `json:"lines"`
+}
@@ -7,28 +7,96 @@ import (
 )
 
 type PaymentRequest struct {
-	PaymentLink    string          `json:"paymentLink"`
-	OrderID        string          `json:"orderID"`
-	ExpiredAt      time.Time       `json:"expiredAt"`
-	Amount         float64         `json:"amount"`
-	Description    *string         `json:"description"`
-	CustomerDetail *CustomerDetail `json:"customerDetail"`
-	ItemDetails    []ItemDetail    `json:"itemDetails"`
+	PaymentLink      string                   `json:"paymentLink"`
+	OrderID          string                   `json:"orderID" validate:"required,uuid"`
+	Provider         string                   `json:"-"`
+	GatewayOrderID   string                   `json:"-"`
+	GatewayReference string                   `json:"-"`
+	RoutingRule      *string                  `json:"-"`
+	RoutingReason    string                   `json:"-"`
+	Status           *constants.PaymentStatus `json:"-"`
+	Instruction      *PaymentInstruction      `json:"-"`
+	Attempt          int                      `json:"-"`
+	PaymentToken     string                   `json:"-"`
+	SubscriptionID   *uint                    `json:"-"`
+	PaymentLinkID    *uint                    `json:"-"`
+	CustomerID       *uuid.UUID               `json:"-"`
+	ExpiredAt        time.Time                `json:"expiredAt" validate:"required"`
+	ExpiryStartTime  *time.Time               `json:"expiryStartTime"`
+	Amount           float64                  `json:"amount" validate:"required,gt=0"`
+	Currency         string                   `json:"currency" validate:"omitempty,len=3"`
+	VoucherCode      *string                  `json:"voucherCode" validate:"omitempty,max=50"`
+	Discount         int64                    `json:"-"`
+	Tax              *PaymentTax              `json:"-"`
+	FX               *PaymentFX               `json:"-"`
+	AllowPartial     bool                     `json:"allowPartial"`
+	Authorize        bool                     `json:"authorize" validate:"excluded_with=AllowPartial"`
+	PaymentMethod    string                   `json:"paymentMethod"`
+	PaymentMethodID  *string                  `json:"paymentMethodID" validate:"omitempty,uuid"`
+	EnabledPayments  []string                 `json:"enabledPayments" validate:"omitempty,dive,oneof=credit_card gopay shopeepay other_qris permata_va bca_va bni_va bri_va cimb_va other_va echannel indomaret alfamart akulaku kredivo"`
+	Description      *string                  `json:"description"`
+	CustomerDetail   *CustomerDetail          `json:"customerDetail" validate:"required"`
+	ItemDetails      []ItemDetail             `json:"itemDetails" validate:"required,min=1,dive"`
+	Splits           []SplitInstruction       `json:"splits" validate:"omitempty,dive"`
+	CustomField1     *string                  `json:"customField1" validate:"omitempty,max=255"`
+	CustomField2     *string                  `json:"customField2" validate:"omitempty,max=255"`
+	CustomField3     *string                  `json:"customField3" validate:"omitempty,max=255"`
+	FinishURL        *string                  `json:"finishURL" validate:"omitempty,url"`
+	CallbackURL      *string                  `json:"callbackURL" validate:"omitempty,url"`
+}
+
+type ChargeRequest struct {
+	PaymentRequest
+	PaymentMethod string `json:"paymentMethod" validate:"required,oneof=bca_va bni_va bri_va permata_va qris gopay"`
 }
 
 type CustomerDetail struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Phone string `json:"phone"`
+	Name            string   `json:"name" validate:"required,max=255"`
+	Email           string   `json:"email" validate:"required,email"`
+	Phone           string   `json:"phone" validate:"omitempty,max=19"`
+	Segment         string   `json:"segment"`
+	NPWP            string   `json:"npwp" validate:"omitempty,numeric,min=15,max=16"`
+	BillingAddress  *Address `json:"billingAddress" validate:"omitempty"`
+	ShippingAddress *Address `json:"shippingAddress" validate:"omitempty"`
 }
+
+type Address struct {
+	FirstName   string `json:"firstName" validate:"required,max=255"`
+	LastName    string `json:"lastName" validate:"omitempty,max=255"`
+	Phone       string `json:"phone" validate:"omitempty,max=19"`
+	Address     string `json:"address" validate:"required,max=200"`
+	City        string `json:"city" validate:"required,max=100"`
+	PostalCode  string `json:"postalCode" validate:"omitempty,max=10"`
+	CountryCode string `json:"countryCode" validate:"omitempty,len=3"`
+}
+
 type ItemDetail struct {
-	ID       string  `json:"id"`
-	Amount   float64 `json:"amount"`
-	Name     string  `json:"name"`
-	Quantity int     `json:"quantity"`
+	ID       string  `json:"id" validate:"required,max=50"`
+	Amount   float64 `json:"amount" validate:"gt=0"`
+	Name     string  `json:"name" validate:"required,max=50"`
+	Quantity int     `json:"quantity" validate:"required,gte=1"`
+	Category string  `json:"category" validate:"omitempty,max=50"`
+}
+
+// PaymentTax is the PPN of a payment in minor units, Base is the DPP (dasar
+// pengenaan pajak) the tax is computed from.
+type PaymentTax struct {
+	Rate         float64
+	Inclusive    bool
+	Base         int64
+	Amount       int64
+	MerchantNPWP string
+}
+
+type PaymentFilter struct {
+	Status    *string    `form:"status" validate:"omitempty,oneof=initial pending partially_paid authorized settlement partial_refund refund expire cancel needs_review"`
+	Bank      *string    `form:"bank"`
+	StartDate *time.Time `form:"startDate" time_format:"2006-01-02"`
+	EndDate   *time.Time `form:"endDate" time_format:"2006-01-02"`
 }
 
 type PaymentRequestParam struct {
+	PaymentFilter
 	Page       int     `form:"page" validate:"required"`
 	Limit      int     `form:"limit" validate:"required"`
 	SortColumn *string `form:"sortColumn"`
@@ -43,23 +111,97 @@ type UpdatePaymentRequest struct {
 	Bank          *string                  `json:"bank"`
 	InvoiceLink   *string                  `json:"invoiceLink,omitempty"`
 	Acquirer      string                   `json:"acquirer"`
+	ReviewReason  *string                  `json:"reviewReason,omitempty"`
+	PaidAmount    *int64                   `json:"-"`
+	CaptureAmount *int64                   `json:"-"`
+	RefundAmount  *int64                   `json:"-"`
+	Fee           *int64                   `json:"-"`
+	NetAmount     *int64                   `json:"-"`
+	FeeSource     *string                  `json:"-"`
+	AuthorizedAt  *time.Time               `json:"-"`
+	MethodDetail  *PaymentMethodDetail     `json:"-"`
+}
+
+type CapturePaymentRequest struct {
+	Amount *float64 `json:"amount" validate:"omitempty,gt=0"`
+}
+
+type PaymentReviewParam struct {
+	Page  int `form:"page" validate:"required"`
+	Limit int `form:"limit" validate:"required"`
 }
 
 type PaymentResponse struct {
-	UUID          uuid.UUID                     `json:"uuid"`
-	OrderID       uuid.UUID                     `json:"orderId"`
-	Amount        float64                       `json:"amount"`
-	Status        constants.PaymentStatusString `json:"status"`
-	PaymentLink   string                        `json:"paymentLink"`
-	TransactionId *string                       `json:"transactionId,omitempty"`
-	PaidAt        *time.Time                    `json:"paidAt,omitempty"`
-	VANumber      *string                       `json:"vaNumber,omitempty"`
-	Bank          *string                       `json:"bank,omitempty"`
-	InvoiceLink   *string                       `json:"invoiceLink,omitempty"`
-	Acquirer      *string                       `json:"acquirer,omitempty"`
-	Description   *string                       `json:"description"`
-	CreatedAt     *time.Time                    `json:"createdAt"`
-	UpdatedAt     *time.Time                    `json:"updatedAt"`
+	UUID              uuid.UUID                     `json:"uuid"`
+	OrderID           uuid.UUID                     `json:"orderId"`
+	Attempt           int                           `json:"attempt"`
+	Provider          string                        `json:"provider"`
+	Amount            float64                       `json:"amount"`
+	Currency          string                        `json:"currency"`
+	Discount          float64                       `json:"discount,omitempty"`
+	VoucherCode       *string                       `json:"voucherCode,omitempty"`
+	Tax               *PaymentTaxResponse           `json:"tax,omitempty"`
+	FX                *PaymentFXResponse            `json:"fx,omitempty"`
+	AllowPartial      bool                          `json:"allowPartial"`
+	PaidAmount        float64                       `json:"paidAmount"`
+	OutstandingAmount float64                       `json:"outstandingAmount"`
+	Authorize         bool                          `json:"authorize"`
+	CaptureAmount     *float64                      `json:"captureAmount,omitempty"`
+	AuthorizedAt      *time.Time                    `json:"authorizedAt,omitempty"`
+	RefundAmount      float64                       `json:"refundAmount"`
+	Fee               *float64                      `json:"fee,omitempty"`
+	NetAmount         *float64                      `json:"netAmount,omitempty"`
+	FeeSource         *string                       `json:"feeSource,omitempty"`
+	Splits            []PaymentSplitResponse        `json:"splits,omitempty"`
+	Transactions      []PaymentTransactionResponse  `json:"transactions,omitempty"`
+	Status            constants.PaymentStatusString `json:"status"`
+	PaymentLink       string                        `json:"paymentLink"`
+	PaymentMethod     *string                       `json:"paymentMethod,omitempty"`
+	PaymentType       *string                       `json:"paymentType,omitempty"`
+	PaymentChannel    *string                       `json:"paymentChannel,omitempty"`
+	PaymentReference  *string                       `json:"paymentReference,omitempty"`
+	QRString          *string                       `json:"qrString,omitempty"`
+	QRImageURL        *string                       `json:"qrImageUrl,omitempty"`
+	Deeplink          *string                       `json:"deeplink,omitempty"`
+	ExpiredAt         *time.Time                    `json:"expiredAt,omitempty"`
+	TransactionId     *string                       `json:"transactionId,omitempty"`
+	PaidAt            *time.Time                    `json:"paidAt,omitempty"`
+	VANumber          *string                       `json:"vaNumber,omitempty"`
+	Bank              *string                       `json:"bank,omitempty"`
+	InvoiceLink       *string                       `json:"invoiceLink,omitempty"`
+	StatusURL         string                        `json:"statusUrl,omitempty"`
+	Acquirer          *string                       `json:"acquirer,omitempty"`
+	RoutingRule       *string                       `json:"routingRule,omitempty"`
+	RoutingReason     *string                       `json:"routingReason,omitempty"`
+	ReviewReason      *string                       `json:"reviewReason,omitempty"`
+	Description       *string                       `json:"description"`
+	CreatedAt         *time.Time                    `json:"createdAt"`
+	UpdatedAt         *time.Time                    `json:"updatedAt"`
+}
+
+// PaymentFX is the exchange rate into the reporting currency when the payment
+// was created, ReportingAmount is in minor units of that currency.
+type PaymentFX struct {
+	Rate            float64
+	Source          string
+	ReportingAmount int64
+}
+
+type PaymentFXResponse struct {
+	Rate              float64 `json:"rate"`
+	Source            string  `json:"source"`
+	ReportingCurrency string  `json:"reportingCurrency"`
+	ReportingAmount   float64 `json:"reportingAmount"`
+}
+
+type PaymentTaxResponse struct {
+	Rate         float64 `json:"rate"`
+	Inclusive    bool    `json:"inclusive"`
+	Base         float64 `json:"base"`
+	Amount       float64 `json:"amount"`
+	MerchantNPWP *string `json:"merchantNpwp,omitempty"`
+	CustomerNPWP *string `json:"customerNpwp,omitempty"`
+	CustomerName *string `json:"customerName,omitempty"`
 }
 
 type Webhook struct {
@@ -72,13 +214,27 @@ type Webhook struct {
 	SignatureKey      string                        `json:"signature_key"`
 	SettlementTime    string                        `json:"settlement_time"`
 	PaymentType       string                        `json:"payment_type"`
-	PaymentAmount     []PaymentAmount               `json:"payment_amount"`
-	OrderId           uuid.UUID                     `json:"order_id"`
+	PaymentAmounts    []PaymentAmount               `json:"payment_amounts"`
+	OrderId           string                        `json:"order_id"`
 	MerchantID        string                        `json:"merchant_id"`
 	GrossAmount       string                        `json:"gross_amount"`
 	FraudStatus       string                        `json:"fraud_status"`
 	Currency          string                        `json:"currency"`
 	Acquirer          string                        `json:"acquirer"`
+	PermataVANumber   string                        `json:"permata_va_number"`
+	MaskedCard        string                        `json:"masked_card"`
+	CardType          string                        `json:"card_type"`
+	Bank              string                        `json:"bank"`
+	ApprovalCode      string                        `json:"approval_code"`
+	ECI               string                        `json:"eci"`
+	BillKey           string                        `json:"bill_key"`
+	BillerCode        string                        `json:"biller_code"`
+	Issuer            string                        `json:"issuer"`
+	Store             string                        `json:"store"`
+	PaymentCode       string                        `json:"payment_code"`
+	SavedTokenID      string                        `json:"saved_token_id"`
+	SavedTokenExpiry  string                        `json:"saved_token_id_expired_at"`
+	RefundAmount      string                        `json:"refund_amount"`
 }
 
 type VANumber struct {
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type PaymentExportStatus string
+
+const (
+	PaymentExportProcessing PaymentExportStatus = "processing"
+	PaymentExportCompleted  PaymentExportStatus = "completed"
+	PaymentExportFailed     PaymentExportStatus = "failed"
+)
+
+type PaymentExportParam struct {
+	PaymentFilter
+	Format string `form:"format" validate:"required,oneof=csv xlsx"`
+	Async  bool   `form:"async"`
+}
+
+type UpdatePaymentExportRequest struct {
+	Status       PaymentExportStatus
+	RowCount     int
+	DownloadLink *string
+	Error        *string
+	CompletedAt  *time.Time
+}
+
+type PaymentExportResponse struct {
+	UUID         uuid.UUID           `json:"uuid"`
+	Format       string              `json:"format"`
+	Status       PaymentExportStatus `json:"status"`
+	RowCount     int                 `json:"rowCount"`
+	DownloadLink *string             `json:"downloadLink"`
+	Error        *string             `json:"error,omitempty"`
+	CompletedAt  *time.Time          `json:"completedAt"`
+	CreatedAt    *time.Time          `json:"createdAt"`
+}
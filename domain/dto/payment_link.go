@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"payment-service/constants"
+	"time"
+)
+
+type PaymentLinkRequest struct {
+	Title       string     `json:"title" validate:"required,max=100"`
+	Description *string    `json:"description"`
+	Amount      *float64   `json:"amount" validate:"omitempty,gt=0"`
+	MinAmount   *float64   `json:"minAmount" validate:"omitempty,gt=0,excluded_with=Amount"`
+	MaxAmount   *float64   `json:"maxAmount" validate:"omitempty,gt=0,excluded_with=Amount"`
+	Currency    string     `json:"currency" validate:"omitempty,len=3"`
+	MaxUses     *int       `json:"maxUses" validate:"omitempty,gte=1"`
+	ExpiredAt   *time.Time `json:"expiredAt"`
+	Code        string     `json:"-"`
+	CreatedBy   *uuid.UUID `json:"-"`
+}
+
+type PaymentLinkRequestParam struct {
+	Page     int   `form:"page" validate:"required"`
+	Limit    int   `form:"limit" validate:"required"`
+	IsActive *bool `form:"isActive"`
+}
+
+// PaymentLinkUseRequest is sent by the payer, Amount is only read for links
+// without a fixed amount.
+type PaymentLinkUseRequest struct {
+	Amount         *float64        `json:"amount" validate:"omitempty,gt=0"`
+	CustomerDetail *CustomerDetail `json:"customerDetail" validate:"required"`
+	FinishURL      *string         `json:"finishURL" validate:"omitempty,url"`
+}
+
+// PaymentLinkStats counts the payments of a link, Used leaves out those that
+// expired or were cancelled.
+type PaymentLinkStats struct {
+	Used       int64
+	Paid       int64
+	PaidAmount int64
+}
+
+type PaymentLinkResponse struct {
+	UUID        uuid.UUID                    `json:"uuid"`
+	Code        string                       `json:"code"`
+	URL         string                       `json:"url,omitempty"`
+	Title       string                       `json:"title"`
+	Description *string                      `json:"description"`
+	Amount      *float64                     `json:"amount"`
+	MinAmount   *float64                     `json:"minAmount"`
+	MaxAmount   *float64                     `json:"maxAmount"`
+	Currency    string                       `json:"currency"`
+	MaxUses     *int                         `json:"maxUses"`
+	UsedCount   int64                        `json:"usedCount"`
+	PaidCount   int64                        `json:"paidCount"`
+	PaidAmount  float64                      `json:"paidAmount"`
+	ExpiredAt   *time.Time                   `json:"expiredAt"`
+	IsActive    bool                         `json:"isActive"`
+	CreatedAt   *time.Time                   `json:"createdAt"`
+	Payments    []PaymentLinkPaymentResponse `json:"payments,omitempty"`
+}
+
+type PaymentLinkPaymentResponse struct {
+	UUID         uuid.UUID                     `json:"uuid"`
+	Amount       float64                       `json:"amount"`
+	Status       constants.PaymentStatusString `json:"status"`
+	CustomerName *string                       `json:"customerName"`
+	PaidAt       *time.Time                    `json:"paidAt"`
+	CreatedAt    *time.Time                    `json:"createdAt"`
+}
+
+// PublicPaymentLinkResponse is what the landing page shows to payers.
+type PublicPaymentLinkResponse struct {
+	Code        string     `json:"code"`
+	Title       string     `json:"title"`
+	Description *string    `json:"description"`
+	Amount      *float64   `json:"amount"`
+	MinAmount   *float64   `json:"minAmount"`
+	MaxAmount   *float64   `json:"maxAmount"`
+	Currency    string     `json:"currency"`
+	ExpiredAt   *time.Time `json:"expiredAt"`
+}
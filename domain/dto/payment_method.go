@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type PaymentMethodRequest struct {
+	CustomerID uuid.UUID
+	Provider   string
+	Type       string
+	SavedCard  *GatewaySavedCard
+}
+
+type PaymentMethodResponse struct {
+	UUID       uuid.UUID  `json:"uuid"`
+	Provider   string     `json:"provider"`
+	Type       string     `json:"type"`
+	MaskedCard string     `json:"maskedCard"`
+	Brand      string     `json:"brand"`
+	Bank       *string    `json:"bank,omitempty"`
+	CardType   *string    `json:"cardType,omitempty"`
+	ExpiredAt  *time.Time `json:"expiredAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  *time.Time `json:"createdAt"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"payment-service/common/money"
+)
+
+// SplitInstruction gives a merchant either a fixed amount or a percentage of
+// the payment, what is not split belongs to the platform.
+type SplitInstruction struct {
+	MerchantID string   `json:"merchantID" validate:"required,max=100"`
+	Amount     *float64 `json:"amount" validate:"required_without=Percentage,excluded_with=Percentage"`
+	Percentage *float64 `json:"percentage" validate:"omitempty,gt=0,lte=100"`
+	Fee        *float64 `json:"fee" validate:"omitempty,gte=0"`
+}
+
+type PaymentSplitRequest struct {
+	PaymentID  uint
+	MerchantID string
+	Amount     money.Money
+	Percentage *float64
+	Fee        money.Money
+}
+
+type PaymentSplitResponse struct {
+	UUID       uuid.UUID `json:"uuid"`
+	MerchantID string    `json:"merchantID"`
+	Amount     float64   `json:"amount"`
+	Percentage *float64  `json:"percentage,omitempty"`
+	Fee        float64   `json:"fee"`
+	Currency   string    `json:"currency"`
+}
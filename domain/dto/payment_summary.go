@@ -0,0 +1,55 @@
+package dto
+
+import "time"
+
+type PaymentSummaryParam struct {
+	StartDate time.Time `form:"startDate" time_format:"2006-01-02" validate:"required"`
+	EndDate   time.Time `form:"endDate" time_format:"2006-01-02" validate:"required"`
+	Period    string    `form:"period" validate:"omitempty,oneof=day week month"`
+}
+
+type PaymentSummaryTotal struct {
+	TotalCount             int64
+	TotalAmount            float64
+	SettledCount           int64
+	SettledAmount          float64
+	SettledFee             float64
+	SettledNetAmount       float64
+	ExpiredCount           int64
+	AverageTimeToPaySecond *float64
+}
+
+type PaymentSummaryGroup struct {
+	Key    *string `json:"key"`
+	Count  int64   `json:"count"`
+	Amount float64 `json:"amount"`
+}
+
+type PaymentSummaryPeriod struct {
+	Period           time.Time `json:"period"`
+	Count            int64     `json:"count"`
+	Amount           float64   `json:"amount"`
+	SettledCount     int64     `json:"settledCount"`
+	SettledAmount    float64   `json:"settledAmount"`
+	SettledFee       float64   `json:"settledFee"`
+	SettledNetAmount float64   `json:"settledNetAmount"`
+}
+
+type PaymentSummaryResponse struct {
+	StartDate              time.Time              `json:"startDate"`
+	EndDate                time.Time              `json:"endDate"`
+	Currency               string                 `json:"currency"`
+	TotalCount             int64                  `json:"totalCount"`
+	TotalAmount            float64                `json:"totalAmount"`
+	SettledCount           int64                  `json:"settledCount"`
+	SettledAmount          float64                `json:"settledAmount"`
+	SettledFee             float64                `json:"settledFee"`
+	SettledNetAmount       float64                `json:"settledNetAmount"`
+	ConversionRate         float64                `json:"conversionRate"`
+	ExpiryRate             float64                `json:"expiryRate"`
+	AverageTimeToPaySecond *float64               `json:"averageTimeToPaySecond"`
+	ByStatus               []PaymentSummaryGroup  `json:"byStatus"`
+	ByBank                 []PaymentSummaryGroup  `json:"byBank"`
+	ByAcquirer             []PaymentSummaryGroup  `json:"byAcquirer"`
+	ByPeriod               []PaymentSummaryPeriod `json:"byPeriod"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"payment-service/common/money"
+	"time"
+)
+
+type PaymentTransactionRequest struct {
+	PaymentID     uint
+	Reference     string
+	TransactionID string
+	Amount        money.Money
+	PaidAt        *time.Time
+}
+
+type PaymentTransactionResponse struct {
+	UUID      uuid.UUID  `json:"uuid"`
+	Amount    float64    `json:"amount"`
+	Currency  string     `json:"currency"`
+	PaidAt    *time.Time `json:"paidAt"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"payment-service/common/money"
+	"time"
+)
+
+type PayoutRequest struct {
+	MerchantID string
+	Amount     money.Money
+}
+
+type PayoutBatchResponse struct {
+	UUID        uuid.UUID        `json:"uuid"`
+	Status      string           `json:"status"`
+	PayoutCount int              `json:"payoutCount"`
+	FileURL     *string          `json:"fileURL"`
+	Error       *string          `json:"error,omitempty"`
+	Payouts     []PayoutResponse `json:"payouts,omitempty"`
+	CreatedAt   *time.Time       `json:"createdAt"`
+	UpdatedAt   *time.Time       `json:"updatedAt"`
+}
+
+type PayoutResponse struct {
+	UUID       uuid.UUID `json:"uuid"`
+	MerchantID string    `json:"merchantID"`
+	Amount     float64   `json:"amount"`
+	Currency   string    `json:"currency"`
+}
+
+type UpdatePayoutBatchRequest struct {
+	Status  string
+	FileURL *string
+	Error   *string
+}
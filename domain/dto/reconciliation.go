@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type ReconcileAction string
+
+const (
+	ReconcileApplied ReconcileAction = "applied"
+	ReconcileSkipped ReconcileAction = "skipped"
+	ReconcileFailed  ReconcileAction = "failed"
+)
+
+type ReconcileRequest struct {
+	StartDate *time.Time `json:"startDate"`
+	EndDate   *time.Time `json:"endDate"`
+	OrderIDs  []string   `json:"orderIDs"`
+}
+
+type ReconcileDiscrepancy struct {
+	PaymentID     uint            `json:"-"`
+	OrderID       uuid.UUID       `json:"orderID"`
+	LocalStatus   string          `json:"localStatus"`
+	GatewayStatus string          `json:"gatewayStatus"`
+	Action        ReconcileAction `json:"action"`
+	Note          *string         `json:"note,omitempty"`
+}
+
+type ReconcileReport struct {
+	UUID               uuid.UUID              `json:"uuid"`
+	StartDate          *time.Time             `json:"startDate,omitempty"`
+	EndDate            *time.Time             `json:"endDate,omitempty"`
+	OrderIDs           []string               `json:"orderIDs,omitempty"`
+	TotalChecked       int                    `json:"totalChecked"`
+	TotalDiscrepancies int                    `json:"totalDiscrepancies"`
+	TotalApplied       int                    `json:"totalApplied"`
+	Discrepancies      []ReconcileDiscrepancy `json:"discrepancies"`
+	CreatedAt          *time.Time             `json:"createdAt"`
+}
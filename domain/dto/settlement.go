@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"io"
+	"payment-service/common/money"
+	"time"
+)
+
+type SettlementResult string
+
+const (
+	SettlementMatched         SettlementResult = "matched"
+	SettlementAmountMismatch  SettlementResult = "amount_mismatch"
+	SettlementStatusMismatch  SettlementResult = "status_mismatch"
+	SettlementMissingPayment  SettlementResult = "missing_payment"
+	SettlementMissingInReport SettlementResult = "missing_in_report"
+	SettlementDuplicated      SettlementResult = "duplicated"
+)
+
+const (
+	SettlementSourceCLI    = "cli"
+	SettlementSourceUpload = "upload"
+)
+
+type SettlementRow struct {
+	RowNumber      int
+	OrderID        string
+	TransactionID  string
+	PaymentType    string
+	Amount         money.Money
+	Fee            *money.Money
+	SettlementTime *time.Time
+}
+
+type SettlementLine struct {
+	RowNumber      *int             `json:"rowNumber,omitempty"`
+	PaymentID      *uint            `json:"-"`
+	OrderID        *string          `json:"orderID,omitempty"`
+	TransactionID  *string          `json:"transactionID,omitempty"`
+	PaymentType    *string          `json:"paymentType,omitempty"`
+	ReportAmount   *float64         `json:"reportAmount,omitempty"`
+	PaymentAmount  *float64         `json:"paymentAmount,omitempty"`
+	ReportFee      *float64         `json:"reportFee,omitempty"`
+	Currency       string           `json:"currency"`
+	SettlementTime *time.Time       `json:"settlementTime,omitempty"`
+	Result         SettlementResult `json:"result"`
+	Note           *string          `json:"note,omitempty"`
+}
+
+// SettlementLineRequest is a line as it is stored, with its amounts in minor
+// units.
+type SettlementLineRequest struct {
+	RowNumber      *int
+	PaymentID      *uint
+	OrderID        *string
+	TransactionID  *string
+	PaymentType    *string
+	ReportAmount   *money.Money
+	PaymentAmount  *money.Money
+	ReportFee      *money.Money
+	SettlementTime *time.Time
+	Result         SettlementResult
+	Note           *string
+}
+
+type SettlementImportRequest struct {
+	FileName string
+	Source   string
+	File     io.Reader
+}
+
+type SettlementReconciliationRequest struct {
+	UUID                uuid.UUID
+	FileName            string
+	Source              string
+	TotalRows           int
+	TotalMatched        int
+	TotalAmountMismatch int
+	TotalStatusMismatch int
+	TotalMissing        int
+	TotalDuplicated     int
+	Lines               []SettlementLineRequest
+}
+
+type SettlementReconciliationRequestParam struct {
+	Page  int `form:"page" validate:"required"`
+	Limit int `form:"limit" validate:"required"`
+}
+
+type SettlementReconciliationResponse struct {
+	UUID                uuid.UUID        `json:"uuid"`
+	FileName            string           `json:"fileName"`
+	Source              string           `json:"source"`
+	TotalRows           int              `json:"totalRows"`
+	TotalMatched        int              `json:"totalMatched"`
+	TotalAmountMismatch int              `json:"totalAmountMismatch"`
+	TotalStatusMismatch int              `json:"totalStatusMismatch"`
+	TotalMissing        int              `json:"totalMissing"`
+	TotalDuplicated     int              `json:"totalDuplicated"`
+	Lines               []SettlementLine `json:"lines,omitempty"`
+	CreatedAt           *time.Time       `json:"createdAt"`
+}
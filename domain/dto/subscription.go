@@ -0,0 +1,95 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"payment-service/constants"
+	"time"
+)
+
+type SubscriptionPlanRequest struct {
+	Code          string  `json:"code" validate:"required,max=50"`
+	Name          string  `json:"name" validate:"required,max=100"`
+	Description   *string `json:"description"`
+	Amount        float64 `json:"amount" validate:"required,gt=0"`
+	Currency      string  `json:"currency" validate:"omitempty,len=3"`
+	Interval      string  `json:"interval" validate:"required,oneof=day week month year"`
+	IntervalCount int     `json:"intervalCount" validate:"omitempty,gte=1"`
+	TrialDays     int     `json:"trialDays" validate:"omitempty,gte=0"`
+}
+
+type SubscriptionPlanResponse struct {
+	UUID          uuid.UUID  `json:"uuid"`
+	Code          string     `json:"code"`
+	Name          string     `json:"name"`
+	Description   *string    `json:"description"`
+	Amount        float64    `json:"amount"`
+	Currency      string     `json:"currency"`
+	Interval      string     `json:"interval"`
+	IntervalCount int        `json:"intervalCount"`
+	TrialDays     int        `json:"trialDays"`
+	IsActive      bool       `json:"isActive"`
+	CreatedAt     *time.Time `json:"createdAt"`
+}
+
+type SubscriptionRequest struct {
+	PlanCode      string    `json:"planCode" validate:"required"`
+	PaymentMethod string    `json:"paymentMethod" validate:"required,oneof=credit_card gopay"`
+	PaymentToken  string    `json:"paymentToken"`
+	Phone         string    `json:"phone" validate:"omitempty,max=19"`
+	RedirectURL   *string   `json:"redirectURL" validate:"omitempty,url"`
+	CustomerID    uuid.UUID `json:"-"`
+	CustomerName  string    `json:"-"`
+	CustomerEmail string    `json:"-"`
+}
+
+type SubscriptionRequestParam struct {
+	Page       int        `form:"page" validate:"required"`
+	Limit      int        `form:"limit" validate:"required"`
+	Status     *string    `form:"status" validate:"omitempty,oneof=active past_due cancelled"`
+	CustomerID *uuid.UUID `form:"-"`
+}
+
+type CancelSubscriptionRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=255"`
+}
+
+type UpdateSubscriptionRequest struct {
+	Status              *constants.SubscriptionStatus
+	Cycle               *int
+	RetryCount          *int
+	CurrentPeriodStart  *time.Time
+	CurrentPeriodEnd    *time.Time
+	NextBillingAt       *time.Time
+	PendingPaymentID    *uuid.UUID
+	ClearPendingPayment bool
+	LastPaymentID       *uuid.UUID
+	FailureReason       *string
+	CancelledAt         *time.Time
+	CancelReason        *string
+}
+
+type SubscriptionResponse struct {
+	UUID               uuid.UUID                    `json:"uuid"`
+	CustomerID         uuid.UUID                    `json:"customerId"`
+	Plan               SubscriptionPlanResponse     `json:"plan"`
+	PaymentMethod      string                       `json:"paymentMethod"`
+	Status             constants.SubscriptionStatus `json:"status"`
+	Cycle              int                          `json:"cycle"`
+	RetryCount         int                          `json:"retryCount"`
+	CurrentPeriodStart *time.Time                   `json:"currentPeriodStart"`
+	CurrentPeriodEnd   *time.Time                   `json:"currentPeriodEnd"`
+	NextBillingAt      *time.Time                   `json:"nextBillingAt"`
+	LastPaymentID      *uuid.UUID                   `json:"lastPaymentId,omitempty"`
+	FailureReason      *string                      `json:"failureReason,omitempty"`
+	ActivationURL      *string                      `json:"activationURL,omitempty"`
+	CancelledAt        *time.Time                   `json:"cancelledAt,omitempty"`
+	CancelReason       *string                      `json:"cancelReason,omitempty"`
+	CreatedAt          *time.Time                   `json:"createdAt"`
+	UpdatedAt          *time.Time                   `json:"updatedAt"`
+}
+
+type SubscriptionBillingResult struct {
+	Charged int
+	Renewed int
+	Failed  int
+}
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"payment-service/common/money"
+	"time"
+)
+
+type VoucherRequest struct {
+	Code         string     `json:"code" validate:"required,max=50"`
+	Type         string     `json:"type" validate:"required,oneof=percentage fixed"`
+	Value        float64    `json:"value" validate:"required,gt=0"`
+	Currency     string     `json:"currency" validate:"omitempty,len=3"`
+	MinSpend     float64    `json:"minSpend" validate:"omitempty,gte=0"`
+	MaxDiscount  *float64   `json:"maxDiscount" validate:"omitempty,gt=0"`
+	UsageLimit   *int       `json:"usageLimit" validate:"omitempty,gte=1"`
+	PerUserLimit *int       `json:"perUserLimit" validate:"omitempty,gte=1"`
+	StartsAt     *time.Time `json:"startsAt"`
+	EndsAt       *time.Time `json:"endsAt"`
+}
+
+type VoucherRequestParam struct {
+	Page     int   `form:"page" validate:"required"`
+	Limit    int   `form:"limit" validate:"required"`
+	IsActive *bool `form:"isActive"`
+}
+
+type VoucherResponse struct {
+	UUID         uuid.UUID  `json:"uuid"`
+	Code         string     `json:"code"`
+	Type         string     `json:"type"`
+	Value        float64    `json:"value"`
+	Currency     string     `json:"currency"`
+	MinSpend     float64    `json:"minSpend"`
+	MaxDiscount  *float64   `json:"maxDiscount"`
+	UsageLimit   *int       `json:"usageLimit"`
+	PerUserLimit *int       `json:"perUserLimit"`
+	UsedCount    int64      `json:"usedCount"`
+	StartsAt     *time.Time `json:"startsAt"`
+	EndsAt       *time.Time `json:"endsAt"`
+	IsActive     bool       `json:"isActive"`
+	CreatedAt    *time.Time `json:"createdAt"`
+}
+
+// VoucherQuote is the discount a voucher gives on an amount.
+type VoucherQuote struct {
+	VoucherID uint
+	Code      string
+	// Amount is the amount quoted on, before the discount and tax.
+	Amount   money.Money
+	Discount money.Money
+}
+
+type VoucherRedemptionRequest struct {
+	VoucherID  uint
+	PaymentID  uint
+	CustomerID *uuid.UUID
+	Discount   money.Money
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type IdempotencyKey struct {
+	ID           uint      `gorm:"primary_key;autoIncrement"`
+	Scope        string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_scope_key"`
+	Key          string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_scope_key"`
+	RequestHash  string    `gorm:"type:varchar(64);not null"`
+	StatusCode   *int      `gorm:"default:null"`
+	ResponseBody *string   `gorm:"type:text;default:null"`
+	ExpiredAt    time.Time `gorm:"not null;index"`
+	CreatedAt    *time.Time
+	UpdatedAt    *time.Time
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"payment-service/constants"
+	"time"
+)
+
+// LedgerAccount is one account of the double-entry ledger, merchant accounts
+// carry the merchant they belong to.
+type LedgerAccount struct {
+	ID         uint                        `gorm:"primary_key;autoIncrement"`
+	UUID       uuid.UUID                   `gorm:"type:uuid;not null"`
+	Code       string                      `gorm:"type:varchar(150);not null;uniqueIndex:idx_ledger_accounts_code_currency"`
+	Currency   string                      `gorm:"type:varchar(3);not null;uniqueIndex:idx_ledger_accounts_code_currency"`
+	Name       string                      `gorm:"type:varchar(255);not null"`
+	Type       constants.LedgerAccountType `gorm:"type:varchar(20);not null"`
+	MerchantID *string                     `gorm:"type:varchar(100);index;default:null"`
+	CreatedAt  *time.Time
+	UpdatedAt  *time.Time
+}
+
+// JournalEntry groups postings whose debits and credits balance, Reference
+// identifies the event it records so the same event is never posted twice.
+type JournalEntry struct {
+	ID            uint      `gorm:"primary_key;autoIncrement"`
+	UUID          uuid.UUID `gorm:"type:uuid;not null"`
+	Reference     string    `gorm:"type:varchar(150);not null;uniqueIndex"`
+	Kind          string    `gorm:"type:varchar(30);not null;index"`
+	PaymentID     *uint     `gorm:"index;default:null"`
+	PayoutBatchID *uint     `gorm:"index;default:null"`
+	Currency      string    `gorm:"type:varchar(3);not null"`
+	Description   *string   `gorm:"type:text;default:null"`
+	PostedAt      time.Time `gorm:"not null;index"`
+	CreatedAt     *time.Time
+	Postings      []LedgerPosting `gorm:"foreignKey:journal_entry_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// LedgerPosting either debits or credits its account, never both.
+type LedgerPosting struct {
+	ID             uint  `gorm:"primary_key;autoIncrement"`
+	JournalEntryID uint  `gorm:"not null;index"`
+	AccountID      uint  `gorm:"not null;index"`
+	Debit          int64 `gorm:"not null;default:0;check:chk_ledger_postings_side,(debit > 0 AND credit = 0) OR (debit = 0 AND credit > 0)"`
+	Credit         int64 `gorm:"not null;default:0"`
+	CreatedAt      *time.Time
+}
@@ -9,17 +9,57 @@ import (
 type Payment struct {
 	ID               uint                     `gorm:"primary_key;autoIncrement"`
 	UUID             uuid.UUID                `gorm:"type:uuid;not null"`
-	OrderID          uuid.UUID                `gorm:"type:uuid;not null"`
-	Amount           float64                  `gorm:"not null"`
+	OrderID          uuid.UUID                `gorm:"type:uuid;not null;index"`
+	Provider         string                   `gorm:"type:varchar(20);not null;default:'midtrans'"`
+	GatewayOrderID   string                   `gorm:"type:varchar(100);index"`
+	GatewayReference *string                  `gorm:"type:varchar(255);default:null"`
+	RoutingRule      *string                  `gorm:"type:varchar(100);default:null"`
+	RoutingReason    *string                  `gorm:"type:text;default:null"`
+	Attempt          int                      `gorm:"not null;default:1"`
+	SubscriptionID   *uint                    `gorm:"index;default:null"`
+	PaymentLinkID    *uint                    `gorm:"index;default:null"`
+	CustomerID       *uuid.UUID               `gorm:"type:uuid;index;default:null"`
+	Amount           int64                    `gorm:"not null"`
+	Currency         string                   `gorm:"type:varchar(3);not null;default:'IDR'"`
+	AllowPartial     bool                     `gorm:"not null;default:false"`
+	PaidAmount       int64                    `gorm:"not null;default:0"`
+	Authorize        bool                     `gorm:"not null;default:false"`
+	CaptureAmount    *int64                   `gorm:"default:null"`
+	RefundAmount     int64                    `gorm:"not null;default:0"`
+	Discount         int64                    `gorm:"not null;default:0"`
+	VoucherCode      *string                  `gorm:"type:varchar(50);default:null"`
+	TaxRate          *float64                 `gorm:"default:null"`
+	TaxInclusive     bool                     `gorm:"not null;default:false"`
+	TaxBase          int64                    `gorm:"not null;default:0"`
+	TaxAmount        int64                    `gorm:"not null;default:0"`
+	MerchantNPWP     *string                  `gorm:"type:varchar(16);default:null"`
+	CustomerNPWP     *string                  `gorm:"type:varchar(16);default:null"`
+	CustomerName     *string                  `gorm:"type:varchar(255);default:null"`
+	FXRate           *float64                 `gorm:"type:numeric(20,8);default:null"`
+	FXSource         *string                  `gorm:"type:varchar(20);default:null"`
+	ReportingAmount  *int64                   `gorm:"default:null"`
+	Fee              *int64                   `gorm:"default:null"`
+	NetAmount        *int64                   `gorm:"default:null"`
+	FeeSource        *string                  `gorm:"type:varchar(20);default:null"`
 	Status           *constants.PaymentStatus `gorm:"not null"`
 	PaymentLink      string                   `gorm:"type:varchar(255);not null"`
 	InvoiceLink      *string                  `gorm:"type:varchar(255);default:null"`
+	PaymentMethod    *string                  `gorm:"type:varchar(30);default:null"`
 	VANumber         *string                  `gorm:"type:varchar(50);default:null"`
+	PaymentType      *string                  `gorm:"type:varchar(30);default:null"`
+	PaymentChannel   *string                  `gorm:"type:varchar(50);default:null"`
+	PaymentReference *string                  `gorm:"type:varchar(100);default:null"`
+	ApprovalCode     *string                  `gorm:"type:varchar(50);default:null"`
+	QRString         *string                  `gorm:"type:text;default:null"`
+	QRImageURL       *string                  `gorm:"type:text;default:null"`
+	Deeplink         *string                  `gorm:"type:text;default:null"`
 	Bank             *string                  `gorm:"type:varchar(100);default:null"`
 	Acquirer         *string                  `gorm:"type:varchar(100);default:null"`
 	TransactionID    *string                  `gorm:"type:varchar(100);default:null"`
 	Description      *string                  `gorm:"type:text;default:null"`
+	ReviewReason     *string                  `gorm:"type:text;default:null"`
 	PaidAt           *time.Time
+	AuthorizedAt     *time.Time
 	ExpiredAt        *time.Time
 	CreatedAt        *time.Time
 	UpdatedAt        *time.Time
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type PaymentExport struct {
+	ID           uint      `gorm:"primary_key;autoIncrement"`
+	UUID         uuid.UUID `gorm:"type:uuid;not null"`
+	Format       string    `gorm:"type:varchar(10);not null"`
+	Status       string    `gorm:"type:varchar(20);not null"`
+	Filter       *string   `gorm:"type:text;default:null"`
+	RowCount     int       `gorm:"not null;default:0"`
+	DownloadLink *string   `gorm:"type:varchar(255);default:null"`
+	Error        *string   `gorm:"type:text;default:null"`
+	CompletedAt  *time.Time
+	CreatedAt    *time.Time
+	UpdatedAt    *time.Time
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// PaymentLink is shared by admins without an order, every use of it creates a
+// payment of its own. Amount is fixed when set, otherwise the payer chooses
+// one between MinAmount and MaxAmount.
+type PaymentLink struct {
+	ID          uint       `gorm:"primary_key;autoIncrement"`
+	UUID        uuid.UUID  `gorm:"type:uuid;not null"`
+	Code        string     `gorm:"type:varchar(20);not null;uniqueIndex"`
+	Title       string     `gorm:"type:varchar(100);not null"`
+	Description *string    `gorm:"type:text;default:null"`
+	Amount      *int64     `gorm:"default:null"`
+	MinAmount   *int64     `gorm:"default:null"`
+	MaxAmount   *int64     `gorm:"default:null"`
+	Currency    string     `gorm:"type:varchar(3);not null;default:'IDR'"`
+	MaxUses     *int       `gorm:"default:null"`
+	ExpiredAt   *time.Time `gorm:"default:null"`
+	IsActive    bool       `gorm:"not null;default:true"`
+	CreatedBy   *uuid.UUID `gorm:"type:uuid;default:null"`
+	CreatedAt   *time.Time
+	UpdatedAt   *time.Time
+}
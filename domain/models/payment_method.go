@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// PaymentMethod is a card the gateway saved for a customer, Token is the
+// saved token id used to charge it again.
+type PaymentMethod struct {
+	ID         uint      `gorm:"primary_key;autoIncrement"`
+	UUID       uuid.UUID `gorm:"type:uuid;not null"`
+	CustomerID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_payment_methods_customer_token"`
+	Provider   string    `gorm:"type:varchar(20);not null"`
+	Type       string    `gorm:"type:varchar(30);not null"`
+	Token      string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_payment_methods_customer_token"`
+	MaskedCard string    `gorm:"type:varchar(30);not null"`
+	Brand      string    `gorm:"type:varchar(20);not null"`
+	Bank       *string   `gorm:"type:varchar(50);default:null"`
+	CardType   *string   `gorm:"type:varchar(20);default:null"`
+	ExpiredAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  *time.Time
+	UpdatedAt  *time.Time
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// PaymentSplit is the share of a marketplace payment that belongs to a
+// merchant, Fee is the platform commission taken out of it.
+type PaymentSplit struct {
+	ID         uint      `gorm:"primary_key;autoIncrement"`
+	UUID       uuid.UUID `gorm:"type:uuid;not null"`
+	PaymentID  uint      `gorm:"not null;index"`
+	MerchantID string    `gorm:"type:varchar(100);not null;index"`
+	Amount     int64     `gorm:"not null"`
+	Percentage *float64  `gorm:"type:numeric(5,2);default:null"`
+	Fee        int64     `gorm:"not null;default:0"`
+	Currency   string    `gorm:"type:varchar(3);not null;default:'IDR'"`
+	CreatedAt  *time.Time
+	UpdatedAt  *time.Time
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// PaymentTransaction is a single transfer received for a payment, a payment
+// that accepts partial payments settles once they add up to its amount.
+type PaymentTransaction struct {
+	ID            uint      `gorm:"primary_key;autoIncrement"`
+	UUID          uuid.UUID `gorm:"type:uuid;not null"`
+	PaymentID     uint      `gorm:"type:bigint;not null;uniqueIndex:idx_payment_transactions_reference"`
+	Reference     string    `gorm:"type:varchar(150);not null;uniqueIndex:idx_payment_transactions_reference"`
+	TransactionID *string   `gorm:"type:varchar(100);default:null"`
+	Amount        int64     `gorm:"not null"`
+	Currency      string    `gorm:"type:varchar(3);not null;default:'IDR'"`
+	PaidAt        *time.Time
+	CreatedAt     *time.Time
+	UpdatedAt     *time.Time
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type PayoutBatch struct {
+	ID          uint      `gorm:"primary_key;autoIncrement"`
+	UUID        uuid.UUID `gorm:"type:uuid;not null"`
+	Status      string    `gorm:"type:varchar(20);not null"`
+	PayoutCount int       `gorm:"not null;default:0"`
+	FileURL     *string   `gorm:"type:text;default:null"`
+	Error       *string   `gorm:"type:text;default:null"`
+	CreatedAt   *time.Time
+	UpdatedAt   *time.Time
+	Payouts     []Payout `gorm:"foreignKey:payout_batch_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// Payout is the amount a merchant is paid in a batch, it has been moved out
+// of the merchant balance when the batch was created.
+type Payout struct {
+	ID            uint      `gorm:"primary_key;autoIncrement"`
+	UUID          uuid.UUID `gorm:"type:uuid;not null"`
+	PayoutBatchID uint      `gorm:"not null;index"`
+	MerchantID    string    `gorm:"type:varchar(100);not null;index"`
+	Amount        int64     `gorm:"not null"`
+	Currency      string    `gorm:"type:varchar(3);not null;default:'IDR'"`
+	CreatedAt     *time.Time
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type SettlementReconciliation struct {
+	ID                  uint      `gorm:"primary_key;autoIncrement"`
+	UUID                uuid.UUID `gorm:"type:uuid;not null"`
+	FileName            string    `gorm:"type:varchar(255);not null"`
+	Source              string    `gorm:"type:varchar(20);not null"`
+	TotalRows           int       `gorm:"not null"`
+	TotalMatched        int       `gorm:"not null"`
+	TotalAmountMismatch int       `gorm:"not null"`
+	TotalStatusMismatch int       `gorm:"not null"`
+	TotalMissing        int       `gorm:"not null"`
+	TotalDuplicated     int       `gorm:"not null"`
+	CreatedAt           *time.Time
+	UpdatedAt           *time.Time
+	Lines               []SettlementReconciliationLine `gorm:"foreignKey:settlement_reconciliation_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type SettlementReconciliationLine struct {
+	ID                         uint    `gorm:"primary_key;autoIncrement"`
+	SettlementReconciliationID uint    `gorm:"type:bigint;not null"`
+	RowNumber                  *int    `gorm:"default:null"`
+	PaymentID                  *uint   `gorm:"type:bigint;default:null"`
+	OrderID                    *string `gorm:"type:varchar(100);default:null"`
+	TransactionID              *string `gorm:"type:varchar(100);default:null"`
+	PaymentType                *string `gorm:"type:varchar(50);default:null"`
+	ReportAmount               *int64  `gorm:"default:null"`
+	PaymentAmount              *int64  `gorm:"default:null"`
+	ReportFee                  *int64  `gorm:"default:null"`
+	Currency                   string  `gorm:"type:varchar(3);not null;default:'IDR'"`
+	SettlementTime             *time.Time
+	Result                     string  `gorm:"type:varchar(50);not null"`
+	Note                       *string `gorm:"type:text;default:null"`
+	CreatedAt                  *time.Time
+	UpdatedAt                  *time.Time
+}
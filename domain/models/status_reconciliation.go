@@ -0,0 +1,33 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type StatusReconciliation struct {
+	ID                 uint      `gorm:"primary_key;autoIncrement"`
+	UUID               uuid.UUID `gorm:"type:uuid;not null"`
+	StartDate          *time.Time
+	EndDate            *time.Time
+	OrderIDs           *string `gorm:"type:text;default:null"`
+	TotalChecked       int     `gorm:"not null"`
+	TotalDiscrepancies int     `gorm:"not null"`
+	TotalApplied       int     `gorm:"not null"`
+	CreatedAt          *time.Time
+	UpdatedAt          *time.Time
+	Discrepancies      []StatusDiscrepancy `gorm:"foreignKey:status_reconciliation_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type StatusDiscrepancy struct {
+	ID                     uint      `gorm:"primary_key;autoIncrement"`
+	StatusReconciliationID uint      `gorm:"type:bigint;not null"`
+	PaymentID              uint      `gorm:"type:bigint;not null"`
+	OrderID                uuid.UUID `gorm:"type:uuid;not null"`
+	LocalStatus            string    `gorm:"type:varchar(50);not null"`
+	GatewayStatus          string    `gorm:"type:varchar(50);not null"`
+	Action                 string    `gorm:"type:varchar(50);not null"`
+	Note                   *string   `gorm:"type:text;default:null"`
+	CreatedAt              *time.Time
+	UpdatedAt              *time.Time
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"payment-service/constants"
+	"time"
+)
+
+type SubscriptionPlan struct {
+	ID            uint      `gorm:"primary_key;autoIncrement"`
+	UUID          uuid.UUID `gorm:"type:uuid;not null"`
+	Code          string    `gorm:"type:varchar(50);not null;uniqueIndex"`
+	Name          string    `gorm:"type:varchar(100);not null"`
+	Description   *string   `gorm:"type:text;default:null"`
+	Amount        int64     `gorm:"not null"`
+	Currency      string    `gorm:"type:varchar(3);not null;default:'IDR'"`
+	Interval      string    `gorm:"type:varchar(10);not null"`
+	IntervalCount int       `gorm:"not null;default:1"`
+	TrialDays     int       `gorm:"not null;default:0"`
+	IsActive      bool      `gorm:"not null;default:true"`
+	CreatedAt     *time.Time
+	UpdatedAt     *time.Time
+}
+
+// Subscription bills a customer for a plan every period by charging the card
+// token or linked GoPay account in PaymentToken.
+type Subscription struct {
+	ID                 uint                         `gorm:"primary_key;autoIncrement"`
+	UUID               uuid.UUID                    `gorm:"type:uuid;not null"`
+	PlanID             uint                         `gorm:"not null;index"`
+	CustomerID         uuid.UUID                    `gorm:"type:uuid;not null;index"`
+	CustomerName       string                       `gorm:"type:varchar(255);not null"`
+	CustomerEmail      string                       `gorm:"type:varchar(255);not null"`
+	CustomerPhone      *string                      `gorm:"type:varchar(20);default:null"`
+	PaymentMethod      string                       `gorm:"type:varchar(30);not null"`
+	PaymentToken       string                       `gorm:"type:varchar(255);not null"`
+	Status             constants.SubscriptionStatus `gorm:"type:varchar(20);not null;index"`
+	Cycle              int                          `gorm:"not null;default:0"`
+	RetryCount         int                          `gorm:"not null;default:0"`
+	CurrentPeriodStart *time.Time
+	CurrentPeriodEnd   *time.Time
+	NextBillingAt      *time.Time `gorm:"index"`
+	PendingPaymentID   *uuid.UUID `gorm:"type:uuid;default:null"`
+	LastPaymentID      *uuid.UUID `gorm:"type:uuid;default:null"`
+	FailureReason      *string    `gorm:"type:text;default:null"`
+	CancelledAt        *time.Time
+	CancelReason       *string `gorm:"type:text;default:null"`
+	CreatedAt          *time.Time
+	UpdatedAt          *time.Time
+	Plan               SubscriptionPlan `gorm:"foreignKey:PlanID;references:ID"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Voucher discounts a payment by a percentage or a fixed amount. Usage is
+// counted from its redemptions that are reserved or redeemed.
+type Voucher struct {
+	ID           uint       `gorm:"primary_key;autoIncrement"`
+	UUID         uuid.UUID  `gorm:"type:uuid;not null"`
+	Code         string     `gorm:"type:varchar(50);not null;uniqueIndex"`
+	Type         string     `gorm:"type:varchar(20);not null"`
+	Percentage   *float64   `gorm:"type:numeric(5,2);default:null"`
+	Amount       *int64     `gorm:"default:null"`
+	Currency     string     `gorm:"type:varchar(3);not null;default:'IDR'"`
+	MinSpend     int64      `gorm:"not null;default:0"`
+	MaxDiscount  *int64     `gorm:"default:null"`
+	UsageLimit   *int       `gorm:"default:null"`
+	PerUserLimit *int       `gorm:"default:null"`
+	StartsAt     *time.Time `gorm:"default:null"`
+	EndsAt       *time.Time `gorm:"default:null"`
+	IsActive     bool       `gorm:"not null;default:true"`
+	CreatedAt    *time.Time
+	UpdatedAt    *time.Time
+}
+
+// VoucherRedemption is the use of a voucher by one payment, reserved when the
+// payment is created until it settles or is closed unpaid.
+type VoucherRedemption struct {
+	ID         uint       `gorm:"primary_key;autoIncrement"`
+	UUID       uuid.UUID  `gorm:"type:uuid;not null"`
+	VoucherID  uint       `gorm:"not null;index"`
+	PaymentID  uint       `gorm:"not null;uniqueIndex"`
+	CustomerID *uuid.UUID `gorm:"type:uuid;index;default:null"`
+	Discount   int64      `gorm:"not null"`
+	Currency   string     `gorm:"type:varchar(3);not null;default:'IDR'"`
+	Status     string     `gorm:"type:varchar(20);not null;index"`
+	CreatedAt  *time.Time
+	UpdatedAt  *time.Time
+}
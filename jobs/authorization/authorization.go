@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"payment-service/config"
+	"payment-service/services"
+	"time"
+)
+
+type IAuthorizationVoidJob interface {
+	Start(context.Context)
+}
+
+func NewAuthorizationVoidJob(service services.IServiceRegistry) IAuthorizationVoidJob {
+	return &AuthorizationVoidJob{service: service}
+}
+
+type AuthorizationVoidJob struct {
+	service services.IServiceRegistry
+}
+
+func (a *AuthorizationVoidJob) Start(ctx context.Context) {
+	cfg := config.Config.Authorization
+	if !cfg.Enabled || cfg.IntervalMinutes <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(cfg.IntervalMinutes) * time.Minute)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.run(ctx)
+			}
+		}
+	}()
+}
+
+func (a *AuthorizationVoidJob) run(ctx context.Context) {
+	voided, err := a.service.GetPayment().VoidStaleAuthorizations(ctx)
+	if err != nil {
+		logrus.Errorf("failed to run authorization void job: %v", err)
+		return
+	}
+	if voided > 0 {
+		logrus.Infof("voided %d stale authorizations", voided)
+	}
+}
@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"payment-service/config"
+	"payment-service/services"
+	"time"
+)
+
+const defaultCleanupInterval = time.Hour
+
+type IIdempotencyCleanupJob interface {
+	Start(context.Context)
+}
+
+func NewIdempotencyCleanupJob(service services.IServiceRegistry) IIdempotencyCleanupJob {
+	return &IdempotencyCleanupJob{service: service}
+}
+
+type IdempotencyCleanupJob struct {
+	service services.IServiceRegistry
+}
+
+func (i *IdempotencyCleanupJob) Start(ctx context.Context) {
+	interval := defaultCleanupInterval
+	if config.Config.Idempotency.CleanupIntervalInMinutes > 0 {
+		interval = time.Duration(config.Config.Idempotency.CleanupIntervalInMinutes) * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				i.run(ctx)
+			}
+		}
+	}()
+}
+
+func (i *IdempotencyCleanupJob) run(ctx context.Context) {
+	deleted, err := i.service.GetIdempotency().DeleteExpired(ctx)
+	if err != nil {
+		logrus.Errorf("failed to delete expired idempotency keys: %v", err)
+		return
+	}
+	logrus.Infof("deleted %d expired idempotency keys", deleted)
+}
@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"payment-service/config"
+	errLedger "payment-service/constants/error/ledger"
+	"payment-service/services"
+	"time"
+)
+
+type IPayoutBatchJob interface {
+	Start(context.Context)
+}
+
+func NewPayoutBatchJob(service services.IServiceRegistry) IPayoutBatchJob {
+	return &PayoutBatchJob{service: service}
+}
+
+type PayoutBatchJob struct {
+	service services.IServiceRegistry
+}
+
+func (p *PayoutBatchJob) Start(ctx context.Context) {
+	cfg := config.Config.Payout
+	if !cfg.Enabled || cfg.IntervalMinutes <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(cfg.IntervalMinutes) * time.Minute)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.run(ctx)
+			}
+		}
+	}()
+}
+
+func (p *PayoutBatchJob) run(ctx context.Context) {
+	batch, err := p.service.GetPayout().CreateBatch(ctx)
+	if errors.Is(err, errLedger.ErrNoPayableBalance) {
+		return
+	}
+	if err != nil {
+		logrus.Errorf("failed to run payout batch job: %v", err)
+		return
+	}
+	logrus.Infof("created payout batch %s with %d payouts, %s", batch.UUID, batch.PayoutCount, batch.Status)
+}
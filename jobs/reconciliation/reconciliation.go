@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"payment-service/config"
+	errReconciliation "payment-service/constants/error/reconciliation"
+	"payment-service/domain/dto"
+	"payment-service/services"
+	"time"
+)
+
+// defaultLookbackHours is used when reconciliation.lookbackHours is unset.
+const defaultLookbackHours = 24
+
+type IReconciliationJob interface {
+	Start(context.Context)
+}
+
+func NewReconciliationJob(service services.IServiceRegistry) IReconciliationJob {
+	return &ReconciliationJob{service: service}
+}
+
+type ReconciliationJob struct {
+	service services.IServiceRegistry
+}
+
+func (r *ReconciliationJob) Start(ctx context.Context) {
+	cfg := config.Config.Reconciliation
+	if !cfg.Enabled || cfg.IntervalMinutes <= 0 {
+		return
+	}
+
+	lookbackHours := cfg.LookbackHours
+	if lookbackHours <= 0 {
+		lookbackHours = defaultLookbackHours
+	}
+	ticker := time.NewTicker(time.Duration(cfg.IntervalMinutes) * time.Minute)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.run(ctx, time.Duration(lookbackHours)*time.Hour)
+			}
+		}
+	}()
+}
+
+func (r *ReconciliationJob) run(ctx context.Context, lookback time.Duration) {
+	endDate := time.Now()
+	startDate := endDate.Add(-lookback)
+	report, err := r.service.GetReconciliation().Reconcile(ctx, &dto.ReconcileRequest{
+		StartDate: &startDate,
+		EndDate:   &endDate,
+	})
+	if errors.Is(err, errReconciliation.ErrReconcileInProgress) {
+		logrus.Infof("skipping reconciliation job, another replica is running it")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("failed to run reconciliation job: %v", err)
+		return
+	}
+	logrus.Infof("reconciliation %s checked %d payments, found %d discrepancies, applied %d",
+		report.UUID, report.TotalChecked, report.TotalDiscrepancies, report.TotalApplied)
+}
@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	jobs4 "payment-service/jobs/authorization"
+	jobs2 "payment-service/jobs/idempotency"
+	jobs5 "payment-service/jobs/payout"
+	jobs "payment-service/jobs/reconciliation"
+	jobs3 "payment-service/jobs/subscription"
+	"payment-service/services"
+)
+
+type IJobRegistry interface {
+	Start(context.Context)
+}
+
+func NewJobRegistry(service services.IServiceRegistry) IJobRegistry {
+	return &Registry{service: service}
+}
+
+type Registry struct {
+	service services.IServiceRegistry
+}
+
+func (r *Registry) Start(ctx context.Context) {
+	r.reconciliationJob().Start(ctx)
+	r.idempotencyCleanupJob().Start(ctx)
+	r.subscriptionBillingJob().Start(ctx)
+	r.authorizationVoidJob().Start(ctx)
+	r.payoutBatchJob().Start(ctx)
+}
+
+func (r *Registry) reconciliationJob() jobs.IReconciliationJob {
+	return jobs.NewReconciliationJob(r.service)
+}
+
+func (r *Registry) idempotencyCleanupJob() jobs2.IIdempotencyCleanupJob {
+	return jobs2.NewIdempotencyCleanupJob(r.service)
+}
+
+func (r *Registry) subscriptionBillingJob() jobs3.ISubscriptionBillingJob {
+	return jobs3.NewSubscriptionBillingJob(r.service)
+}
+
+func (r *Registry) authorizationVoidJob() jobs4.IAuthorizationVoidJob {
+	return jobs4.NewAuthorizationVoidJob(r.service)
+}
+
+func (r *Registry) payoutBatchJob() jobs5.IPayoutBatchJob {
+	return jobs5.NewPayoutBatchJob(r.service)
+}
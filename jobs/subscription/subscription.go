@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"payment-service/config"
+	"payment-service/services"
+	"time"
+)
+
+type ISubscriptionBillingJob interface {
+	Start(context.Context)
+}
+
+func NewSubscriptionBillingJob(service services.IServiceRegistry) ISubscriptionBillingJob {
+	return &SubscriptionBillingJob{service: service}
+}
+
+type SubscriptionBillingJob struct {
+	service services.IServiceRegistry
+}
+
+func (s *SubscriptionBillingJob) Start(ctx context.Context) {
+	cfg := config.Config.Subscription
+	if !cfg.Enabled || cfg.IntervalMinutes <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(cfg.IntervalMinutes) * time.Minute)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.run(ctx)
+			}
+		}
+	}()
+}
+
+func (s *SubscriptionBillingJob) run(ctx context.Context) {
+	result, err := s.service.GetSubscription().Bill(ctx)
+	if err != nil {
+		logrus.Errorf("failed to run subscription billing job: %v", err)
+		return
+	}
+	logrus.Infof("subscription billing charged %d, renewed %d, failed %d",
+		result.Charged, result.Renewed, result.Failed)
+}
@@ -0,0 +1,125 @@
+package middlewares
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"payment-service/common/response"
+	"payment-service/common/utils"
+	"payment-service/constants"
+	errConstant "payment-service/constants/error"
+	errIdempotency "payment-service/constants/error/idempotency"
+	"payment-service/domain/dto"
+	"payment-service/services"
+)
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(data string) (int, error) {
+	r.body.WriteString(data)
+	return r.ResponseWriter.WriteString(data)
+}
+
+func responseIdempotencyError(c *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	message := errConstant.ErrInternalServerError.Error()
+	switch {
+	case errors.Is(err, errIdempotency.ErrIdempotencyKeyMismatch),
+		errors.Is(err, errIdempotency.ErrIdempotencyKeyInProgress):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, errIdempotency.ErrIdempotencyKeyTooLong):
+		code = http.StatusBadRequest
+		message = err.Error()
+	}
+	c.JSON(code, response.Response{
+		Status:  constants.Error,
+		Message: message,
+	})
+	c.Abort()
+}
+
+func Idempotency(service services.IServiceRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(constants.IdempotencyKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			responseIdempotencyError(c, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		request := &dto.IdempotencyRequest{
+			Scope:       fmt.Sprintf("%s %s %s", idempotencyCaller(c), c.Request.Method, c.FullPath()),
+			Key:         key,
+			RequestHash: utils.GenerateSHA256(string(body)),
+		}
+		result, err := service.GetIdempotency().Begin(c.Request.Context(), request)
+		if err != nil {
+			responseIdempotencyError(c, err)
+			return
+		}
+		if result.Replay {
+			c.Header(constants.IdempotentReplayed, "true")
+			c.Data(result.Response.StatusCode, gin.MIMEJSON, result.Response.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+		defer func() {
+			if r := recover(); r != nil {
+				releaseIdempotencyKey(c, service, request)
+				panic(r)
+			}
+		}()
+		c.Next()
+
+		// Server errors are not stored so the client can retry with the same key.
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			releaseIdempotencyKey(c, service, request)
+			return
+		}
+		err = service.GetIdempotency().Complete(c.Request.Context(), request, &dto.IdempotencyResponse{
+			StatusCode: c.Writer.Status(),
+			Body:       recorder.body.Bytes(),
+		})
+		if err != nil {
+			logrus.Errorf("failed to store idempotency key %s: %v", key, err)
+		}
+	}
+}
+
+// idempotencyCaller keeps the keys of one caller apart from those of another,
+// who must not be handed the stored response by sending the same key.
+func idempotencyCaller(c *gin.Context) string {
+	if user := CurrentUser(c); user != nil {
+		return user.UUID.String()
+	}
+	return c.GetHeader(constants.XServiceName)
+}
+
+func releaseIdempotencyKey(c *gin.Context, service services.IServiceRegistry, request *dto.IdempotencyRequest) {
+	err := service.GetIdempotency().Release(c.Request.Context(), request)
+	if err != nil {
+		logrus.Errorf("failed to release idempotency key %s: %v", request.Key, err)
+	}
+}
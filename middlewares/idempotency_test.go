@@ -0,0 +1,187 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	clients2 "payment-service/clients/user"
+	"payment-service/constants"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories/fake"
+	repositories2 "payment-service/repositories/idempotency_key"
+	"payment-service/services"
+	services2 "payment-service/services/idempotency"
+)
+
+type fakeIdempotencyKeyRepository struct {
+	repositories2.IIdempotencyKeyRepository
+	keys map[string]*models.IdempotencyKey
+}
+
+func (f *fakeIdempotencyKeyRepository) id(scope, key string) string {
+	return scope + "|" + key
+}
+
+func (f *fakeIdempotencyKeyRepository) FindByScopeAndKey(_ context.Context, scope, key string) (*models.IdempotencyKey, error) {
+	return f.keys[f.id(scope, key)], nil
+}
+
+func (f *fakeIdempotencyKeyRepository) CreateIfNotExists(
+	_ context.Context,
+	_ *gorm.DB,
+	request *dto.IdempotencyRequest,
+	expiredAt time.Time,
+) (bool, error) {
+	id := f.id(request.Scope, request.Key)
+	if _, ok := f.keys[id]; ok {
+		return false, nil
+	}
+	f.keys[id] = &models.IdempotencyKey{
+		Scope:       request.Scope,
+		Key:         request.Key,
+		RequestHash: request.RequestHash,
+		ExpiredAt:   expiredAt,
+	}
+	return true, nil
+}
+
+func (f *fakeIdempotencyKeyRepository) UpdateResponse(
+	_ context.Context,
+	_ *gorm.DB,
+	request *dto.IdempotencyRequest,
+	response *dto.IdempotencyResponse,
+) error {
+	key := f.keys[f.id(request.Scope, request.Key)]
+	body := string(response.Body)
+	key.StatusCode = &response.StatusCode
+	key.ResponseBody = &body
+	return nil
+}
+
+func (f *fakeIdempotencyKeyRepository) Delete(_ context.Context, _ *gorm.DB, request *dto.IdempotencyRequest) error {
+	delete(f.keys, f.id(request.Scope, request.Key))
+	return nil
+}
+
+type fakeServiceRegistry struct {
+	services.IServiceRegistry
+	idempotency services2.IIdempotencyService
+}
+
+func (f *fakeServiceRegistry) GetIdempotency() services2.IIdempotencyService {
+	return f.idempotency
+}
+
+// newIdempotencyRouter counts the requests that reach the handler, a replayed
+// request must not.
+func newIdempotencyRouter(calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	keys := &fakeIdempotencyKeyRepository{keys: map[string]*models.IdempotencyKey{}}
+	service := &fakeServiceRegistry{
+		idempotency: services2.NewIdempotencyService(&fake.Registry{IdempotencyKey: keys}),
+	}
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			c.Set(constants.User, &clients2.UserData{UUID: uuid.MustParse(user)})
+		}
+		c.Next()
+	})
+	router.POST("/payment", Idempotency(service), func(c *gin.Context) {
+		*calls++
+		if c.Query("fail") != "" {
+			c.JSON(http.StatusInternalServerError, gin.H{"call": *calls})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func sendIdempotent(router *gin.Engine, path, key, user, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	request.Header.Set(constants.IdempotencyKey, key)
+	if user != "" {
+		request.Header.Set("X-Test-User", user)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIdempotencyReplaysStoredResponse(t *testing.T) {
+	var calls int
+	router := newIdempotencyRouter(&calls)
+	user := uuid.NewString()
+
+	first := sendIdempotent(router, "/payment", "key-1", user, `{"amount":10000}`)
+	second := sendIdempotent(router, "/payment", "key-1", user, `{"amount":10000}`)
+
+	if calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(constants.IdempotentReplayed) != "true" {
+		t.Errorf("replay is missing the %s header", constants.IdempotentReplayed)
+	}
+	if first.Header().Get(constants.IdempotentReplayed) != "" {
+		t.Errorf("first response carries the %s header", constants.IdempotentReplayed)
+	}
+}
+
+func TestIdempotencyRejectsOtherBody(t *testing.T) {
+	var calls int
+	router := newIdempotencyRouter(&calls)
+	user := uuid.NewString()
+
+	sendIdempotent(router, "/payment", "key-1", user, `{"amount":10000}`)
+	second := sendIdempotent(router, "/payment", "key-1", user, `{"amount":20000}`)
+
+	if second.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d", second.Code, http.StatusConflict)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+}
+
+func TestIdempotencyKeysAreScopedToCaller(t *testing.T) {
+	var calls int
+	router := newIdempotencyRouter(&calls)
+
+	first := sendIdempotent(router, "/payment", "key-1", uuid.NewString(), `{"amount":10000}`)
+	second := sendIdempotent(router, "/payment", "key-1", uuid.NewString(), `{"amount":10000}`)
+
+	if calls != 2 {
+		t.Fatalf("handler called %d times, want 2", calls)
+	}
+	if second.Header().Get(constants.IdempotentReplayed) != "" || second.Body.String() == first.Body.String() {
+		t.Errorf("another caller was handed the stored response %s", second.Body)
+	}
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	var calls int
+	router := newIdempotencyRouter(&calls)
+	user := uuid.NewString()
+
+	first := sendIdempotent(router, "/payment?fail=1", "key-1", user, `{"amount":10000}`)
+	second := sendIdempotent(router, "/payment?fail=1", "key-1", user, `{"amount":10000}`)
+
+	if first.Code != http.StatusInternalServerError || second.Code != http.StatusInternalServerError {
+		t.Fatalf("statuses = %d, %d, want %d", first.Code, second.Code, http.StatusInternalServerError)
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want the retry to run again", calls)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"net/http"
 	"payment-service/clients"
+	clients2 "payment-service/clients/user"
 	"payment-service/common/response"
 	"payment-service/config"
 	"payment-service/constants"
@@ -104,10 +105,18 @@ func CheckRole(roles []string, client clients.IClientRegistry) gin.HandlerFunc {
 			responseUnauthorized(c, errConstant.ErrUnauthorized.Error())
 			return
 		}
+		c.Set(constants.User, user)
 		c.Next()
 	}
 }
 
+// CurrentUser returns the user CheckRole resolved for the request.
+func CurrentUser(c *gin.Context) *clients2.UserData {
+	value, _ := c.Get(constants.User)
+	user, _ := value.(*clients2.UserData)
+	return user
+}
+
 func Authenticate() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var err error
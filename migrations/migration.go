@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"time"
+)
+
+type Migration struct {
+	ID      string
+	Migrate func(*gorm.DB) error
+	// BeforeAutoMigrate marks column conversions AutoMigrate would otherwise
+	// apply with a plain cast.
+	BeforeAutoMigrate bool
+}
+
+type SchemaMigration struct {
+	ID        string `gorm:"primary_key;type:varchar(100)"`
+	CreatedAt *time.Time
+}
+
+// migrations holds data and index changes AutoMigrate can not express. They
+// run once, in order, after AutoMigrate unless marked BeforeAutoMigrate.
+var migrations = []Migration{
+	{ID: "0001_payment_attempts", Migrate: paymentAttempts},
+	{ID: "0002_amount_minor_units", Migrate: amountMinorUnits, BeforeAutoMigrate: true},
+	{ID: "0003_payment_transactions", Migrate: paymentTransactions},
+	{ID: "0004_settlement_amount_minor_units", Migrate: settlementAmountMinorUnits, BeforeAutoMigrate: true},
+	{ID: "0005_payment_active_statuses", Migrate: paymentActiveStatuses},
+}
+
+func RunBeforeAutoMigrate(db *gorm.DB) error {
+	return apply(db, true)
+}
+
+func Run(db *gorm.DB) error {
+	return apply(db, false)
+}
+
+func apply(db *gorm.DB, beforeAutoMigrate bool) error {
+	err := db.AutoMigrate(&SchemaMigration{})
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if migration.BeforeAutoMigrate != beforeAutoMigrate {
+			continue
+		}
+		var applied SchemaMigration
+		err = db.Where("id = ?", migration.ID).First(&applied).Error
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Migrate(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{ID: migration.ID}).Error
+		})
+		if err != nil {
+			logrus.Errorf("failed to run migration %s: %v", migration.ID, err)
+			return err
+		}
+		logrus.Infof("migration %s applied", migration.ID)
+	}
+	return nil
+}
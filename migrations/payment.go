@@ -0,0 +1,110 @@
+package migrations
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+)
+
+func paymentAttempts(tx *gorm.DB) error {
+	statements := []string{
+		`UPDATE payments SET gateway_order_id = order_id::text WHERE gateway_order_id IS NULL`,
+		`UPDATE payments p SET status = 300
+			WHERE p.status IN (0, 100)
+			AND EXISTS (
+				SELECT 1 FROM payments q
+				WHERE q.order_id = p.order_id AND q.status IN (0, 100) AND q.id > p.id
+			)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_active_order_id
+			ON payments (order_id) WHERE status IN (0, 100)`,
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// paymentActiveStatuses widens the one active payment per order index to the
+// partially paid (150), authorized (170) and needs review (500) payments that
+// block a new attempt as well.
+func paymentActiveStatuses(tx *gorm.DB) error {
+	statements := []string{
+		`DROP INDEX IF EXISTS idx_payments_active_order_id`,
+		`CREATE UNIQUE INDEX idx_payments_active_order_id
+			ON payments (order_id) WHERE status IN (0, 100, 150, 170, 500)`,
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type amountColumn struct {
+	table  string
+	column string
+}
+
+// amountMinorUnits turns the float amounts, all in rupiah until now, into
+// integer sen before AutoMigrate switches the columns to bigint.
+func amountMinorUnits(tx *gorm.DB) error {
+	return toMinorUnits(tx, []amountColumn{
+		{table: "payments", column: "amount"},
+	})
+}
+
+// settlementAmountMinorUnits does the same for the amounts of settlement
+// report lines, Midtrans reports are in rupiah.
+func settlementAmountMinorUnits(tx *gorm.DB) error {
+	return toMinorUnits(tx, []amountColumn{
+		{table: "settlement_reconciliation_lines", column: "report_amount"},
+		{table: "settlement_reconciliation_lines", column: "payment_amount"},
+		{table: "settlement_reconciliation_lines", column: "report_fee"},
+	})
+}
+
+func toMinorUnits(tx *gorm.DB, columns []amountColumn) error {
+	for _, item := range columns {
+		if !tx.Migrator().HasColumn(item.table, item.column) {
+			continue
+		}
+		var dataType string
+		err := tx.Raw(`SELECT data_type FROM information_schema.columns
+			WHERE table_schema = CURRENT_SCHEMA() AND table_name = ? AND column_name = ?`,
+			item.table, item.column).Scan(&dataType).Error
+		if err != nil {
+			return err
+		}
+		if dataType == "bigint" {
+			continue
+		}
+		statement := fmt.Sprintf(`ALTER TABLE %[1]s ALTER COLUMN %[2]s TYPE bigint USING ROUND(%[2]s * 100)::bigint`,
+			item.table, item.column)
+		if err = tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// paymentTransactions records the single transfer of every payment settled
+// before partial payments were tracked, so paid_amount holds for all of them.
+func paymentTransactions(tx *gorm.DB) error {
+	statements := []string{
+		`INSERT INTO payment_transactions
+			(uuid, payment_id, reference, transaction_id, amount, currency, paid_at, created_at, updated_at)
+			SELECT md5(random()::text || id::text)::uuid, id, COALESCE(transaction_id, gateway_order_id),
+				transaction_id, amount, currency, paid_at, NOW(), NOW()
+			FROM payments WHERE status = 200
+			ON CONFLICT DO NOTHING`,
+		`UPDATE payments SET paid_amount = amount WHERE status = 200`,
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package fake
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var errNoDatabase = errors.New("fake: no database behind the connection")
+
+// DB returns a connection whose transactions begin and commit without a
+// database, so services that wrap fake repositories in a transaction can run.
+// Any statement sent through it fails.
+func DB() *gorm.DB {
+	db, err := gorm.Open(dialector{}, &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+type dialector struct{}
+
+func (dialector) Name() string {
+	return "fake"
+}
+
+func (dialector) Initialize(db *gorm.DB) error {
+	db.ConnPool = pool{}
+	return nil
+}
+
+func (dialector) Migrator(*gorm.DB) gorm.Migrator {
+	return nil
+}
+
+func (dialector) DataTypeOf(*schema.Field) string {
+	return ""
+}
+
+func (dialector) DefaultValueOf(*schema.Field) clause.Expression {
+	return clause.Expr{}
+}
+
+func (dialector) BindVarTo(writer clause.Writer, _ *gorm.Statement, _ interface{}) {
+	_ = writer.WriteByte('?')
+}
+
+func (dialector) QuoteTo(writer clause.Writer, value string) {
+	_, _ = writer.WriteString(value)
+}
+
+func (dialector) Explain(sql string, _ ...interface{}) string {
+	return sql
+}
+
+func (dialector) SavePoint(*gorm.DB, string) error {
+	return nil
+}
+
+func (dialector) RollbackTo(*gorm.DB, string) error {
+	return nil
+}
+
+type pool struct{}
+
+func (pool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	return &tx{}, nil
+}
+
+func (pool) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errNoDatabase
+}
+
+func (pool) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, errNoDatabase
+}
+
+func (pool) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errNoDatabase
+}
+
+func (pool) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return &sql.Row{}
+}
+
+type tx struct {
+	pool
+}
+
+func (*tx) Commit() error {
+	return nil
+}
+
+func (*tx) Rollback() error {
+	return nil
+}
@@ -0,0 +1,105 @@
+package fake
+
+import (
+	"gorm.io/gorm"
+	repositories6 "payment-service/repositories/idempotency_key"
+	repositories12 "payment-service/repositories/ledger"
+	repositories "payment-service/repositories/payment"
+	repositories5 "payment-service/repositories/payment_export"
+	repositories2 "payment-service/repositories/payment_history"
+	repositories15 "payment-service/repositories/payment_link"
+	repositories10 "payment-service/repositories/payment_method"
+	repositories11 "payment-service/repositories/payment_split"
+	repositories7 "payment-service/repositories/payment_transaction"
+	repositories13 "payment-service/repositories/payout"
+	repositories4 "payment-service/repositories/settlement_reconciliation"
+	repositories3 "payment-service/repositories/status_reconciliation"
+	repositories9 "payment-service/repositories/subscription"
+	repositories8 "payment-service/repositories/subscription_plan"
+	repositories14 "payment-service/repositories/voucher"
+)
+
+// Registry hands out the repositories a test sets on it, repositories left
+// unset are nil. DB is returned by GetTx.
+type Registry struct {
+	DB                       *gorm.DB
+	Payment                  repositories.IPaymentRepository
+	PaymentHistory           repositories2.IPaymentHistoryRepository
+	StatusReconciliation     repositories3.IStatusReconciliationRepository
+	SettlementReconciliation repositories4.ISettlementReconciliationRepository
+	PaymentExport            repositories5.IPaymentExportRepository
+	IdempotencyKey           repositories6.IIdempotencyKeyRepository
+	PaymentTransaction       repositories7.IPaymentTransactionRepository
+	SubscriptionPlan         repositories8.ISubscriptionPlanRepository
+	Subscription             repositories9.ISubscriptionRepository
+	PaymentMethod            repositories10.IPaymentMethodRepository
+	PaymentSplit             repositories11.IPaymentSplitRepository
+	Ledger                   repositories12.ILedgerRepository
+	Payout                   repositories13.IPayoutRepository
+	Voucher                  repositories14.IVoucherRepository
+	PaymentLink              repositories15.IPaymentLinkRepository
+}
+
+func (r *Registry) GetTx() *gorm.DB {
+	return r.DB
+}
+
+func (r *Registry) GetPayment() repositories.IPaymentRepository {
+	return r.Payment
+}
+
+func (r *Registry) GetPaymentHistory() repositories2.IPaymentHistoryRepository {
+	return r.PaymentHistory
+}
+
+func (r *Registry) GetStatusReconciliation() repositories3.IStatusReconciliationRepository {
+	return r.StatusReconciliation
+}
+
+func (r *Registry) GetSettlementReconciliation() repositories4.ISettlementReconciliationRepository {
+	return r.SettlementReconciliation
+}
+
+func (r *Registry) GetPaymentExport() repositories5.IPaymentExportRepository {
+	return r.PaymentExport
+}
+
+func (r *Registry) GetIdempotencyKey() repositories6.IIdempotencyKeyRepository {
+	return r.IdempotencyKey
+}
+
+func (r *Registry) GetPaymentTransaction() repositories7.IPaymentTransactionRepository {
+	return r.PaymentTransaction
+}
+
+func (r *Registry) GetSubscriptionPlan() repositories8.ISubscriptionPlanRepository {
+	return r.SubscriptionPlan
+}
+
+func (r *Registry) GetSubscription() repositories9.ISubscriptionRepository {
+	return r.Subscription
+}
+
+func (r *Registry) GetPaymentMethod() repositories10.IPaymentMethodRepository {
+	return r.PaymentMethod
+}
+
+func (r *Registry) GetPaymentSplit() repositories11.IPaymentSplitRepository {
+	return r.PaymentSplit
+}
+
+func (r *Registry) GetLedger() repositories12.ILedgerRepository {
+	return r.Ledger
+}
+
+func (r *Registry) GetPayout() repositories13.IPayoutRepository {
+	return r.Payout
+}
+
+func (r *Registry) GetVoucher() repositories14.IVoucherRepository {
+	return r.Voucher
+}
+
+func (r *Registry) GetPaymentLink() repositories15.IPaymentLinkRepository {
+	return r.PaymentLink
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	errWrap "payment-service/common/error"
+	errConstant "payment-service/constants/error"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"time"
+)
+
+type IIdempotencyKeyRepository interface {
+	FindByScopeAndKey(context.Context, string, string) (*models.IdempotencyKey, error)
+	CreateIfNotExists(context.Context, *gorm.DB, *dto.IdempotencyRequest, time.Time) (bool, error)
+	UpdateResponse(context.Context, *gorm.DB, *dto.IdempotencyRequest, *dto.IdempotencyResponse) error
+	Delete(context.Context, *gorm.DB, *dto.IdempotencyRequest) error
+	DeleteExpired(context.Context, *gorm.DB, time.Time) (int64, error)
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) IIdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{db: db}
+}
+
+type IdempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+func (i *IdempotencyKeyRepository) FindByScopeAndKey(ctx context.Context, scope, key string) (*models.IdempotencyKey, error) {
+	var idempotencyKey models.IdempotencyKey
+	err := i.db.WithContext(ctx).
+		Where("scope = ? AND key = ?", scope, key).
+		First(&idempotencyKey).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &idempotencyKey, nil
+}
+
+func (i *IdempotencyKeyRepository) CreateIfNotExists(
+	ctx context.Context,
+	tx *gorm.DB,
+	request *dto.IdempotencyRequest,
+	expiredAt time.Time,
+) (bool, error) {
+	idempotencyKey := models.IdempotencyKey{
+		Scope:       request.Scope,
+		Key:         request.Key,
+		RequestHash: request.RequestHash,
+		ExpiredAt:   expiredAt,
+	}
+	result := tx.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&idempotencyKey)
+	if result.Error != nil {
+		return false, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (i *IdempotencyKeyRepository) UpdateResponse(
+	ctx context.Context,
+	tx *gorm.DB,
+	request *dto.IdempotencyRequest,
+	response *dto.IdempotencyResponse,
+) error {
+	body := string(response.Body)
+	err := tx.WithContext(ctx).
+		Where("scope = ? AND key = ?", request.Scope, request.Key).
+		Updates(&models.IdempotencyKey{
+			StatusCode:   &response.StatusCode,
+			ResponseBody: &body,
+		}).
+		Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+func (i *IdempotencyKeyRepository) Delete(ctx context.Context, tx *gorm.DB, request *dto.IdempotencyRequest) error {
+	err := tx.WithContext(ctx).
+		Where("scope = ? AND key = ?", request.Scope, request.Key).
+		Delete(&models.IdempotencyKey{}).
+		Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+func (i *IdempotencyKeyRepository) DeleteExpired(ctx context.Context, tx *gorm.DB, now time.Time) (int64, error) {
+	result := tx.WithContext(ctx).
+		Where("expired_at < ?", now).
+		Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return 0, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	errWrap "payment-service/common/error"
+	errConstant "payment-service/constants/error"
+	errLedger "payment-service/constants/error/ledger"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"time"
+)
+
+type ILedgerRepository interface {
+	FindOrCreateAccount(context.Context, *gorm.DB, *dto.LedgerAccountRequest) (*models.LedgerAccount, error)
+	FindAccountByUUID(context.Context, string) (*models.LedgerAccount, error)
+	FindEntryByReference(context.Context, *gorm.DB, string) (*models.JournalEntry, error)
+	CreateEntry(context.Context, *gorm.DB, *models.JournalEntry) (bool, error)
+	Balances(context.Context, *gorm.DB, *dto.LedgerBalanceFilter) ([]dto.LedgerAccountBalance, error)
+	FindPostings(context.Context, uint, time.Time, time.Time) ([]dto.LedgerStatementRow, error)
+	Lock(context.Context, *gorm.DB, string) error
+}
+
+func NewLedgerRepository(db *gorm.DB) ILedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+// FindOrCreateAccount opens an account the first time something is posted to
+// it, a concurrent insert of the same account is tolerated.
+func (l *LedgerRepository) FindOrCreateAccount(
+	ctx context.Context,
+	tx *gorm.DB,
+	request *dto.LedgerAccountRequest,
+) (*models.LedgerAccount, error) {
+	account := models.LedgerAccount{
+		UUID:       uuid.New(),
+		Code:       request.Code,
+		Currency:   request.Currency,
+		Name:       request.Name,
+		Type:       request.Type,
+		MerchantID: request.MerchantID,
+	}
+	err := tx.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "code"}, {Name: "currency"}},
+			DoNothing: true,
+		}).
+		Create(&account).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	if account.ID != 0 {
+		return &account, nil
+	}
+
+	err = tx.WithContext(ctx).
+		Where("code = ? AND currency = ?", request.Code, request.Currency).
+		First(&account).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &account, nil
+}
+
+func (l *LedgerRepository) FindAccountByUUID(ctx context.Context, uuid string) (*models.LedgerAccount, error) {
+	var account models.LedgerAccount
+	err := l.db.WithContext(ctx).
+		Where("uuid = ?", uuid).
+		First(&account).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(errLedger.ErrAccountNotFound)
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &account, nil
+}
+
+// FindEntryByReference returns nil when nothing has been posted under the
+// reference yet.
+func (l *LedgerRepository) FindEntryByReference(ctx context.Context, tx *gorm.DB, reference string) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	err := tx.WithContext(ctx).
+		Preload("Postings").
+		Where("reference = ?", reference).
+		First(&entry).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &entry, nil
+}
+
+// CreateEntry stores the entry with its postings and reports false when an
+// entry with the same reference already exists.
+func (l *LedgerRepository) CreateEntry(ctx context.Context, tx *gorm.DB, entry *models.JournalEntry) (bool, error) {
+	postings := entry.Postings
+	entry.Postings = nil
+	result := tx.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "reference"}},
+			DoNothing: true,
+		}).
+		Create(entry)
+	entry.Postings = postings
+	if result.Error != nil {
+		return false, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	for i := range postings {
+		postings[i].JournalEntryID = entry.ID
+	}
+	err := tx.WithContext(ctx).Create(&postings).Error
+	if err != nil {
+		return false, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return true, nil
+}
+
+func (l *LedgerRepository) Balances(
+	ctx context.Context,
+	tx *gorm.DB,
+	filter *dto.LedgerBalanceFilter,
+) ([]dto.LedgerAccountBalance, error) {
+	var balances []dto.LedgerAccountBalance
+	query := tx.WithContext(ctx).
+		Table("ledger_accounts AS a").
+		Select(`a.id AS account_id, a.uuid, a.code, a.name, a.type, a.merchant_id, a.currency,
+			COALESCE(SUM(p.debit), 0) AS debit, COALESCE(SUM(p.credit), 0) AS credit`)
+	if filter.PostedBefore != nil {
+		query = query.Joins(`LEFT JOIN ledger_postings AS p ON p.account_id = a.id
+			AND p.journal_entry_id IN (SELECT id FROM journal_entries WHERE posted_at < ?)`, *filter.PostedBefore)
+	} else {
+		query = query.Joins("LEFT JOIN ledger_postings AS p ON p.account_id = a.id")
+	}
+	if filter.AccountID != nil {
+		query = query.Where("a.id = ?", *filter.AccountID)
+	}
+	if filter.CodePrefix != nil {
+		query = query.Where("a.code LIKE ?", *filter.CodePrefix+"%")
+	}
+	if filter.MerchantID != nil {
+		query = query.Where("a.merchant_id = ?", *filter.MerchantID)
+	}
+	if filter.Currency != nil {
+		query = query.Where("a.currency = ?", *filter.Currency)
+	}
+	err := query.
+		Group("a.id").
+		Order("a.code asc, a.currency asc").
+		Scan(&balances).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return balances, nil
+}
+
+// FindPostings lists the postings of an account in entries posted from start
+// until before end, in the order they were posted.
+func (l *LedgerRepository) FindPostings(
+	ctx context.Context,
+	accountID uint,
+	start, end time.Time,
+) ([]dto.LedgerStatementRow, error) {
+	var rows []dto.LedgerStatementRow
+	err := l.db.WithContext(ctx).
+		Table("ledger_postings AS p").
+		Select("e.uuid AS entry_uuid, e.reference, e.kind, e.description, e.posted_at, p.debit, p.credit").
+		Joins("JOIN journal_entries AS e ON e.id = p.journal_entry_id").
+		Where("p.account_id = ? AND e.posted_at >= ? AND e.posted_at < ?", accountID, start, end).
+		Order("e.posted_at asc, p.id asc").
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return rows, nil
+}
+
+// Lock serialises work on the ledger that reads balances before posting, such
+// as a payout batch, until the transaction ends.
+func (l *LedgerRepository) Lock(ctx context.Context, tx *gorm.DB, key string) error {
+	err := tx.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
@@ -6,20 +6,37 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	errWrap "payment-service/common/error"
+	"payment-service/common/money"
 	"payment-service/constants"
 	errConstant "payment-service/constants/error"
 	errPayment "payment-service/constants/error/payment"
 	"payment-service/domain/dto"
 	"payment-service/domain/models"
+	"time"
 )
 
 type IPaymentRepository interface {
 	FindAllWithPagination(context.Context, *dto.PaymentRequestParam) ([]models.Payment, int64, error)
+	CountByFilter(context.Context, *dto.PaymentFilter) (int64, error)
+	StreamByFilter(context.Context, *dto.PaymentFilter, func(*models.Payment) error) error
 	FindByUUID(context.Context, string) (*models.Payment, error)
 	FindByOrderID(context.Context, string) (*models.Payment, error)
+	LockByGatewayOrderID(context.Context, *gorm.DB, string) (*models.Payment, error)
+	FindByID(context.Context, *gorm.DB, uint) (*models.Payment, error)
+	LockOrder(context.Context, *gorm.DB, string) error
+	FindForReconciliation(context.Context, *dto.ReconcileRequest) ([]models.Payment, error)
+	FindByGatewayOrderIDsOrTransactionIDs(context.Context, []string, []string) ([]models.Payment, error)
+	FindSettledBetween(context.Context, time.Time, time.Time) ([]models.Payment, error)
+	FindAuthorizedBefore(context.Context, time.Time) ([]models.Payment, error)
+	FindByPaymentLinkID(context.Context, uint) ([]models.Payment, error)
+	SummaryTotal(context.Context, *dto.PaymentSummaryParam) (*dto.PaymentSummaryTotal, error)
+	SummaryGroupBy(context.Context, *dto.PaymentSummaryParam, string) ([]dto.PaymentSummaryGroup, error)
+	SummaryByPeriod(context.Context, *dto.PaymentSummaryParam) ([]dto.PaymentSummaryPeriod, error)
 	Create(context.Context, *gorm.DB, *dto.PaymentRequest) (*models.Payment, error)
-	Update(context.Context, *gorm.DB, string, *dto.UpdatePaymentRequest) (*models.Payment, error)
+	Update(context.Context, *gorm.DB, uint, *dto.UpdatePaymentRequest) (*models.Payment, error)
+	UpdateCaptureAmount(context.Context, *gorm.DB, uint, *int64) error
 }
 
 func NewPaymentRepository(db *gorm.DB) IPaymentRepository {
@@ -38,13 +55,16 @@ func (p *PaymentRepository) FindAllWithPagination(ctx context.Context, param *dt
 	)
 	sort = "created_at desc"
 	if param.SortColumn != nil {
-		sort = fmt.Sprintf("%s %s", param.SortColumn, param.SortOrder)
+		sortOrder := "asc"
+		if param.SortOrder != nil {
+			sortOrder = *param.SortOrder
+		}
+		sort = fmt.Sprintf("%s %s", *param.SortColumn, sortOrder)
 	}
 
 	limit := param.Limit
 	offset := (param.Page - 1) * limit
-	err := p.db.
-		WithContext(ctx).
+	err := p.filter(p.db.WithContext(ctx), &param.PaymentFilter).
 		Limit(limit).
 		Offset(offset).
 		Order(sort).
@@ -53,9 +73,8 @@ func (p *PaymentRepository) FindAllWithPagination(ctx context.Context, param *dt
 	if err != nil {
 		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
 	}
-	err = p.db.
-		WithContext(ctx).
-		Model(&fields).
+	err = p.filter(p.db.WithContext(ctx), &param.PaymentFilter).
+		Model(&models.Payment{}).
 		Count(&total).
 		Error
 	if err != nil {
@@ -64,6 +83,65 @@ func (p *PaymentRepository) FindAllWithPagination(ctx context.Context, param *dt
 	return fields, total, nil
 }
 
+func (p *PaymentRepository) filter(query *gorm.DB, filter *dto.PaymentFilter) *gorm.DB {
+	if filter.Status != nil {
+		query = query.Where("status = ?", constants.PaymentStatusString(*filter.Status).GetStatusInt())
+	}
+	if filter.Bank != nil {
+		query = query.Where("bank = ?", *filter.Bank)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("created_at >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("created_at < ?", filter.EndDate.AddDate(0, 0, 1))
+	}
+	return query
+}
+
+func (p *PaymentRepository) CountByFilter(ctx context.Context, filter *dto.PaymentFilter) (int64, error) {
+	var total int64
+	err := p.filter(p.db.WithContext(ctx), filter).
+		Model(&models.Payment{}).
+		Count(&total).
+		Error
+	if err != nil {
+		return 0, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return total, nil
+}
+
+func (p *PaymentRepository) StreamByFilter(
+	ctx context.Context,
+	filter *dto.PaymentFilter,
+	handle func(*models.Payment) error,
+) error {
+	rows, err := p.filter(p.db.WithContext(ctx), filter).
+		Model(&models.Payment{}).
+		Order("created_at asc").
+		Rows()
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var payment models.Payment
+		err = p.db.ScanRows(rows, &payment)
+		if err != nil {
+			return errWrap.WrapError(errConstant.ErrSQLError)
+		}
+		err = handle(&payment)
+		if err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
+
 func (p *PaymentRepository) FindByUUID(ctx context.Context, uuid string) (*models.Payment, error) {
 	var payment models.Payment
 	err := p.db.WithContext(ctx).
@@ -83,6 +161,40 @@ func (p *PaymentRepository) FindByOrderID(ctx context.Context, orderId string) (
 	var payment models.Payment
 	err := p.db.WithContext(ctx).
 		Where("order_id = ?", orderId).
+		Order("attempt desc").
+		First(&payment).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(errPayment.ErrPaymentNotFound)
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &payment, nil
+}
+
+// LockByGatewayOrderID reads the payment inside tx and holds it until the
+// transaction ends, so notifications for the same order are applied one by one.
+func (p *PaymentRepository) LockByGatewayOrderID(ctx context.Context, tx *gorm.DB, gatewayOrderID string) (*models.Payment, error) {
+	var payment models.Payment
+	err := tx.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("gateway_order_id = ?", gatewayOrderID).
+		First(&payment).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(errPayment.ErrPaymentNotFound)
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &payment, nil
+}
+
+func (p *PaymentRepository) FindByID(ctx context.Context, tx *gorm.DB, id uint) (*models.Payment, error) {
+	var payment models.Payment
+	err := tx.WithContext(ctx).
+		Where("id = ?", id).
 		First(&payment).
 		Error
 	if err != nil {
@@ -94,17 +206,231 @@ func (p *PaymentRepository) FindByOrderID(ctx context.Context, orderId string) (
 	return &payment, nil
 }
 
+func (p *PaymentRepository) LockOrder(ctx context.Context, tx *gorm.DB, orderId string) error {
+	err := tx.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", orderId).Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+func (p *PaymentRepository) FindForReconciliation(ctx context.Context, request *dto.ReconcileRequest) ([]models.Payment, error) {
+	var payments []models.Payment
+	query := p.db.WithContext(ctx)
+	if len(request.OrderIDs) > 0 {
+		query = query.Where("order_id IN ?", request.OrderIDs)
+	}
+	if request.StartDate != nil {
+		query = query.Where("created_at >= ?", request.StartDate)
+	}
+	if request.EndDate != nil {
+		query = query.Where("created_at < ?", request.EndDate)
+	}
+	err := query.Order("created_at asc").Find(&payments).Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return payments, nil
+}
+
+func (p *PaymentRepository) FindByGatewayOrderIDsOrTransactionIDs(
+	ctx context.Context,
+	orderIDs []string,
+	transactionIDs []string,
+) ([]models.Payment, error) {
+	var payments []models.Payment
+	if len(orderIDs) == 0 && len(transactionIDs) == 0 {
+		return payments, nil
+	}
+	query := p.db.WithContext(ctx)
+	switch {
+	case len(orderIDs) > 0 && len(transactionIDs) > 0:
+		query = query.Where("gateway_order_id IN ? OR transaction_id IN ?", orderIDs, transactionIDs)
+	case len(orderIDs) > 0:
+		query = query.Where("gateway_order_id IN ?", orderIDs)
+	default:
+		query = query.Where("transaction_id IN ?", transactionIDs)
+	}
+	err := query.Find(&payments).Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return payments, nil
+}
+
+func (p *PaymentRepository) FindSettledBetween(ctx context.Context, startDate, endDate time.Time) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := p.db.WithContext(ctx).
+		Where("status = ?", constants.Settlement).
+		Where("paid_at >= ? AND paid_at < ?", startDate, endDate).
+		Find(&payments).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return payments, nil
+}
+
+func (p *PaymentRepository) FindAuthorizedBefore(ctx context.Context, authorizedAt time.Time) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := p.db.WithContext(ctx).
+		Where("status = ?", constants.Authorized).
+		Where("authorized_at < ?", authorizedAt).
+		Order("authorized_at asc").
+		Find(&payments).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return payments, nil
+}
+
+func (p *PaymentRepository) FindByPaymentLinkID(ctx context.Context, paymentLinkID uint) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := p.db.WithContext(ctx).
+		Where("payment_link_id = ?", paymentLinkID).
+		Order("created_at desc").
+		Find(&payments).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return payments, nil
+}
+
+func (p *PaymentRepository) summaryQuery(ctx context.Context, param *dto.PaymentSummaryParam) *gorm.DB {
+	return p.db.WithContext(ctx).
+		Model(&models.Payment{}).
+		Where("created_at >= ? AND created_at < ?", param.StartDate, param.EndDate)
+}
+
+// SummaryTotal and the other summaries add payments up in the reporting
+// currency at the rate snapshotted on each, payments created before rates
+// were snapshotted are in rupiah.
+func (p *PaymentRepository) SummaryTotal(ctx context.Context, param *dto.PaymentSummaryParam) (*dto.PaymentSummaryTotal, error) {
+	var total dto.PaymentSummaryTotal
+	err := p.summaryQuery(ctx, param).
+		Select(`COUNT(*) AS total_count,
+			COALESCE(SUM(COALESCE(reporting_amount, amount)), 0) AS total_amount,
+			COUNT(*) FILTER (WHERE status = ?) AS settled_count,
+			COALESCE(SUM(COALESCE(reporting_amount, amount)) FILTER (WHERE status = ?), 0) AS settled_amount,
+			COALESCE(SUM(fee * COALESCE(reporting_amount, amount)::numeric / amount) FILTER (WHERE status = ?), 0) AS settled_fee,
+			COALESCE(SUM(COALESCE(net_amount, amount) * COALESCE(reporting_amount, amount)::numeric / amount) FILTER (WHERE status = ?), 0) AS settled_net_amount,
+			COUNT(*) FILTER (WHERE status = ?) AS expired_count,
+			AVG(EXTRACT(EPOCH FROM (paid_at - created_at))) FILTER (WHERE paid_at IS NOT NULL) AS average_time_to_pay_second`,
+			constants.Settlement, constants.Settlement, constants.Settlement, constants.Settlement, constants.Expire).
+		Scan(&total).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &total, nil
+}
+
+func (p *PaymentRepository) SummaryGroupBy(
+	ctx context.Context,
+	param *dto.PaymentSummaryParam,
+	column string,
+) ([]dto.PaymentSummaryGroup, error) {
+	var groups []dto.PaymentSummaryGroup
+	err := p.summaryQuery(ctx, param).
+		Select(fmt.Sprintf("CAST(%s AS TEXT) AS key, COUNT(*) AS count, COALESCE(SUM(COALESCE(reporting_amount, amount)), 0) AS amount", column)).
+		Group(column).
+		Order("count desc").
+		Scan(&groups).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return groups, nil
+}
+
+func (p *PaymentRepository) SummaryByPeriod(ctx context.Context, param *dto.PaymentSummaryParam) ([]dto.PaymentSummaryPeriod, error) {
+	var periods []dto.PaymentSummaryPeriod
+	err := p.summaryQuery(ctx, param).
+		Select(`DATE_TRUNC(?, created_at) AS period,
+			COUNT(*) AS count,
+			COALESCE(SUM(COALESCE(reporting_amount, amount)), 0) AS amount,
+			COUNT(*) FILTER (WHERE status = ?) AS settled_count,
+			COALESCE(SUM(COALESCE(reporting_amount, amount)) FILTER (WHERE status = ?), 0) AS settled_amount,
+			COALESCE(SUM(fee * COALESCE(reporting_amount, amount)::numeric / amount) FILTER (WHERE status = ?), 0) AS settled_fee,
+			COALESCE(SUM(COALESCE(net_amount, amount) * COALESCE(reporting_amount, amount)::numeric / amount) FILTER (WHERE status = ?), 0) AS settled_net_amount`,
+			param.Period, constants.Settlement, constants.Settlement, constants.Settlement, constants.Settlement).
+		Group("period").
+		Order("period asc").
+		Scan(&periods).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return periods, nil
+}
+
 func (p *PaymentRepository) Create(ctx context.Context, tx *gorm.DB, request *dto.PaymentRequest) (*models.Payment, error) {
 	status := constants.Initial
 	orderId := uuid.MustParse(request.OrderID)
+	currency := request.Currency
+	if currency == "" {
+		currency = constants.DefaultCurrency
+	}
+	amount := money.FromMajor(request.Amount, currency)
 	payment := models.Payment{
-		UUID:        uuid.New(),
-		OrderID:     orderId,
-		Amount:      request.Amount,
-		PaymentLink: request.PaymentLink,
-		ExpiredAt:   &request.ExpiredAt,
-		Description: request.Description,
-		Status:      &status,
+		UUID:           uuid.New(),
+		OrderID:        orderId,
+		Provider:       request.Provider,
+		GatewayOrderID: request.GatewayOrderID,
+		Attempt:        request.Attempt,
+		SubscriptionID: request.SubscriptionID,
+		PaymentLinkID:  request.PaymentLinkID,
+		CustomerID:     request.CustomerID,
+		RoutingRule:    request.RoutingRule,
+		Amount:         amount.Amount,
+		Currency:       amount.Currency,
+		Discount:       request.Discount,
+		VoucherCode:    request.VoucherCode,
+		AllowPartial:   request.AllowPartial,
+		Authorize:      request.Authorize,
+		PaymentLink:    request.PaymentLink,
+		ExpiredAt:      &request.ExpiredAt,
+		Description:    request.Description,
+		Status:         &status,
+	}
+	if request.Status != nil {
+		payment.Status = request.Status
+	}
+	if request.Instruction != nil {
+		payment.PaymentMethod = &request.PaymentMethod
+		payment.VANumber = request.Instruction.VANumber
+		payment.Bank = request.Instruction.Bank
+		payment.QRString = request.Instruction.QRString
+		payment.QRImageURL = request.Instruction.QRImageURL
+		payment.Deeplink = request.Instruction.Deeplink
+	}
+	if request.RoutingReason != "" {
+		payment.RoutingReason = &request.RoutingReason
+	}
+	if request.GatewayReference != "" {
+		payment.GatewayReference = &request.GatewayReference
+	}
+	if request.CustomerDetail != nil {
+		payment.CustomerName = &request.CustomerDetail.Name
+		if request.CustomerDetail.NPWP != "" {
+			payment.CustomerNPWP = &request.CustomerDetail.NPWP
+		}
+	}
+	if request.FX != nil {
+		payment.FXRate = &request.FX.Rate
+		payment.FXSource = &request.FX.Source
+		payment.ReportingAmount = &request.FX.ReportingAmount
+	}
+	if request.Tax != nil {
+		payment.TaxRate = &request.Tax.Rate
+		payment.TaxInclusive = request.Tax.Inclusive
+		payment.TaxBase = request.Tax.Base
+		payment.TaxAmount = request.Tax.Amount
+		if request.Tax.MerchantNPWP != "" {
+			payment.MerchantNPWP = &request.Tax.MerchantNPWP
+		}
 	}
 	err := tx.WithContext(ctx).Create(&payment).Error
 	if err != nil {
@@ -113,7 +439,7 @@ func (p *PaymentRepository) Create(ctx context.Context, tx *gorm.DB, request *dt
 	return &payment, nil
 }
 
-func (p *PaymentRepository) Update(ctx context.Context, tx *gorm.DB, orderId string, request *dto.UpdatePaymentRequest) (*models.Payment, error) {
+func (p *PaymentRepository) Update(ctx context.Context, tx *gorm.DB, id uint, request *dto.UpdatePaymentRequest) (*models.Payment, error) {
 	payment := models.Payment{
 		Status:        request.Status,
 		TransactionID: request.TransactionId,
@@ -121,11 +447,52 @@ func (p *PaymentRepository) Update(ctx context.Context, tx *gorm.DB, orderId str
 		PaidAt:        request.PaidAt,
 		VANumber:      request.VANumber,
 		Bank:          request.Bank,
-		Acquirer:      &request.Acquirer,
+		ReviewReason:  request.ReviewReason,
+		CaptureAmount: request.CaptureAmount,
+		AuthorizedAt:  request.AuthorizedAt,
+		Fee:           request.Fee,
+		NetAmount:     request.NetAmount,
+		FeeSource:     request.FeeSource,
+	}
+	if request.PaidAmount != nil {
+		payment.PaidAmount = *request.PaidAmount
+	}
+	if request.RefundAmount != nil {
+		payment.RefundAmount = *request.RefundAmount
+	}
+	if request.Acquirer != "" {
+		payment.Acquirer = &request.Acquirer
+	}
+	if detail := request.MethodDetail; detail != nil {
+		payment.PaymentType = p.optional(detail.Type)
+		payment.PaymentChannel = p.optional(detail.Channel)
+		payment.PaymentReference = p.optional(detail.Reference)
+		payment.ApprovalCode = p.optional(detail.ApprovalCode)
 	}
-	err := tx.WithContext(ctx).Where("order_id = ?", orderId).Updates(&payment).Error
+	err := tx.WithContext(ctx).Where("id = ?", id).Updates(&payment).Error
 	if err != nil {
 		return nil, errWrap.WrapError(errConstant.ErrSQLError)
 	}
 	return &payment, nil
 }
+
+func (p *PaymentRepository) optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// UpdateCaptureAmount also writes a nil amount, which Update would skip, so a
+// capture the gateway refused can be taken back.
+func (p *PaymentRepository) UpdateCaptureAmount(ctx context.Context, tx *gorm.DB, id uint, amount *int64) error {
+	err := tx.WithContext(ctx).
+		Model(&models.Payment{}).
+		Where("id = ?", id).
+		Update("capture_amount", amount).
+		Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	errWrap "payment-service/common/error"
+	errConstant "payment-service/constants/error"
+	errPayment "payment-service/constants/error/payment"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+)
+
+type IPaymentExportRepository interface {
+	FindByUUID(context.Context, string) (*models.PaymentExport, error)
+	Create(context.Context, *gorm.DB, *dto.PaymentExportParam) (*models.PaymentExport, error)
+	Update(context.Context, *gorm.DB, uint, *dto.UpdatePaymentExportRequest) error
+}
+
+func NewPaymentExportRepository(db *gorm.DB) IPaymentExportRepository {
+	return &PaymentExportRepository{db: db}
+}
+
+type PaymentExportRepository struct {
+	db *gorm.DB
+}
+
+func (p *PaymentExportRepository) FindByUUID(ctx context.Context, uuid string) (*models.PaymentExport, error) {
+	var paymentExport models.PaymentExport
+	err := p.db.WithContext(ctx).
+		Where("uuid = ?", uuid).
+		First(&paymentExport).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(errPayment.ErrExportNotFound)
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &paymentExport, nil
+}
+
+func (p *PaymentExportRepository) Create(
+	ctx context.Context,
+	tx *gorm.DB,
+	param *dto.PaymentExportParam,
+) (*models.PaymentExport, error) {
+	filter, _ := json.Marshal(param.PaymentFilter)
+	filterString := string(filter)
+	paymentExport := models.PaymentExport{
+		UUID:   uuid.New(),
+		Format: param.Format,
+		Status: string(dto.PaymentExportProcessing),
+		Filter: &filterString,
+	}
+	err := tx.WithContext(ctx).Create(&paymentExport).Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &paymentExport, nil
+}
+
+func (p *PaymentExportRepository) Update(
+	ctx context.Context,
+	tx *gorm.DB,
+	id uint,
+	request *dto.UpdatePaymentExportRequest,
+) error {
+	paymentExport := models.PaymentExport{
+		Status:       string(request.Status),
+		RowCount:     request.RowCount,
+		DownloadLink: request.DownloadLink,
+		Error:        request.Error,
+		CompletedAt:  request.CompletedAt,
+	}
+	err := tx.WithContext(ctx).Where("id = ?", id).Updates(&paymentExport).Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	errWrap "payment-service/common/error"
+	"payment-service/common/money"
+	"payment-service/constants"
+	errConstant "payment-service/constants/error"
+	errPaymentLink "payment-service/constants/error/payment_link"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"strings"
+)
+
+type IPaymentLinkRepository interface {
+	FindAllWithPagination(context.Context, *dto.PaymentLinkRequestParam) ([]models.PaymentLink, int64, error)
+	FindByUUID(context.Context, string) (*models.PaymentLink, error)
+	FindByCode(context.Context, string) (*models.PaymentLink, error)
+	Lock(context.Context, *gorm.DB, uint) (*models.PaymentLink, error)
+	Create(context.Context, *dto.PaymentLinkRequest) (*models.PaymentLink, error)
+	Deactivate(context.Context, uint) error
+	Stats(context.Context, *gorm.DB, uint) (*dto.PaymentLinkStats, error)
+}
+
+func NewPaymentLinkRepository(db *gorm.DB) IPaymentLinkRepository {
+	return &PaymentLinkRepository{db: db}
+}
+
+type PaymentLinkRepository struct {
+	db *gorm.DB
+}
+
+func (p *PaymentLinkRepository) FindAllWithPagination(
+	ctx context.Context,
+	param *dto.PaymentLinkRequestParam,
+) ([]models.PaymentLink, int64, error) {
+	var (
+		links []models.PaymentLink
+		total int64
+	)
+	limit := param.Limit
+	offset := (param.Page - 1) * limit
+	err := p.filter(p.db.WithContext(ctx), param).
+		Limit(limit).
+		Offset(offset).
+		Order("created_at desc").
+		Find(&links).
+		Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	err = p.filter(p.db.WithContext(ctx), param).
+		Model(&models.PaymentLink{}).
+		Count(&total).
+		Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return links, total, nil
+}
+
+func (p *PaymentLinkRepository) filter(query *gorm.DB, param *dto.PaymentLinkRequestParam) *gorm.DB {
+	if param.IsActive != nil {
+		query = query.Where("is_active = ?", *param.IsActive)
+	}
+	return query
+}
+
+func (p *PaymentLinkRepository) FindByUUID(ctx context.Context, uuid string) (*models.PaymentLink, error) {
+	return p.find(p.db.WithContext(ctx).Where("uuid = ?", uuid))
+}
+
+func (p *PaymentLinkRepository) FindByCode(ctx context.Context, code string) (*models.PaymentLink, error) {
+	return p.find(p.db.WithContext(ctx).Where("code = ?", code))
+}
+
+// Lock reads the link again and holds it until the transaction ends, so
+// concurrent payers cannot use it more often than it allows.
+func (p *PaymentLinkRepository) Lock(ctx context.Context, tx *gorm.DB, id uint) (*models.PaymentLink, error) {
+	return p.find(tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id))
+}
+
+func (p *PaymentLinkRepository) find(query *gorm.DB) (*models.PaymentLink, error) {
+	var link models.PaymentLink
+	err := query.First(&link).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(errPaymentLink.ErrPaymentLinkNotFound)
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &link, nil
+}
+
+func (p *PaymentLinkRepository) Create(ctx context.Context, request *dto.PaymentLinkRequest) (*models.PaymentLink, error) {
+	currency := request.Currency
+	if currency == "" {
+		currency = constants.DefaultCurrency
+	}
+	link := models.PaymentLink{
+		UUID:        uuid.New(),
+		Code:        request.Code,
+		Title:       request.Title,
+		Description: request.Description,
+		Amount:      p.amount(request.Amount, currency),
+		MinAmount:   p.amount(request.MinAmount, currency),
+		MaxAmount:   p.amount(request.MaxAmount, currency),
+		Currency:    strings.ToUpper(currency),
+		MaxUses:     request.MaxUses,
+		ExpiredAt:   request.ExpiredAt,
+		IsActive:    true,
+		CreatedBy:   request.CreatedBy,
+	}
+	err := p.db.WithContext(ctx).Create(&link).Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &link, nil
+}
+
+func (p *PaymentLinkRepository) amount(value *float64, currency string) *int64 {
+	if value == nil {
+		return nil
+	}
+	amount := money.FromMajor(*value, currency).Amount
+	return &amount
+}
+
+func (p *PaymentLinkRepository) Deactivate(ctx context.Context, id uint) error {
+	err := p.db.WithContext(ctx).
+		Model(&models.PaymentLink{}).
+		Where("id = ?", id).
+		Update("is_active", false).
+		Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+func (p *PaymentLinkRepository) Stats(ctx context.Context, tx *gorm.DB, id uint) (*dto.PaymentLinkStats, error) {
+	var stats dto.PaymentLinkStats
+	paid := []constants.PaymentStatus{constants.Settlement, constants.PartiallyRefunded, constants.Refunded}
+	err := tx.WithContext(ctx).
+		Model(&models.Payment{}).
+		Select(`COUNT(*) FILTER (WHERE status NOT IN ?) AS used,
+			COUNT(*) FILTER (WHERE status IN ?) AS paid,
+			COALESCE(SUM(amount) FILTER (WHERE status IN ?), 0) AS paid_amount`,
+			[]constants.PaymentStatus{constants.Expire, constants.Cancel}, paid, paid).
+		Where("payment_link_id = ?", id).
+		Scan(&stats).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &stats, nil
+}
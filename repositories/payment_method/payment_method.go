@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	errWrap "payment-service/common/error"
+	errConstant "payment-service/constants/error"
+	errPayment "payment-service/constants/error/payment"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"time"
+)
+
+type IPaymentMethodRepository interface {
+	FindByCustomerID(context.Context, uuid.UUID) ([]models.PaymentMethod, error)
+	FindByUUID(context.Context, string) (*models.PaymentMethod, error)
+	Save(context.Context, *gorm.DB, *dto.PaymentMethodRequest) error
+	MarkUsed(context.Context, uint, time.Time) error
+	Delete(context.Context, uint) error
+}
+
+func NewPaymentMethodRepository(db *gorm.DB) IPaymentMethodRepository {
+	return &PaymentMethodRepository{db: db}
+}
+
+type PaymentMethodRepository struct {
+	db *gorm.DB
+}
+
+func (p *PaymentMethodRepository) FindByCustomerID(ctx context.Context, customerID uuid.UUID) ([]models.PaymentMethod, error) {
+	var methods []models.PaymentMethod
+	err := p.db.WithContext(ctx).
+		Where("customer_id = ?", customerID).
+		Order("last_used_at desc nulls last, created_at desc").
+		Find(&methods).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return methods, nil
+}
+
+func (p *PaymentMethodRepository) FindByUUID(ctx context.Context, uuid string) (*models.PaymentMethod, error) {
+	var method models.PaymentMethod
+	err := p.db.WithContext(ctx).
+		Where("uuid = ?", uuid).
+		First(&method).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(errPayment.ErrSavedMethodNotFound)
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &method, nil
+}
+
+// Save stores a saved card of the customer, a token seen before only has its
+// card details and expiry refreshed.
+func (p *PaymentMethodRepository) Save(ctx context.Context, tx *gorm.DB, request *dto.PaymentMethodRequest) error {
+	card := request.SavedCard
+	method := models.PaymentMethod{
+		UUID:       uuid.New(),
+		CustomerID: request.CustomerID,
+		Provider:   request.Provider,
+		Type:       request.Type,
+		Token:      card.Token,
+		MaskedCard: card.MaskedCard,
+		Brand:      card.Brand,
+		ExpiredAt:  card.ExpiredAt,
+	}
+	if card.Bank != "" {
+		method.Bank = &card.Bank
+	}
+	if card.CardType != "" {
+		method.CardType = &card.CardType
+	}
+
+	err := tx.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "customer_id"}, {Name: "token"}},
+			DoUpdates: clause.AssignmentColumns([]string{"masked_card", "brand", "bank", "card_type", "expired_at", "updated_at"}),
+		}).
+		Create(&method).
+		Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+func (p *PaymentMethodRepository) MarkUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	err := p.db.WithContext(ctx).
+		Model(&models.PaymentMethod{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).
+		Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+func (p *PaymentMethodRepository) Delete(ctx context.Context, id uint) error {
+	err := p.db.WithContext(ctx).
+		Where("id = ?", id).
+		Delete(&models.PaymentMethod{}).
+		Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
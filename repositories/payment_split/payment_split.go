@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	errWrap "payment-service/common/error"
+	errConstant "payment-service/constants/error"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+)
+
+type IPaymentSplitRepository interface {
+	Create(context.Context, *gorm.DB, []dto.PaymentSplitRequest) error
+	FindByPaymentID(context.Context, *gorm.DB, uint) ([]models.PaymentSplit, error)
+}
+
+func NewPaymentSplitRepository(db *gorm.DB) IPaymentSplitRepository {
+	return &PaymentSplitRepository{db: db}
+}
+
+type PaymentSplitRepository struct {
+	db *gorm.DB
+}
+
+func (p *PaymentSplitRepository) Create(ctx context.Context, tx *gorm.DB, requests []dto.PaymentSplitRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+	splits := make([]models.PaymentSplit, 0, len(requests))
+	for _, request := range requests {
+		splits = append(splits, models.PaymentSplit{
+			UUID:       uuid.New(),
+			PaymentID:  request.PaymentID,
+			MerchantID: request.MerchantID,
+			Amount:     request.Amount.Amount,
+			Percentage: request.Percentage,
+			Fee:        request.Fee.Amount,
+			Currency:   request.Amount.Currency,
+		})
+	}
+	err := tx.WithContext(ctx).Create(&splits).Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+func (p *PaymentSplitRepository) FindByPaymentID(ctx context.Context, tx *gorm.DB, paymentID uint) ([]models.PaymentSplit, error) {
+	var splits []models.PaymentSplit
+	err := tx.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("id asc").
+		Find(&splits).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return splits, nil
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	errWrap "payment-service/common/error"
+	errConstant "payment-service/constants/error"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+)
+
+type IPaymentTransactionRepository interface {
+	Create(context.Context, *gorm.DB, *dto.PaymentTransactionRequest) error
+	FindByPaymentID(context.Context, uint) ([]models.PaymentTransaction, error)
+	SumByPaymentID(context.Context, *gorm.DB, uint) (int64, error)
+}
+
+func NewPaymentTransactionRepository(db *gorm.DB) IPaymentTransactionRepository {
+	return &PaymentTransactionRepository{db: db}
+}
+
+type PaymentTransactionRepository struct {
+	db *gorm.DB
+}
+
+// Create ignores a transaction already recorded under the same reference, so
+// a notification delivered twice does not count a transfer twice.
+func (p *PaymentTransactionRepository) Create(ctx context.Context, tx *gorm.DB, request *dto.PaymentTransactionRequest) error {
+	transaction := models.PaymentTransaction{
+		UUID:      uuid.New(),
+		PaymentID: request.PaymentID,
+		Reference: request.Reference,
+		Amount:    request.Amount.Amount,
+		Currency:  request.Amount.Currency,
+		PaidAt:    request.PaidAt,
+	}
+	if request.TransactionID != "" {
+		transaction.TransactionID = &request.TransactionID
+	}
+
+	err := tx.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "payment_id"}, {Name: "reference"}},
+			DoNothing: true,
+		}).
+		Create(&transaction).
+		Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+func (p *PaymentTransactionRepository) FindByPaymentID(ctx context.Context, paymentID uint) ([]models.PaymentTransaction, error) {
+	var transactions []models.PaymentTransaction
+	err := p.db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("paid_at asc, id asc").
+		Find(&transactions).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return transactions, nil
+}
+
+func (p *PaymentTransactionRepository) SumByPaymentID(ctx context.Context, tx *gorm.DB, paymentID uint) (int64, error) {
+	var total int64
+	err := tx.WithContext(ctx).
+		Model(&models.PaymentTransaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("payment_id = ?", paymentID).
+		Scan(&total).
+		Error
+	if err != nil {
+		return 0, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return total, nil
+}
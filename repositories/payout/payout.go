@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	errWrap "payment-service/common/error"
+	"payment-service/constants"
+	errConstant "payment-service/constants/error"
+	errLedger "payment-service/constants/error/ledger"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+)
+
+type IPayoutRepository interface {
+	FindBatchByUUID(context.Context, string) (*models.PayoutBatch, error)
+	CreateBatch(context.Context, *gorm.DB, []dto.PayoutRequest) (*models.PayoutBatch, error)
+	UpdateBatch(context.Context, uint, *dto.UpdatePayoutBatchRequest) error
+}
+
+func NewPayoutRepository(db *gorm.DB) IPayoutRepository {
+	return &PayoutRepository{db: db}
+}
+
+type PayoutRepository struct {
+	db *gorm.DB
+}
+
+func (p *PayoutRepository) FindBatchByUUID(ctx context.Context, uuid string) (*models.PayoutBatch, error) {
+	var batch models.PayoutBatch
+	err := p.db.WithContext(ctx).
+		Preload("Payouts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("merchant_id asc, currency asc")
+		}).
+		Where("uuid = ?", uuid).
+		First(&batch).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(errLedger.ErrPayoutBatchNotFound)
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &batch, nil
+}
+
+func (p *PayoutRepository) CreateBatch(ctx context.Context, tx *gorm.DB, requests []dto.PayoutRequest) (*models.PayoutBatch, error) {
+	batch := models.PayoutBatch{
+		UUID:        uuid.New(),
+		Status:      constants.PayoutBatchCreated,
+		PayoutCount: len(requests),
+	}
+	for _, request := range requests {
+		batch.Payouts = append(batch.Payouts, models.Payout{
+			UUID:       uuid.New(),
+			MerchantID: request.MerchantID,
+			Amount:     request.Amount.Amount,
+			Currency:   request.Amount.Currency,
+		})
+	}
+	err := tx.WithContext(ctx).Create(&batch).Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &batch, nil
+}
+
+func (p *PayoutRepository) UpdateBatch(ctx context.Context, id uint, request *dto.UpdatePayoutBatchRequest) error {
+	err := p.db.WithContext(ctx).
+		Model(&models.PayoutBatch{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":   request.Status,
+			"file_url": request.FileURL,
+			"error":    request.Error,
+		}).
+		Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
@@ -2,13 +2,39 @@ package repositories
 
 import (
 	"gorm.io/gorm"
+	repositories6 "payment-service/repositories/idempotency_key"
+	repositories12 "payment-service/repositories/ledger"
 	repositories "payment-service/repositories/payment"
+	repositories5 "payment-service/repositories/payment_export"
 	repositories2 "payment-service/repositories/payment_history"
+	repositories15 "payment-service/repositories/payment_link"
+	repositories10 "payment-service/repositories/payment_method"
+	repositories11 "payment-service/repositories/payment_split"
+	repositories7 "payment-service/repositories/payment_transaction"
+	repositories13 "payment-service/repositories/payout"
+	repositories4 "payment-service/repositories/settlement_reconciliation"
+	repositories3 "payment-service/repositories/status_reconciliation"
+	repositories9 "payment-service/repositories/subscription"
+	repositories8 "payment-service/repositories/subscription_plan"
+	repositories14 "payment-service/repositories/voucher"
 )
 
 type IRepositoryRegistry interface {
 	GetPayment() repositories.IPaymentRepository
 	GetPaymentHistory() repositories2.IPaymentHistoryRepository
+	GetStatusReconciliation() repositories3.IStatusReconciliationRepository
+	GetSettlementReconciliation() repositories4.ISettlementReconciliationRepository
+	GetPaymentExport() repositories5.IPaymentExportRepository
+	GetIdempotencyKey() repositories6.IIdempotencyKeyRepository
+	GetPaymentTransaction() repositories7.IPaymentTransactionRepository
+	GetSubscriptionPlan() repositories8.ISubscriptionPlanRepository
+	GetSubscription() repositories9.ISubscriptionRepository
+	GetPaymentMethod() repositories10.IPaymentMethodRepository
+	GetPaymentSplit() repositories11.IPaymentSplitRepository
+	GetLedger() repositories12.ILedgerRepository
+	GetPayout() repositories13.IPayoutRepository
+	GetVoucher() repositories14.IVoucherRepository
+	GetPaymentLink() repositories15.IPaymentLinkRepository
 	GetTx() *gorm.DB
 }
 
@@ -31,3 +57,55 @@ func (r *Registry) GetPayment() repositories.IPaymentRepository {
 func (r *Registry) GetPaymentHistory() repositories2.IPaymentHistoryRepository {
 	return repositories2.NewPaymentHistoryRepository(r.db)
 }
+
+func (r *Registry) GetStatusReconciliation() repositories3.IStatusReconciliationRepository {
+	return repositories3.NewStatusReconciliationRepository(r.db)
+}
+
+func (r *Registry) GetSettlementReconciliation() repositories4.ISettlementReconciliationRepository {
+	return repositories4.NewSettlementReconciliationRepository(r.db)
+}
+
+func (r *Registry) GetPaymentExport() repositories5.IPaymentExportRepository {
+	return repositories5.NewPaymentExportRepository(r.db)
+}
+
+func (r *Registry) GetIdempotencyKey() repositories6.IIdempotencyKeyRepository {
+	return repositories6.NewIdempotencyKeyRepository(r.db)
+}
+
+func (r *Registry) GetPaymentTransaction() repositories7.IPaymentTransactionRepository {
+	return repositories7.NewPaymentTransactionRepository(r.db)
+}
+
+func (r *Registry) GetSubscriptionPlan() repositories8.ISubscriptionPlanRepository {
+	return repositories8.NewSubscriptionPlanRepository(r.db)
+}
+
+func (r *Registry) GetSubscription() repositories9.ISubscriptionRepository {
+	return repositories9.NewSubscriptionRepository(r.db)
+}
+
+func (r *Registry) GetPaymentMethod() repositories10.IPaymentMethodRepository {
+	return repositories10.NewPaymentMethodRepository(r.db)
+}
+
+func (r *Registry) GetPaymentSplit() repositories11.IPaymentSplitRepository {
+	return repositories11.NewPaymentSplitRepository(r.db)
+}
+
+func (r *Registry) GetLedger() repositories12.ILedgerRepository {
+	return repositories12.NewLedgerRepository(r.db)
+}
+
+func (r *Registry) GetPayout() repositories13.IPayoutRepository {
+	return repositories13.NewPayoutRepository(r.db)
+}
+
+func (r *Registry) GetVoucher() repositories14.IVoucherRepository {
+	return repositories14.NewVoucherRepository(r.db)
+}
+
+func (r *Registry) GetPaymentLink() repositories15.IPaymentLinkRepository {
+	return repositories15.NewPaymentLinkRepository(r.db)
+}
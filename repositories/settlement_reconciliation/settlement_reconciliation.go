@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	errWrap "payment-service/common/error"
+	"payment-service/common/money"
+	"payment-service/constants"
+	errConstant "payment-service/constants/error"
+	errSettlement "payment-service/constants/error/settlement"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+)
+
+type ISettlementReconciliationRepository interface {
+	FindAllWithPagination(context.Context, *dto.SettlementReconciliationRequestParam) ([]models.SettlementReconciliation, int64, error)
+	FindByUUID(context.Context, string) (*models.SettlementReconciliation, error)
+	Create(context.Context, *gorm.DB, *dto.SettlementReconciliationRequest) (*models.SettlementReconciliation, error)
+}
+
+func NewSettlementReconciliationRepository(db *gorm.DB) ISettlementReconciliationRepository {
+	return &SettlementReconciliationRepository{db: db}
+}
+
+type SettlementReconciliationRepository struct {
+	db *gorm.DB
+}
+
+func (s *SettlementReconciliationRepository) FindAllWithPagination(
+	ctx context.Context,
+	param *dto.SettlementReconciliationRequestParam,
+) ([]models.SettlementReconciliation, int64, error) {
+	var (
+		reconciliations []models.SettlementReconciliation
+		total           int64
+	)
+	limit := param.Limit
+	offset := (param.Page - 1) * limit
+	err := s.db.
+		WithContext(ctx).
+		Limit(limit).
+		Offset(offset).
+		Order("created_at desc").
+		Find(&reconciliations).
+		Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	err = s.db.
+		WithContext(ctx).
+		Model(&models.SettlementReconciliation{}).
+		Count(&total).
+		Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return reconciliations, total, nil
+}
+
+func (s *SettlementReconciliationRepository) FindByUUID(ctx context.Context, uuid string) (*models.SettlementReconciliation, error) {
+	var reconciliation models.SettlementReconciliation
+	err := s.db.WithContext(ctx).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id asc")
+		}).
+		Where("uuid = ?", uuid).
+		First(&reconciliation).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(errSettlement.ErrSettlementNotFound)
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &reconciliation, nil
+}
+
+func (s *SettlementReconciliationRepository) Create(
+	ctx context.Context,
+	tx *gorm.DB,
+	request *dto.SettlementReconciliationRequest,
+) (*models.SettlementReconciliation, error) {
+	lines := make([]models.SettlementReconciliationLine, 0, len(request.Lines))
+	for _, line := range request.Lines {
+		lines = append(lines, models.SettlementReconciliationLine{
+			RowNumber:      line.RowNumber,
+			PaymentID:      line.PaymentID,
+			OrderID:        line.OrderID,
+			TransactionID:  line.TransactionID,
+			PaymentType:    line.PaymentType,
+			ReportAmount:   s.minor(line.ReportAmount),
+			ReportFee:      s.minor(line.ReportFee),
+			PaymentAmount:  s.minor(line.PaymentAmount),
+			Currency:       s.currency(&line),
+			SettlementTime: line.SettlementTime,
+			Result:         string(line.Result),
+			Note:           line.Note,
+		})
+	}
+
+	reconciliation := models.SettlementReconciliation{
+		UUID:                request.UUID,
+		FileName:            request.FileName,
+		Source:              request.Source,
+		TotalRows:           request.TotalRows,
+		TotalMatched:        request.TotalMatched,
+		TotalAmountMismatch: request.TotalAmountMismatch,
+		TotalStatusMismatch: request.TotalStatusMismatch,
+		TotalMissing:        request.TotalMissing,
+		TotalDuplicated:     request.TotalDuplicated,
+		Lines:               lines,
+	}
+	err := tx.WithContext(ctx).Create(&reconciliation).Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &reconciliation, nil
+}
+
+func (s *SettlementReconciliationRepository) minor(amount *money.Money) *int64 {
+	if amount == nil {
+		return nil
+	}
+	return &amount.Amount
+}
+
+func (s *SettlementReconciliationRepository) currency(line *dto.SettlementLineRequest) string {
+	for _, amount := range []*money.Money{line.ReportAmount, line.PaymentAmount} {
+		if amount != nil && amount.Currency != "" {
+			return amount.Currency
+		}
+	}
+	return constants.DefaultCurrency
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	errWrap "payment-service/common/error"
+	errConstant "payment-service/constants/error"
+	errReconciliation "payment-service/constants/error/reconciliation"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"strings"
+)
+
+type IStatusReconciliationRepository interface {
+	Create(context.Context, *gorm.DB, *dto.ReconcileReport) (*models.StatusReconciliation, error)
+	RunExclusive(context.Context, string, func() error) error
+}
+
+func NewStatusReconciliationRepository(db *gorm.DB) IStatusReconciliationRepository {
+	return &StatusReconciliationRepository{db: db}
+}
+
+type StatusReconciliationRepository struct {
+	db *gorm.DB
+}
+
+func (s *StatusReconciliationRepository) Create(
+	ctx context.Context,
+	tx *gorm.DB,
+	report *dto.ReconcileReport,
+) (*models.StatusReconciliation, error) {
+	var orderIDs *string
+	if len(report.OrderIDs) > 0 {
+		joined := strings.Join(report.OrderIDs, ",")
+		orderIDs = &joined
+	}
+
+	discrepancies := make([]models.StatusDiscrepancy, 0, len(report.Discrepancies))
+	for _, item := range report.Discrepancies {
+		discrepancies = append(discrepancies, models.StatusDiscrepancy{
+			PaymentID:     item.PaymentID,
+			OrderID:       item.OrderID,
+			LocalStatus:   item.LocalStatus,
+			GatewayStatus: item.GatewayStatus,
+			Action:        string(item.Action),
+			Note:          item.Note,
+		})
+	}
+
+	reconciliation := models.StatusReconciliation{
+		UUID:               report.UUID,
+		StartDate:          report.StartDate,
+		EndDate:            report.EndDate,
+		OrderIDs:           orderIDs,
+		TotalChecked:       report.TotalChecked,
+		TotalDiscrepancies: report.TotalDiscrepancies,
+		TotalApplied:       report.TotalApplied,
+		Discrepancies:      discrepancies,
+	}
+	err := tx.WithContext(ctx).Create(&reconciliation).Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &reconciliation, nil
+}
+
+// RunExclusive runs fn while holding the advisory lock of key, so replicas do
+// not reconcile the same payments at once. The lock is taken and released on
+// one pinned connection since it belongs to the session.
+func (s *StatusReconciliationRepository) RunExclusive(ctx context.Context, key string, fn func() error) error {
+	return s.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		var locked bool
+		err := conn.Raw("SELECT pg_try_advisory_lock(hashtext(?))", key).Scan(&locked).Error
+		if err != nil {
+			return errWrap.WrapError(errConstant.ErrSQLError)
+		}
+		if !locked {
+			return errReconciliation.ErrReconcileInProgress
+		}
+		defer func() {
+			err := conn.Exec("SELECT pg_advisory_unlock(hashtext(?))", key).Error
+			if err != nil {
+				logrus.Errorf("failed to release reconciliation lock %s: %v", key, err)
+			}
+		}()
+		return fn()
+	})
+}
@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	errWrap "payment-service/common/error"
+	"payment-service/constants"
+	errConstant "payment-service/constants/error"
+	errSubscription "payment-service/constants/error/subscription"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"time"
+)
+
+type ISubscriptionRepository interface {
+	FindAllWithPagination(context.Context, *dto.SubscriptionRequestParam) ([]models.Subscription, int64, error)
+	FindByUUID(context.Context, string) (*models.Subscription, error)
+	FindDue(context.Context, time.Time) ([]models.Subscription, error)
+	FindAwaitingPayment(context.Context) ([]models.Subscription, error)
+	Claim(context.Context, *models.Subscription, time.Time) (bool, error)
+	Create(context.Context, *models.Subscription) (*models.Subscription, error)
+	Update(context.Context, uint, *dto.UpdateSubscriptionRequest) error
+	UpdatePending(context.Context, uint, uuid.UUID, *dto.UpdateSubscriptionRequest) (bool, error)
+}
+
+func NewSubscriptionRepository(db *gorm.DB) ISubscriptionRepository {
+	return &SubscriptionRepository{db: db}
+}
+
+type SubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func (s *SubscriptionRepository) FindAllWithPagination(
+	ctx context.Context,
+	param *dto.SubscriptionRequestParam,
+) ([]models.Subscription, int64, error) {
+	var (
+		subscriptions []models.Subscription
+		total         int64
+	)
+	limit := param.Limit
+	offset := (param.Page - 1) * limit
+	err := s.filter(s.db.WithContext(ctx), param).
+		Preload("Plan").
+		Limit(limit).
+		Offset(offset).
+		Order("created_at desc").
+		Find(&subscriptions).
+		Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	err = s.filter(s.db.WithContext(ctx), param).
+		Model(&models.Subscription{}).
+		Count(&total).
+		Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return subscriptions, total, nil
+}
+
+func (s *SubscriptionRepository) filter(query *gorm.DB, param *dto.SubscriptionRequestParam) *gorm.DB {
+	if param.Status != nil {
+		query = query.Where("status = ?", *param.Status)
+	}
+	if param.CustomerID != nil {
+		query = query.Where("customer_id = ?", *param.CustomerID)
+	}
+	return query
+}
+
+func (s *SubscriptionRepository) FindByUUID(ctx context.Context, uuid string) (*models.Subscription, error) {
+	var subscription models.Subscription
+	err := s.db.WithContext(ctx).
+		Preload("Plan").
+		Where("uuid = ?", uuid).
+		First(&subscription).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(errSubscription.ErrSubscriptionNotFound)
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &subscription, nil
+}
+
+// FindDue returns the subscriptions whose next charge is due and that have no
+// charge in flight.
+func (s *SubscriptionRepository) FindDue(ctx context.Context, now time.Time) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	err := s.db.WithContext(ctx).
+		Preload("Plan").
+		Where("status IN ?", []constants.SubscriptionStatus{constants.SubscriptionActive, constants.SubscriptionPastDue}).
+		Where("pending_payment_id IS NULL").
+		Where("next_billing_at <= ?", now).
+		Order("next_billing_at asc").
+		Find(&subscriptions).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return subscriptions, nil
+}
+
+func (s *SubscriptionRepository) FindAwaitingPayment(ctx context.Context) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	err := s.db.WithContext(ctx).
+		Preload("Plan").
+		Where("status <> ?", constants.SubscriptionCancelled).
+		Where("pending_payment_id IS NOT NULL").
+		Find(&subscriptions).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return subscriptions, nil
+}
+
+// Claim moves next_billing_at of a due subscription to until, only one
+// instance of the service wins the claim and charges the subscription.
+func (s *SubscriptionRepository) Claim(ctx context.Context, subscription *models.Subscription, until time.Time) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Model(&models.Subscription{}).
+		Where("id = ? AND next_billing_at = ? AND pending_payment_id IS NULL", subscription.ID, subscription.NextBillingAt).
+		Update("next_billing_at", until)
+	if result.Error != nil {
+		return false, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (s *SubscriptionRepository) Create(ctx context.Context, subscription *models.Subscription) (*models.Subscription, error) {
+	subscription.UUID = uuid.New()
+	err := s.db.WithContext(ctx).Omit("Plan").Create(subscription).Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return subscription, nil
+}
+
+func (s *SubscriptionRepository) Update(ctx context.Context, id uint, request *dto.UpdateSubscriptionRequest) error {
+	err := s.db.WithContext(ctx).
+		Model(&models.Subscription{}).
+		Where("id = ?", id).
+		Updates(s.values(request)).
+		Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+// UpdatePending applies the update only while pendingPaymentID is still the
+// pending payment of the subscription, so the outcome of a charge is applied
+// by exactly one instance of the service.
+func (s *SubscriptionRepository) UpdatePending(
+	ctx context.Context,
+	id uint,
+	pendingPaymentID uuid.UUID,
+	request *dto.UpdateSubscriptionRequest,
+) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Model(&models.Subscription{}).
+		Where("id = ? AND pending_payment_id = ?", id, pendingPaymentID).
+		Updates(s.values(request))
+	if result.Error != nil {
+		return false, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (s *SubscriptionRepository) values(request *dto.UpdateSubscriptionRequest) map[string]interface{} {
+	values := map[string]interface{}{}
+	if request.Status != nil {
+		values["status"] = *request.Status
+	}
+	if request.Cycle != nil {
+		values["cycle"] = *request.Cycle
+	}
+	if request.RetryCount != nil {
+		values["retry_count"] = *request.RetryCount
+	}
+	if request.CurrentPeriodStart != nil {
+		values["current_period_start"] = *request.CurrentPeriodStart
+	}
+	if request.CurrentPeriodEnd != nil {
+		values["current_period_end"] = *request.CurrentPeriodEnd
+	}
+	if request.NextBillingAt != nil {
+		values["next_billing_at"] = *request.NextBillingAt
+	}
+	if request.PendingPaymentID != nil {
+		values["pending_payment_id"] = *request.PendingPaymentID
+	}
+	if request.ClearPendingPayment {
+		values["pending_payment_id"] = nil
+	}
+	if request.LastPaymentID != nil {
+		values["last_payment_id"] = *request.LastPaymentID
+	}
+	if request.FailureReason != nil {
+		values["failure_reason"] = *request.FailureReason
+	}
+	if request.CancelledAt != nil {
+		values["cancelled_at"] = *request.CancelledAt
+	}
+	if request.CancelReason != nil {
+		values["cancel_reason"] = *request.CancelReason
+	}
+	return values
+}
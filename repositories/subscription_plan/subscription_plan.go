@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	errWrap "payment-service/common/error"
+	"payment-service/common/money"
+	"payment-service/constants"
+	errConstant "payment-service/constants/error"
+	errSubscription "payment-service/constants/error/subscription"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+)
+
+type ISubscriptionPlanRepository interface {
+	FindAllActive(context.Context) ([]models.SubscriptionPlan, error)
+	FindByCode(context.Context, string) (*models.SubscriptionPlan, error)
+	Create(context.Context, *dto.SubscriptionPlanRequest) (*models.SubscriptionPlan, error)
+}
+
+func NewSubscriptionPlanRepository(db *gorm.DB) ISubscriptionPlanRepository {
+	return &SubscriptionPlanRepository{db: db}
+}
+
+type SubscriptionPlanRepository struct {
+	db *gorm.DB
+}
+
+func (s *SubscriptionPlanRepository) FindAllActive(ctx context.Context) ([]models.SubscriptionPlan, error) {
+	var plans []models.SubscriptionPlan
+	err := s.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Order("amount asc").
+		Find(&plans).
+		Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return plans, nil
+}
+
+func (s *SubscriptionPlanRepository) FindByCode(ctx context.Context, code string) (*models.SubscriptionPlan, error) {
+	var plan models.SubscriptionPlan
+	err := s.db.WithContext(ctx).
+		Where("code = ?", code).
+		First(&plan).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(errSubscription.ErrPlanNotFound)
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &plan, nil
+}
+
+func (s *SubscriptionPlanRepository) Create(ctx context.Context, request *dto.SubscriptionPlanRequest) (*models.SubscriptionPlan, error) {
+	currency := request.Currency
+	if currency == "" {
+		currency = constants.DefaultCurrency
+	}
+	intervalCount := request.IntervalCount
+	if intervalCount == 0 {
+		intervalCount = 1
+	}
+	amount := money.FromMajor(request.Amount, currency)
+	plan := models.SubscriptionPlan{
+		UUID:          uuid.New(),
+		Code:          request.Code,
+		Name:          request.Name,
+		Description:   request.Description,
+		Amount:        amount.Amount,
+		Currency:      amount.Currency,
+		Interval:      request.Interval,
+		IntervalCount: intervalCount,
+		TrialDays:     request.TrialDays,
+		IsActive:      true,
+	}
+	err := s.db.WithContext(ctx).Create(&plan).Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &plan, nil
+}
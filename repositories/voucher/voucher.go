@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	errWrap "payment-service/common/error"
+	"payment-service/common/money"
+	"payment-service/constants"
+	errConstant "payment-service/constants/error"
+	errVoucher "payment-service/constants/error/voucher"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+)
+
+type IVoucherRepository interface {
+	FindAllWithPagination(context.Context, *dto.VoucherRequestParam) ([]models.Voucher, int64, error)
+	FindByUUID(context.Context, string) (*models.Voucher, error)
+	FindByCode(context.Context, string) (*models.Voucher, error)
+	Lock(context.Context, *gorm.DB, uint) (*models.Voucher, error)
+	Create(context.Context, *dto.VoucherRequest) (*models.Voucher, error)
+	Deactivate(context.Context, uint) error
+	CountUsage(context.Context, *gorm.DB, uint, *uuid.UUID) (int64, error)
+	CreateRedemption(context.Context, *gorm.DB, *dto.VoucherRedemptionRequest) error
+	UpdateRedemption(context.Context, *gorm.DB, uint, string, string) error
+}
+
+func NewVoucherRepository(db *gorm.DB) IVoucherRepository {
+	return &VoucherRepository{db: db}
+}
+
+type VoucherRepository struct {
+	db *gorm.DB
+}
+
+func (v *VoucherRepository) FindAllWithPagination(
+	ctx context.Context,
+	param *dto.VoucherRequestParam,
+) ([]models.Voucher, int64, error) {
+	var (
+		vouchers []models.Voucher
+		total    int64
+	)
+	limit := param.Limit
+	offset := (param.Page - 1) * limit
+	err := v.filter(v.db.WithContext(ctx), param).
+		Limit(limit).
+		Offset(offset).
+		Order("created_at desc").
+		Find(&vouchers).
+		Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	err = v.filter(v.db.WithContext(ctx), param).
+		Model(&models.Voucher{}).
+		Count(&total).
+		Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return vouchers, total, nil
+}
+
+func (v *VoucherRepository) filter(query *gorm.DB, param *dto.VoucherRequestParam) *gorm.DB {
+	if param.IsActive != nil {
+		query = query.Where("is_active = ?", *param.IsActive)
+	}
+	return query
+}
+
+func (v *VoucherRepository) FindByUUID(ctx context.Context, uuid string) (*models.Voucher, error) {
+	return v.find(v.db.WithContext(ctx).Where("uuid = ?", uuid))
+}
+
+func (v *VoucherRepository) FindByCode(ctx context.Context, code string) (*models.Voucher, error) {
+	return v.find(v.db.WithContext(ctx).Where("UPPER(code) = UPPER(?)", code))
+}
+
+// Lock reads the voucher again and holds it until the transaction ends, so
+// concurrent payments cannot use it beyond its limits.
+func (v *VoucherRepository) Lock(ctx context.Context, tx *gorm.DB, id uint) (*models.Voucher, error) {
+	return v.find(tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id))
+}
+
+func (v *VoucherRepository) find(query *gorm.DB) (*models.Voucher, error) {
+	var voucher models.Voucher
+	err := query.First(&voucher).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(errVoucher.ErrVoucherNotFound)
+		}
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &voucher, nil
+}
+
+func (v *VoucherRepository) Create(ctx context.Context, request *dto.VoucherRequest) (*models.Voucher, error) {
+	currency := request.Currency
+	if currency == "" {
+		currency = constants.DefaultCurrency
+	}
+	minSpend := money.FromMajor(request.MinSpend, currency)
+	voucher := models.Voucher{
+		UUID:         uuid.New(),
+		Code:         request.Code,
+		Type:         request.Type,
+		Currency:     minSpend.Currency,
+		MinSpend:     minSpend.Amount,
+		UsageLimit:   request.UsageLimit,
+		PerUserLimit: request.PerUserLimit,
+		StartsAt:     request.StartsAt,
+		EndsAt:       request.EndsAt,
+		IsActive:     true,
+	}
+	if request.Type == constants.VoucherPercentage {
+		voucher.Percentage = &request.Value
+	} else {
+		amount := money.FromMajor(request.Value, currency).Amount
+		voucher.Amount = &amount
+	}
+	if request.MaxDiscount != nil {
+		maxDiscount := money.FromMajor(*request.MaxDiscount, currency).Amount
+		voucher.MaxDiscount = &maxDiscount
+	}
+	err := v.db.WithContext(ctx).Create(&voucher).Error
+	if err != nil {
+		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return &voucher, nil
+}
+
+func (v *VoucherRepository) Deactivate(ctx context.Context, id uint) error {
+	err := v.db.WithContext(ctx).
+		Model(&models.Voucher{}).
+		Where("id = ?", id).
+		Update("is_active", false).
+		Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+// CountUsage counts the reserved and redeemed uses of a voucher, those of one
+// customer when customerID is set.
+func (v *VoucherRepository) CountUsage(ctx context.Context, tx *gorm.DB, voucherID uint, customerID *uuid.UUID) (int64, error) {
+	var count int64
+	query := tx.WithContext(ctx).
+		Model(&models.VoucherRedemption{}).
+		Where("voucher_id = ?", voucherID).
+		Where("status IN ?", []string{constants.RedemptionReserved, constants.RedemptionRedeemed})
+	if customerID != nil {
+		query = query.Where("customer_id = ?", *customerID)
+	}
+	err := query.Count(&count).Error
+	if err != nil {
+		return 0, errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return count, nil
+}
+
+func (v *VoucherRepository) CreateRedemption(ctx context.Context, tx *gorm.DB, request *dto.VoucherRedemptionRequest) error {
+	redemption := models.VoucherRedemption{
+		UUID:       uuid.New(),
+		VoucherID:  request.VoucherID,
+		PaymentID:  request.PaymentID,
+		CustomerID: request.CustomerID,
+		Discount:   request.Discount.Amount,
+		Currency:   request.Discount.Currency,
+		Status:     constants.RedemptionReserved,
+	}
+	err := tx.WithContext(ctx).Create(&redemption).Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+// UpdateRedemption moves the redemption of a payment from one status to
+// another, a payment without one in that status is left alone.
+func (v *VoucherRepository) UpdateRedemption(ctx context.Context, tx *gorm.DB, paymentID uint, from, to string) error {
+	err := tx.WithContext(ctx).
+		Model(&models.VoucherRedemption{}).
+		Where("payment_id = ? AND status = ?", paymentID, from).
+		Update("status", to).
+		Error
+	if err != nil {
+		return errWrap.WrapError(errConstant.ErrSQLError)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	controllers "payment-service/controllers/http"
+)
+
+type ICheckoutRoute interface {
+	Run()
+}
+type CheckoutRoute struct {
+	controller controllers.IControllerRegistry
+	group      *gin.RouterGroup
+}
+
+// Run serves the page customers land on after paying, the token in the link
+// stands in for authentication.
+func (c *CheckoutRoute) Run() {
+	group := c.group.Group("/pay")
+	group.GET("/:uuid/status", c.controller.GetCheckout().GetStatus)
+}
+
+func NewCheckoutRoute(
+	controller controllers.IControllerRegistry,
+	group *gin.RouterGroup,
+) ICheckoutRoute {
+	return &CheckoutRoute{
+		controller: controller,
+		group:      group,
+	}
+}
@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"payment-service/clients"
+	"payment-service/constants"
+	controllers "payment-service/controllers/http"
+	"payment-service/middlewares"
+)
+
+type ILedgerRoute interface {
+	Run()
+}
+type LedgerRoute struct {
+	controller controllers.IControllerRegistry
+	client     clients.IClientRegistry
+	group      *gin.RouterGroup
+}
+
+func (l *LedgerRoute) Run() {
+	group := l.group.Group("/ledger")
+	group.Use(middlewares.Authenticate())
+	group.Use(middlewares.CheckRole([]string{
+		constants.Admin,
+	}, l.client))
+
+	group.GET("/merchants", l.controller.GetLedger().GetMerchantBalances)
+	group.GET("/merchants/:merchantID", l.controller.GetLedger().GetMerchantBalance)
+	group.GET("/trial-balance", l.controller.GetLedger().GetTrialBalance)
+	group.GET("/accounts/:uuid/statement", l.controller.GetLedger().GetAccountStatement)
+}
+
+func NewLedgerRoute(
+	controller controllers.IControllerRegistry,
+	client clients.IClientRegistry,
+	group *gin.RouterGroup,
+) ILedgerRoute {
+	return &LedgerRoute{
+		controller: controller,
+		client:     client,
+		group:      group,
+	}
+}
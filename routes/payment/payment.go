@@ -6,6 +6,7 @@ import (
 	"payment-service/constants"
 	controllers "payment-service/controllers/http"
 	"payment-service/middlewares"
+	"payment-service/services"
 )
 
 type IPaymentRoute interface {
@@ -14,12 +15,14 @@ type IPaymentRoute interface {
 type PaymentRoute struct {
 	controller controllers.IControllerRegistry
 	client     clients.IClientRegistry
+	service    services.IServiceRegistry
 	group      *gin.RouterGroup
 }
 
 func (p *PaymentRoute) Run() {
 	group := p.group.Group("/payment")
 	group.POST("/webhook", p.controller.GetPayment().Webhook)
+	group.POST("/webhook/:provider", p.controller.GetPayment().Webhook)
 
 	group.Use(middlewares.Authenticate())
 
@@ -28,6 +31,22 @@ func (p *PaymentRoute) Run() {
 		constants.Customer,
 	}, p.client), p.controller.GetPayment().GetAllWithPagination)
 
+	group.GET("/summary", middlewares.CheckRole([]string{
+		constants.Admin,
+	}, p.client), p.controller.GetPayment().Summary)
+
+	group.GET("/review", middlewares.CheckRole([]string{
+		constants.Admin,
+	}, p.client), p.controller.GetPayment().GetReviewQueue)
+
+	group.GET("/export", middlewares.CheckRole([]string{
+		constants.Admin,
+	}, p.client), p.controller.GetPayment().Export)
+
+	group.GET("/export/:uuid", middlewares.CheckRole([]string{
+		constants.Admin,
+	}, p.client), p.controller.GetPayment().GetExportByUUID)
+
 	group.GET("/:uuid", middlewares.CheckRole([]string{
 		constants.Admin,
 		constants.Customer,
@@ -35,17 +54,31 @@ func (p *PaymentRoute) Run() {
 
 	group.POST("", middlewares.CheckRole([]string{
 		constants.Customer,
-	}, p.client), p.controller.GetPayment().Create)
+	}, p.client), middlewares.Idempotency(p.service), p.controller.GetPayment().Create)
+
+	group.POST("/charge", middlewares.CheckRole([]string{
+		constants.Customer,
+	}, p.client), middlewares.Idempotency(p.service), p.controller.GetPayment().Charge)
+
+	group.POST("/:uuid/capture", middlewares.CheckRole([]string{
+		constants.Admin,
+	}, p.client), middlewares.Idempotency(p.service), p.controller.GetPayment().Capture)
+
+	group.POST("/:uuid/void", middlewares.CheckRole([]string{
+		constants.Admin,
+	}, p.client), p.controller.GetPayment().Void)
 }
 
 func NewPaymentRoute(
 	controller controllers.IControllerRegistry,
 	client clients.IClientRegistry,
+	service services.IServiceRegistry,
 	group *gin.RouterGroup,
 ) IPaymentRoute {
 	return &PaymentRoute{
 		controller: controller,
 		client:     client,
+		service:    service,
 		group:      group,
 	}
 }
@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"github.com/didip/tollbooth"
+	"github.com/didip/tollbooth/limiter"
+	"github.com/gin-gonic/gin"
+	"payment-service/clients"
+	"payment-service/config"
+	"payment-service/constants"
+	controllers "payment-service/controllers/http"
+	"payment-service/middlewares"
+	"time"
+)
+
+const defaultUsesPerMinute = 5
+
+type IPaymentLinkRoute interface {
+	Run()
+}
+type PaymentLinkRoute struct {
+	controller controllers.IControllerRegistry
+	client     clients.IClientRegistry
+	group      *gin.RouterGroup
+}
+
+func (p *PaymentLinkRoute) Run() {
+	// The landing page of a link is opened by payers without an account.
+	public := p.group.Group("/link")
+	public.GET("/:code", p.controller.GetPaymentLink().GetByCode)
+	public.POST("/:code", middlewares.RateLimiter(p.useLimiter()), p.controller.GetPaymentLink().Use)
+
+	group := p.group.Group("/payment-link")
+	group.Use(middlewares.Authenticate())
+	group.Use(middlewares.CheckRole([]string{
+		constants.Admin,
+	}, p.client))
+
+	group.GET("", p.controller.GetPaymentLink().GetAllWithPagination)
+	group.GET("/:uuid", p.controller.GetPaymentLink().GetByUUID)
+	group.POST("", p.controller.GetPaymentLink().Create)
+	group.POST("/:uuid/deactivate", p.controller.GetPaymentLink().Deactivate)
+}
+
+// useLimiter limits how often a payer can create payments through links,
+// every pending payment holds a use of the link until it expires.
+func (p *PaymentLinkRoute) useLimiter() *limiter.Limiter {
+	usesPerMinute := defaultUsesPerMinute
+	if config.Config.PaymentLink.UsesPerMinute > 0 {
+		usesPerMinute = config.Config.PaymentLink.UsesPerMinute
+	}
+	return tollbooth.NewLimiter(
+		float64(usesPerMinute)/60,
+		&limiter.ExpirableOptions{
+			DefaultExpirationTTL: time.Hour,
+		},
+	).SetBurst(usesPerMinute)
+}
+
+func NewPaymentLinkRoute(
+	controller controllers.IControllerRegistry,
+	client clients.IClientRegistry,
+	group *gin.RouterGroup,
+) IPaymentLinkRoute {
+	return &PaymentLinkRoute{
+		controller: controller,
+		client:     client,
+		group:      group,
+	}
+}
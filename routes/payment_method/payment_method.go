@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"payment-service/clients"
+	"payment-service/constants"
+	controllers "payment-service/controllers/http"
+	"payment-service/middlewares"
+)
+
+type IPaymentMethodRoute interface {
+	Run()
+}
+type PaymentMethodRoute struct {
+	controller controllers.IControllerRegistry
+	client     clients.IClientRegistry
+	group      *gin.RouterGroup
+}
+
+func (p *PaymentMethodRoute) Run() {
+	group := p.group.Group("/payment/methods")
+	group.Use(middlewares.Authenticate())
+	group.Use(middlewares.CheckRole([]string{
+		constants.Customer,
+	}, p.client))
+
+	group.GET("", p.controller.GetPaymentMethod().GetAll)
+	group.DELETE("/:uuid", p.controller.GetPaymentMethod().Delete)
+}
+
+func NewPaymentMethodRoute(
+	controller controllers.IControllerRegistry,
+	client clients.IClientRegistry,
+	group *gin.RouterGroup,
+) IPaymentMethodRoute {
+	return &PaymentMethodRoute{
+		controller: controller,
+		client:     client,
+		group:      group,
+	}
+}
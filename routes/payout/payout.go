@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"payment-service/clients"
+	"payment-service/constants"
+	controllers "payment-service/controllers/http"
+	"payment-service/middlewares"
+	"payment-service/services"
+)
+
+type IPayoutRoute interface {
+	Run()
+}
+type PayoutRoute struct {
+	controller controllers.IControllerRegistry
+	client     clients.IClientRegistry
+	service    services.IServiceRegistry
+	group      *gin.RouterGroup
+}
+
+func (p *PayoutRoute) Run() {
+	group := p.group.Group("/payout/batches")
+	group.Use(middlewares.Authenticate())
+	group.Use(middlewares.CheckRole([]string{
+		constants.Admin,
+	}, p.client))
+
+	group.POST("", middlewares.Idempotency(p.service), p.controller.GetPayout().CreateBatch)
+	group.GET("/:uuid", p.controller.GetPayout().GetBatch)
+	group.POST("/:uuid/upload", p.controller.GetPayout().UploadBatch)
+}
+
+func NewPayoutRoute(
+	controller controllers.IControllerRegistry,
+	client clients.IClientRegistry,
+	service services.IServiceRegistry,
+	group *gin.RouterGroup,
+) IPayoutRoute {
+	return &PayoutRoute{
+		controller: controller,
+		client:     client,
+		service:    service,
+		group:      group,
+	}
+}
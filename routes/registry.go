@@ -4,7 +4,16 @@ import (
 	"github.com/gin-gonic/gin"
 	"payment-service/clients"
 	controllers "payment-service/controllers/http"
+	routes9 "payment-service/routes/checkout"
+	routes5 "payment-service/routes/ledger"
 	routes "payment-service/routes/payment"
+	routes8 "payment-service/routes/payment_link"
+	routes4 "payment-service/routes/payment_method"
+	routes6 "payment-service/routes/payout"
+	routes2 "payment-service/routes/settlement"
+	routes3 "payment-service/routes/subscription"
+	routes7 "payment-service/routes/voucher"
+	"payment-service/services"
 )
 
 type IRouteRegistry interface {
@@ -14,24 +23,67 @@ type Registry struct {
 	controller controllers.IControllerRegistry
 	group      *gin.RouterGroup
 	client     clients.IClientRegistry
+	service    services.IServiceRegistry
 }
 
 func (r *Registry) Serve() {
 	r.paymentRoute().Run()
+	r.settlementRoute().Run()
+	r.subscriptionRoute().Run()
+	r.paymentMethodRoute().Run()
+	r.ledgerRoute().Run()
+	r.payoutRoute().Run()
+	r.voucherRoute().Run()
+	r.paymentLinkRoute().Run()
+	r.checkoutRoute().Run()
 }
 
 func (r *Registry) paymentRoute() routes.IPaymentRoute {
-	return routes.NewPaymentRoute(r.controller, r.client, r.group)
+	return routes.NewPaymentRoute(r.controller, r.client, r.service, r.group)
+}
+
+func (r *Registry) settlementRoute() routes2.ISettlementRoute {
+	return routes2.NewSettlementRoute(r.controller, r.client, r.group)
+}
+
+func (r *Registry) subscriptionRoute() routes3.ISubscriptionRoute {
+	return routes3.NewSubscriptionRoute(r.controller, r.client, r.service, r.group)
+}
+
+func (r *Registry) paymentMethodRoute() routes4.IPaymentMethodRoute {
+	return routes4.NewPaymentMethodRoute(r.controller, r.client, r.group)
+}
+
+func (r *Registry) ledgerRoute() routes5.ILedgerRoute {
+	return routes5.NewLedgerRoute(r.controller, r.client, r.group)
+}
+
+func (r *Registry) payoutRoute() routes6.IPayoutRoute {
+	return routes6.NewPayoutRoute(r.controller, r.client, r.service, r.group)
+}
+
+func (r *Registry) voucherRoute() routes7.IVoucherRoute {
+	return routes7.NewVoucherRoute(r.controller, r.client, r.group)
+}
+
+func (r *Registry) paymentLinkRoute() routes8.IPaymentLinkRoute {
+	return routes8.NewPaymentLinkRoute(r.controller, r.client, r.group)
+}
+
+func (r *Registry) checkoutRoute() routes9.ICheckoutRoute {
+	return routes9.NewCheckoutRoute(r.controller, r.group)
 }
 
 func NewRouteRegistry(
 	group *gin.RouterGroup,
 	controller controllers.IControllerRegistry,
 	client clients.IClientRegistry,
+	service services.IServiceRegistry,
 ) IRouteRegistry {
 	return &Registry{
 		controller: controller,
 		group:      group,
 		client:     client,
+		service:    service,
 	}
 }
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"payment-service/clients"
+	"payment-service/constants"
+	controllers "payment-service/controllers/http"
+	"payment-service/middlewares"
+)
+
+type ISettlementRoute interface {
+	Run()
+}
+type SettlementRoute struct {
+	controller controllers.IControllerRegistry
+	client     clients.IClientRegistry
+	group      *gin.RouterGroup
+}
+
+func (s *SettlementRoute) Run() {
+	group := s.group.Group("/settlement")
+	group.Use(middlewares.Authenticate())
+	group.Use(middlewares.CheckRole([]string{
+		constants.Admin,
+	}, s.client))
+
+	group.GET("/reconciliation", s.controller.GetSettlement().GetAllWithPagination)
+	group.GET("/reconciliation/:uuid", s.controller.GetSettlement().GetByUUID)
+	group.POST("/reconciliation", s.controller.GetSettlement().Import)
+}
+
+func NewSettlementRoute(
+	controller controllers.IControllerRegistry,
+	client clients.IClientRegistry,
+	group *gin.RouterGroup,
+) ISettlementRoute {
+	return &SettlementRoute{
+		controller: controller,
+		client:     client,
+		group:      group,
+	}
+}
@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"payment-service/clients"
+	"payment-service/constants"
+	controllers "payment-service/controllers/http"
+	"payment-service/middlewares"
+	"payment-service/services"
+)
+
+type ISubscriptionRoute interface {
+	Run()
+}
+type SubscriptionRoute struct {
+	controller controllers.IControllerRegistry
+	client     clients.IClientRegistry
+	service    services.IServiceRegistry
+	group      *gin.RouterGroup
+}
+
+func (s *SubscriptionRoute) Run() {
+	group := s.group.Group("/subscription")
+	group.Use(middlewares.Authenticate())
+
+	group.GET("/plans", middlewares.CheckRole([]string{
+		constants.Admin,
+		constants.Customer,
+	}, s.client), s.controller.GetSubscription().GetPlans)
+
+	group.POST("/plans", middlewares.CheckRole([]string{
+		constants.Admin,
+	}, s.client), s.controller.GetSubscription().CreatePlan)
+
+	group.GET("", middlewares.CheckRole([]string{
+		constants.Admin,
+		constants.Customer,
+	}, s.client), s.controller.GetSubscription().GetAllWithPagination)
+
+	group.GET("/:uuid", middlewares.CheckRole([]string{
+		constants.Admin,
+		constants.Customer,
+	}, s.client), s.controller.GetSubscription().GetByUUID)
+
+	group.POST("", middlewares.CheckRole([]string{
+		constants.Customer,
+	}, s.client), middlewares.Idempotency(s.service), s.controller.GetSubscription().Create)
+
+	group.POST("/:uuid/cancel", middlewares.CheckRole([]string{
+		constants.Admin,
+		constants.Customer,
+	}, s.client), s.controller.GetSubscription().Cancel)
+}
+
+func NewSubscriptionRoute(
+	controller controllers.IControllerRegistry,
+	client clients.IClientRegistry,
+	service services.IServiceRegistry,
+	group *gin.RouterGroup,
+) ISubscriptionRoute {
+	return &SubscriptionRoute{
+		controller: controller,
+		client:     client,
+		service:    service,
+		group:      group,
+	}
+}
@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"payment-service/clients"
+	"payment-service/constants"
+	controllers "payment-service/controllers/http"
+	"payment-service/middlewares"
+)
+
+type IVoucherRoute interface {
+	Run()
+}
+type VoucherRoute struct {
+	controller controllers.IControllerRegistry
+	client     clients.IClientRegistry
+	group      *gin.RouterGroup
+}
+
+func (v *VoucherRoute) Run() {
+	group := v.group.Group("/voucher")
+	group.Use(middlewares.Authenticate())
+	group.Use(middlewares.CheckRole([]string{
+		constants.Admin,
+	}, v.client))
+
+	group.GET("", v.controller.GetVoucher().GetAllWithPagination)
+	group.GET("/:uuid", v.controller.GetVoucher().GetByUUID)
+	group.POST("", v.controller.GetVoucher().Create)
+	group.POST("/:uuid/deactivate", v.controller.GetVoucher().Deactivate)
+}
+
+func NewVoucherRoute(
+	controller controllers.IControllerRegistry,
+	client clients.IClientRegistry,
+	group *gin.RouterGroup,
+) IVoucherRoute {
+	return &VoucherRoute{
+		controller: controller,
+		client:     client,
+		group:      group,
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"payment-service/common/checkout"
+	"payment-service/constants"
+	errCheckout "payment-service/constants/error/checkout"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories"
+	services "payment-service/services/payment"
+	"strings"
+	"time"
+)
+
+type ICheckoutService interface {
+	GetStatus(context.Context, string, string) (*dto.CheckoutStatusResponse, error)
+}
+
+func NewCheckoutService(repository repositories.IRepositoryRegistry, payment services.IPaymentService) ICheckoutService {
+	return &CheckoutService{repository: repository, payment: payment}
+}
+
+type CheckoutService struct {
+	repository repositories.IRepositoryRegistry
+	payment    services.IPaymentService
+}
+
+// GetStatus checks the token before reading the payment, so an unknown uuid
+// and a wrong token can not be told apart.
+func (c *CheckoutService) GetStatus(ctx context.Context, uuid, token string) (*dto.CheckoutStatusResponse, error) {
+	if !checkout.Verify(uuid, token) {
+		return nil, errCheckout.ErrCheckoutTokenInvalid
+	}
+	payment, err := c.repository.GetPayment().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := c.payment.DueAmount(payment)
+	outstanding := c.payment.OutstandingAmount(payment)
+
+	status := *payment.Status
+	isOpen := status == constants.Initial || status == constants.Pending || status == constants.PartiallyPaid
+	isPaid := status == constants.Settlement || status == constants.PartiallyRefunded || status == constants.Refunded
+	response := &dto.CheckoutStatusResponse{
+		UUID:              payment.UUID,
+		OrderID:           payment.OrderID,
+		Description:       payment.Description,
+		Amount:            amount.Major(),
+		AmountText:        amount.Format(),
+		OutstandingAmount: outstanding.Major(),
+		OutstandingText:   outstanding.Format(),
+		Currency:          payment.Currency,
+		Status:            status.GetStatusString(),
+		IsPaid:            isPaid,
+		IsOpen:            isOpen,
+		PaymentMethod:     payment.PaymentMethod,
+		Bank:              payment.Bank,
+		VANumber:          payment.VANumber,
+		QRImageURL:        payment.QRImageURL,
+		Deeplink:          payment.Deeplink,
+		ExpiredAt:         payment.ExpiredAt,
+		PaidAt:            payment.PaidAt,
+	}
+	if isOpen {
+		response.PaymentLink = payment.PaymentLink
+		response.Instructions = c.instructions(payment)
+		if payment.ExpiredAt != nil {
+			if remaining := time.Until(*payment.ExpiredAt); remaining > 0 {
+				response.ExpiresInSeconds = int64(remaining.Seconds())
+			}
+		}
+	}
+	if isPaid {
+		response.InvoiceLink = payment.InvoiceLink
+	}
+	return response, nil
+}
+
+// instructions tell the customer how to finish a payment that is waiting for
+// them outside of the payment page.
+func (c *CheckoutService) instructions(payment *models.Payment) []string {
+	switch {
+	case payment.VANumber != nil:
+		bank := "bank"
+		if payment.Bank != nil {
+			bank = strings.ToUpper(*payment.Bank)
+		}
+		return []string{
+			fmt.Sprintf("Buka aplikasi mobile banking, internet banking atau ATM %s.", bank),
+			"Pilih menu transfer ke virtual account.",
+			fmt.Sprintf("Masukkan nomor virtual account %s.", *payment.VANumber),
+			"Periksa nama dan jumlah tagihan, lalu konfirmasi pembayaran.",
+		}
+	case payment.QRImageURL != nil || payment.QRString != nil:
+		return []string{
+			"Buka aplikasi e-wallet atau mobile banking yang mendukung QRIS.",
+			"Pindai kode QR di halaman ini.",
+			"Periksa jumlah tagihan, lalu konfirmasi pembayaran.",
+		}
+	case payment.Deeplink != nil:
+		return []string{
+			"Tekan tombol bayar untuk membuka aplikasi e-wallet.",
+			"Periksa jumlah tagihan, lalu konfirmasi pembayaran.",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"payment-service/common/checkout"
+	"payment-service/config"
+	"payment-service/constants"
+	errCheckout "payment-service/constants/error/checkout"
+	errPayment "payment-service/constants/error/payment"
+	"payment-service/domain/models"
+	"payment-service/repositories/fake"
+	repositories "payment-service/repositories/payment"
+	services "payment-service/services/payment"
+)
+
+type fakePaymentRepository struct {
+	repositories.IPaymentRepository
+	payment *models.Payment
+}
+
+func (f *fakePaymentRepository) FindByUUID(_ context.Context, id string) (*models.Payment, error) {
+	if id != f.payment.UUID.String() {
+		return nil, errPayment.ErrPaymentNotFound
+	}
+	return f.payment, nil
+}
+
+func newTestCheckout(t *testing.T, payment *models.Payment) *CheckoutService {
+	secret := config.Config.Checkout.TokenSecret
+	t.Cleanup(func() { config.Config.Checkout.TokenSecret = secret })
+	config.Config.Checkout.TokenSecret = "secret"
+
+	payment.UUID = uuid.New()
+	payment.Currency = "IDR"
+	return &CheckoutService{
+		repository: &fake.Registry{Payment: &fakePaymentRepository{payment: payment}},
+		payment:    &services.PaymentService{},
+	}
+}
+
+func token(t *testing.T, id string) string {
+	signed, err := checkout.Token(id)
+	if err != nil {
+		t.Fatalf("Token unexpected error: %v", err)
+	}
+	return signed
+}
+
+func TestGetStatusChecksToken(t *testing.T) {
+	pending := constants.Pending
+	payment := &models.Payment{Amount: 1000000, Status: &pending}
+	service := newTestCheckout(t, payment)
+	id := payment.UUID.String()
+
+	tests := []struct {
+		name  string
+		uuid  string
+		token string
+		want  error
+	}{
+		{name: "valid token", uuid: id, token: token(t, id)},
+		{name: "no token", uuid: id, want: errCheckout.ErrCheckoutTokenInvalid},
+		{name: "token of another payment", uuid: id, token: token(t, uuid.NewString()), want: errCheckout.ErrCheckoutTokenInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.GetStatus(context.Background(), tt.uuid, tt.token)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("GetStatus error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	vaNumber, bank := "880812345", "bca"
+	paymentLink, invoiceLink := "https://pay.example/order", "https://invoice.example/order.pdf"
+	expiredAt := time.Now().Add(time.Hour)
+	tests := []struct {
+		name         string
+		status       constants.PaymentStatus
+		paid         int64
+		open         bool
+		outstanding  float64
+		instructions bool
+	}{
+		{name: "waiting for a transfer", status: constants.Pending, open: true, outstanding: 10000, instructions: true},
+		{name: "paid in part", status: constants.PartiallyPaid, paid: 400000, open: true, outstanding: 6000, instructions: true},
+		{name: "settled", status: constants.Settlement, paid: 1000000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &models.Payment{
+				Amount:      1000000,
+				PaidAmount:  tt.paid,
+				Status:      &tt.status,
+				VANumber:    &vaNumber,
+				Bank:        &bank,
+				PaymentLink: paymentLink,
+				InvoiceLink: &invoiceLink,
+				ExpiredAt:   &expiredAt,
+			}
+			service := newTestCheckout(t, payment)
+			id := payment.UUID.String()
+
+			response, err := service.GetStatus(context.Background(), id, token(t, id))
+			if err != nil {
+				t.Fatalf("GetStatus unexpected error: %v", err)
+			}
+			if response.IsOpen != tt.open || response.IsPaid == tt.open {
+				t.Errorf("open = %v and paid = %v, want open %v", response.IsOpen, response.IsPaid, tt.open)
+			}
+			if response.OutstandingAmount != tt.outstanding {
+				t.Errorf("outstanding = %.2f, want %.2f", response.OutstandingAmount, tt.outstanding)
+			}
+			if (len(response.Instructions) > 0) != tt.instructions {
+				t.Errorf("instructions = %v, want them only while the payment is open", response.Instructions)
+			}
+			// The payment page is offered while open, the invoice once paid.
+			if (response.PaymentLink != "") != tt.open || (response.InvoiceLink != nil) == tt.open {
+				t.Errorf("payment link = %v and invoice link = %v for an open payment %v", response.PaymentLink, response.InvoiceLink, tt.open)
+			}
+			if tt.open && response.ExpiresInSeconds <= 0 {
+				t.Errorf("expires in %d seconds, want the time left", response.ExpiresInSeconds)
+			}
+		})
+	}
+}
+
+func TestGetStatusWithoutSecret(t *testing.T) {
+	pending := constants.Pending
+	payment := &models.Payment{Amount: 1000000, Status: &pending}
+	service := newTestCheckout(t, payment)
+	id := payment.UUID.String()
+	valid := token(t, id)
+
+	// Without a secret no token is accepted, not even one signed before.
+	signatureKey := config.Config.SignatureKey
+	defer func() { config.Config.SignatureKey = signatureKey }()
+	config.Config.Checkout.TokenSecret, config.Config.SignatureKey = "", ""
+	if err := checkout.Validate(); !errors.Is(err, errCheckout.ErrCheckoutSecretMissing) {
+		t.Errorf("Validate error = %v, want %v", err, errCheckout.ErrCheckoutSecretMissing)
+	}
+	if _, err := service.GetStatus(context.Background(), id, valid); !errors.Is(err, errCheckout.ErrCheckoutTokenInvalid) {
+		t.Errorf("GetStatus error = %v, want %v", err, errCheckout.ErrCheckoutTokenInvalid)
+	}
+}
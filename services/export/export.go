@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"payment-service/common/export"
+	"payment-service/common/fx"
+	"payment-service/common/gcs"
+	"payment-service/common/money"
+	"payment-service/config"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories"
+	"strconv"
+	"time"
+)
+
+type IExportService interface {
+	IsAsync(context.Context, *dto.PaymentExportParam) (bool, error)
+	Export(context.Context, *dto.PaymentExportParam, io.Writer) error
+	CreateAsync(context.Context, *dto.PaymentExportParam) (*dto.PaymentExportResponse, error)
+	GetByUUID(context.Context, string) (*dto.PaymentExportResponse, error)
+}
+
+func NewExportService(repository repositories.IRepositoryRegistry, gcs gcs.IGSClient) IExportService {
+	return &ExportService{
+		repository: repository,
+		gcs:        gcs,
+	}
+}
+
+type ExportService struct {
+	repository repositories.IRepositoryRegistry
+	gcs        gcs.IGSClient
+}
+
+var exportHeader = []string{
+	"Payment ID",
+	"Order ID",
+	"Amount",
+	"Fee",
+	"Net Amount",
+	"Currency",
+	"FX Rate",
+	"Reporting Amount",
+	"Status",
+	"Bank",
+	"VA Number",
+	"Acquirer",
+	"Transaction ID",
+	"Description",
+	"Paid At",
+	"Expired At",
+	"Created At",
+}
+
+func (e *ExportService) IsAsync(ctx context.Context, param *dto.PaymentExportParam) (bool, error) {
+	if param.Async {
+		return true, nil
+	}
+	threshold := config.Config.Export.AsyncRowThreshold
+	if threshold <= 0 {
+		return false, nil
+	}
+	total, err := e.repository.GetPayment().CountByFilter(ctx, &param.PaymentFilter)
+	if err != nil {
+		return false, err
+	}
+	return total > threshold, nil
+}
+
+func (e *ExportService) Export(ctx context.Context, param *dto.PaymentExportParam, writer io.Writer) error {
+	_, err := e.write(ctx, param, writer)
+	return err
+}
+
+func (e *ExportService) CreateAsync(ctx context.Context, param *dto.PaymentExportParam) (*dto.PaymentExportResponse, error) {
+	paymentExport, err := e.repository.GetPaymentExport().Create(ctx, e.repository.GetTx(), param)
+	if err != nil {
+		return nil, err
+	}
+	go e.runAsync(context.Background(), paymentExport, param)
+	return e.toResponse(paymentExport), nil
+}
+
+func (e *ExportService) GetByUUID(ctx context.Context, uuid string) (*dto.PaymentExportResponse, error) {
+	paymentExport, err := e.repository.GetPaymentExport().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	return e.toResponse(paymentExport), nil
+}
+
+func (e *ExportService) runAsync(ctx context.Context, paymentExport *models.PaymentExport, param *dto.PaymentExportParam) {
+	rowCount, link, err := e.writeToStorage(ctx, paymentExport, param)
+	now := time.Now()
+	update := &dto.UpdatePaymentExportRequest{
+		Status:       dto.PaymentExportCompleted,
+		RowCount:     rowCount,
+		DownloadLink: &link,
+		CompletedAt:  &now,
+	}
+	if err != nil {
+		logrus.Errorf("failed to export payments %s: %v", paymentExport.UUID, err)
+		message := err.Error()
+		update.Status = dto.PaymentExportFailed
+		update.DownloadLink = nil
+		update.Error = &message
+	}
+	err = e.repository.GetPaymentExport().Update(ctx, e.repository.GetTx(), paymentExport.ID, update)
+	if err != nil {
+		logrus.Errorf("failed to update payment export %s: %v", paymentExport.UUID, err)
+	}
+}
+
+func (e *ExportService) writeToStorage(
+	ctx context.Context,
+	paymentExport *models.PaymentExport,
+	param *dto.PaymentExportParam,
+) (int, string, error) {
+	file, err := os.CreateTemp("", "payment-export-*")
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	rowCount, err := e.write(ctx, param, file)
+	if err != nil {
+		return 0, "", err
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, "", err
+	}
+	filename := fmt.Sprintf("exports/payments-%s.%s", paymentExport.UUID, paymentExport.Format)
+	link, err := e.gcs.UploadReader(ctx, filename, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return rowCount, link, nil
+}
+
+func (e *ExportService) write(ctx context.Context, param *dto.PaymentExportParam, writer io.Writer) (int, error) {
+	exportWriter, err := export.NewWriter(param.Format, writer)
+	if err != nil {
+		return 0, err
+	}
+	err = exportWriter.Write(exportHeader)
+	if err != nil {
+		return 0, err
+	}
+
+	rowCount := 0
+	err = e.repository.GetPayment().StreamByFilter(ctx, &param.PaymentFilter, func(payment *models.Payment) error {
+		rowCount++
+		return exportWriter.Write(e.toRecord(payment))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rowCount, exportWriter.Close()
+}
+
+func (e *ExportService) toRecord(payment *models.Payment) []string {
+	return []string{
+		payment.UUID.String(),
+		payment.OrderID.String(),
+		e.amount(&payment.Amount, payment.Currency),
+		e.amount(payment.Fee, payment.Currency),
+		e.amount(payment.NetAmount, payment.Currency),
+		payment.Currency,
+		floatValue(payment.FXRate),
+		e.amount(payment.ReportingAmount, fx.ReportingCurrency()),
+		payment.Status.GetStatusString().String(),
+		stringValue(payment.Bank),
+		stringValue(payment.VANumber),
+		stringValue(payment.Acquirer),
+		stringValue(payment.TransactionID),
+		stringValue(payment.Description),
+		timeValue(payment.PaidAt),
+		timeValue(payment.ExpiredAt),
+		timeValue(payment.CreatedAt),
+	}
+}
+
+// amount leaves the cell empty when the amount is not known yet.
+func (e *ExportService) amount(value *int64, currency string) string {
+	if value == nil {
+		return ""
+	}
+	amount := money.New(*value, currency)
+	if config.Config.Export.FormatAmount {
+		return amount.Format()
+	}
+	return amount.String()
+}
+
+func (e *ExportService) toResponse(paymentExport *models.PaymentExport) *dto.PaymentExportResponse {
+	return &dto.PaymentExportResponse{
+		UUID:         paymentExport.UUID,
+		Format:       paymentExport.Format,
+		Status:       dto.PaymentExportStatus(paymentExport.Status),
+		RowCount:     paymentExport.RowCount,
+		DownloadLink: paymentExport.DownloadLink,
+		Error:        paymentExport.Error,
+		CompletedAt:  paymentExport.CompletedAt,
+		CreatedAt:    paymentExport.CreatedAt,
+	}
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func floatValue(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+func timeValue(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.DateTime)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	"github.com/google/uuid"
+	"payment-service/config"
+	"payment-service/constants"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories/fake"
+	repositories "payment-service/repositories/payment"
+)
+
+type fakePaymentRepository struct {
+	repositories.IPaymentRepository
+	payments []models.Payment
+	total    int64
+}
+
+func (f *fakePaymentRepository) CountByFilter(context.Context, *dto.PaymentFilter) (int64, error) {
+	return f.total, nil
+}
+
+func (f *fakePaymentRepository) StreamByFilter(
+	_ context.Context,
+	_ *dto.PaymentFilter,
+	fn func(*models.Payment) error,
+) error {
+	for i := range f.payments {
+		if err := fn(&f.payments[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExportCSV(t *testing.T) {
+	status := constants.Settlement
+	bank := "bca"
+	payment := models.Payment{UUID: uuid.New(), OrderID: uuid.New(), Amount: 15000000, Currency: "IDR", Status: &status, Bank: &bank}
+	service := &ExportService{repository: &fake.Registry{
+		Payment: &fakePaymentRepository{payments: []models.Payment{payment}},
+	}}
+
+	var buffer bytes.Buffer
+	err := service.Export(context.Background(), &dto.PaymentExportParam{Format: "csv"}, &buffer)
+	if err != nil {
+		t.Fatalf("Export unexpected error: %v", err)
+	}
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatalf("exported file is not valid csv: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("exported %d records, want the header and one row", len(records))
+	}
+
+	row := map[string]string{}
+	for i, column := range records[0] {
+		row[column] = records[1][i]
+	}
+	if row["Payment ID"] != payment.UUID.String() || row["Order ID"] != payment.OrderID.String() {
+		t.Errorf("row ids = %s and %s, want the payment and order ids", row["Payment ID"], row["Order ID"])
+	}
+	if row["Amount"] != "150000.00" || row["Status"] != constants.SettlementString.String() || row["Bank"] != bank {
+		t.Errorf("row = %v, want 150000.00 settled through bca", row)
+	}
+	// Optional fields are written as empty cells.
+	if row["VA Number"] != "" || row["Paid At"] != "" {
+		t.Errorf("row = %v, want empty va number and paid at", row)
+	}
+}
+
+func TestExportRejectsUnknownFormat(t *testing.T) {
+	service := &ExportService{repository: &fake.Registry{Payment: &fakePaymentRepository{}}}
+	err := service.Export(context.Background(), &dto.PaymentExportParam{Format: "pdf"}, &bytes.Buffer{})
+	if err == nil {
+		t.Error("Export error = nil, want an unsupported format error")
+	}
+}
+
+func TestIsAsync(t *testing.T) {
+	threshold := config.Config.Export.AsyncRowThreshold
+	defer func() { config.Config.Export.AsyncRowThreshold = threshold }()
+	config.Config.Export.AsyncRowThreshold = 1000
+
+	tests := []struct {
+		name  string
+		param dto.PaymentExportParam
+		total int64
+		want  bool
+	}{
+		{name: "below threshold", total: 1000, want: false},
+		{name: "above threshold", total: 1001, want: true},
+		{name: "requested async", param: dto.PaymentExportParam{Async: true}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &ExportService{repository: &fake.Registry{Payment: &fakePaymentRepository{total: tt.total}}}
+			got, err := service.IsAsync(context.Background(), &tt.param)
+			if err != nil {
+				t.Fatalf("IsAsync unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsAsync = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"payment-service/config"
+	errIdempotency "payment-service/constants/error/idempotency"
+	"payment-service/domain/dto"
+	"payment-service/repositories"
+	"time"
+)
+
+const defaultIdempotencyTTL = 24 * time.Hour
+
+type IIdempotencyService interface {
+	Begin(context.Context, *dto.IdempotencyRequest) (*dto.IdempotencyResult, error)
+	Complete(context.Context, *dto.IdempotencyRequest, *dto.IdempotencyResponse) error
+	Release(context.Context, *dto.IdempotencyRequest) error
+	DeleteExpired(context.Context) (int64, error)
+}
+
+func NewIdempotencyService(repository repositories.IRepositoryRegistry) IIdempotencyService {
+	return &IdempotencyService{repository: repository}
+}
+
+type IdempotencyService struct {
+	repository repositories.IRepositoryRegistry
+}
+
+func (i *IdempotencyService) ttl() time.Duration {
+	if config.Config.Idempotency.TTLInHours <= 0 {
+		return defaultIdempotencyTTL
+	}
+	return time.Duration(config.Config.Idempotency.TTLInHours) * time.Hour
+}
+
+// Begin reserves the key for the request. A nil Response means the caller owns
+// the key and must either Complete or Release it once the handler finishes.
+func (i *IdempotencyService) Begin(ctx context.Context, request *dto.IdempotencyRequest) (*dto.IdempotencyResult, error) {
+	if len(request.Key) > 255 {
+		return nil, errIdempotency.ErrIdempotencyKeyTooLong
+	}
+	repository := i.repository.GetIdempotencyKey()
+	now := time.Now()
+	created, err := repository.CreateIfNotExists(ctx, i.repository.GetTx(), request, now.Add(i.ttl()))
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		return &dto.IdempotencyResult{}, nil
+	}
+
+	existing, err := repository.FindByScopeAndKey(ctx, request.Scope, request.Key)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil || existing.ExpiredAt.Before(now) {
+		if existing != nil {
+			err = repository.Delete(ctx, i.repository.GetTx(), request)
+			if err != nil {
+				return nil, err
+			}
+		}
+		created, err = repository.CreateIfNotExists(ctx, i.repository.GetTx(), request, now.Add(i.ttl()))
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			return nil, errIdempotency.ErrIdempotencyKeyInProgress
+		}
+		return &dto.IdempotencyResult{}, nil
+	}
+
+	if existing.RequestHash != request.RequestHash {
+		return nil, errIdempotency.ErrIdempotencyKeyMismatch
+	}
+	if existing.StatusCode == nil {
+		return nil, errIdempotency.ErrIdempotencyKeyInProgress
+	}
+
+	var body []byte
+	if existing.ResponseBody != nil {
+		body = []byte(*existing.ResponseBody)
+	}
+	return &dto.IdempotencyResult{
+		Replay: true,
+		Response: &dto.IdempotencyResponse{
+			StatusCode: *existing.StatusCode,
+			Body:       body,
+		},
+	}, nil
+}
+
+func (i *IdempotencyService) Complete(
+	ctx context.Context,
+	request *dto.IdempotencyRequest,
+	response *dto.IdempotencyResponse,
+) error {
+	return i.repository.GetIdempotencyKey().UpdateResponse(ctx, i.repository.GetTx(), request, response)
+}
+
+func (i *IdempotencyService) Release(ctx context.Context, request *dto.IdempotencyRequest) error {
+	return i.repository.GetIdempotencyKey().Delete(ctx, i.repository.GetTx(), request)
+}
+
+func (i *IdempotencyService) DeleteExpired(ctx context.Context) (int64, error) {
+	return i.repository.GetIdempotencyKey().DeleteExpired(ctx, i.repository.GetTx(), time.Now())
+}
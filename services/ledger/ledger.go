@@ -0,0 +1,511 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"payment-service/common/money"
+	"payment-service/constants"
+	errLedger "payment-service/constants/error/ledger"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories"
+	"sort"
+	"time"
+)
+
+type ILedgerService interface {
+	Post(context.Context, *gorm.DB, *dto.JournalEntryRequest) error
+	PostSettlement(context.Context, *gorm.DB, *models.Payment, money.Money) error
+	PostRefund(context.Context, *gorm.DB, *models.Payment, money.Money) error
+	PostGatewayFee(context.Context, *gorm.DB, *models.Payment, int64) error
+	GetMerchantBalances(context.Context, *string) ([]dto.MerchantBalanceResponse, error)
+	GetTrialBalance(context.Context, *dto.TrialBalanceParam) (*dto.TrialBalanceResponse, error)
+	GetAccountStatement(context.Context, string, *dto.AccountStatementParam) (*dto.AccountStatementResponse, error)
+}
+
+func NewLedgerService(repository repositories.IRepositoryRegistry) ILedgerService {
+	return &LedgerService{repository: repository}
+}
+
+type LedgerService struct {
+	repository repositories.IRepositoryRegistry
+}
+
+func GatewayReceivableAccount(provider string) dto.LedgerAccountRequest {
+	return dto.LedgerAccountRequest{
+		Code: fmt.Sprintf("%s:%s", constants.AccountGatewayReceivable, provider),
+		Name: fmt.Sprintf("Receivable from %s", provider),
+		Type: constants.AccountAsset,
+	}
+}
+
+func MerchantPayableAccount(merchantID string) dto.LedgerAccountRequest {
+	return dto.LedgerAccountRequest{
+		Code:       fmt.Sprintf("%s:%s", constants.AccountMerchantPayable, merchantID),
+		Name:       fmt.Sprintf("Payable to merchant %s", merchantID),
+		Type:       constants.AccountLiability,
+		MerchantID: &merchantID,
+	}
+}
+
+func PlatformRevenueAccount() dto.LedgerAccountRequest {
+	return dto.LedgerAccountRequest{
+		Code: constants.AccountPlatformRevenue,
+		Name: "Platform revenue",
+		Type: constants.AccountRevenue,
+	}
+}
+
+func FeeRevenueAccount() dto.LedgerAccountRequest {
+	return dto.LedgerAccountRequest{
+		Code: constants.AccountFeeRevenue,
+		Name: "Fee revenue",
+		Type: constants.AccountRevenue,
+	}
+}
+
+func GatewayFeeAccount() dto.LedgerAccountRequest {
+	return dto.LedgerAccountRequest{
+		Code: constants.AccountGatewayFee,
+		Name: "Gateway fees",
+		Type: constants.AccountExpense,
+	}
+}
+
+func PayoutClearingAccount() dto.LedgerAccountRequest {
+	return dto.LedgerAccountRequest{
+		Code: constants.AccountPayoutClearing,
+		Name: "Payouts in transit",
+		Type: constants.AccountLiability,
+	}
+}
+
+// Post writes a balanced journal entry in the transaction of the caller. An
+// entry already posted under the same reference is left as it is, so the
+// event it records may be replayed safely.
+func (l *LedgerService) Post(ctx context.Context, tx *gorm.DB, request *dto.JournalEntryRequest) error {
+	var debit, credit int64
+	for _, line := range request.Lines {
+		if line.Debit < 0 || line.Credit < 0 || (line.Debit > 0) == (line.Credit > 0) {
+			return errLedger.ErrEntryLineInvalid
+		}
+		debit += line.Debit
+		credit += line.Credit
+	}
+	if len(request.Lines) < 2 || debit != credit {
+		logrus.Errorf("journal entry %s is unbalanced: debit %d, credit %d", request.Reference, debit, credit)
+		return errLedger.ErrEntryUnbalanced
+	}
+
+	entry := &models.JournalEntry{
+		UUID:          uuid.New(),
+		Reference:     request.Reference,
+		Kind:          request.Kind,
+		PaymentID:     request.PaymentID,
+		PayoutBatchID: request.PayoutBatchID,
+		Currency:      request.Currency,
+		Description:   request.Description,
+		PostedAt:      request.PostedAt,
+	}
+	for _, line := range request.Lines {
+		accountID := line.AccountID
+		if accountID == 0 {
+			account := line.Account
+			account.Currency = request.Currency
+			found, err := l.repository.GetLedger().FindOrCreateAccount(ctx, tx, &account)
+			if err != nil {
+				return err
+			}
+			accountID = found.ID
+		}
+		entry.Postings = append(entry.Postings, models.LedgerPosting{
+			AccountID: accountID,
+			Debit:     line.Debit,
+			Credit:    line.Credit,
+		})
+	}
+	created, err := l.repository.GetLedger().CreateEntry(ctx, tx, entry)
+	if err != nil {
+		return err
+	}
+	if !created {
+		logrus.Infof("journal entry %s has already been posted", request.Reference)
+	}
+	return nil
+}
+
+// PostSettlement moves the settled amount of a payment from the gateway to
+// the merchants it is split with and the platform. The amount is shared in the
+// ratio of the splits, so a capture for less than the amount shrinks every
+// share alike. The fees of the splits are posted as an entry of their own.
+func (l *LedgerService) PostSettlement(ctx context.Context, tx *gorm.DB, payment *models.Payment, amount money.Money) error {
+	splits, err := l.repository.GetPaymentSplit().FindByPaymentID(ctx, tx, payment.ID)
+	if err != nil {
+		return err
+	}
+
+	ratios := make([]int64, 0, len(splits)+1)
+	platformShare := payment.Amount
+	for _, split := range splits {
+		ratios = append(ratios, split.Amount)
+		platformShare -= split.Amount
+	}
+	ratios = append(ratios, platformShare)
+	shares := amount.Allocate(ratios...)
+
+	lines := []dto.JournalLineRequest{{
+		Account: GatewayReceivableAccount(payment.Provider),
+		Debit:   amount.Amount,
+	}}
+	var fees []dto.JournalLineRequest
+	var feeTotal int64
+	for i, split := range splits {
+		if shares[i].Amount <= 0 {
+			continue
+		}
+		account := MerchantPayableAccount(split.MerchantID)
+		lines = append(lines, dto.JournalLineRequest{
+			Account: account,
+			Credit:  shares[i].Amount,
+		})
+		if fee := min(split.Fee, shares[i].Amount); fee > 0 {
+			fees = append(fees, dto.JournalLineRequest{Account: account, Debit: fee})
+			feeTotal += fee
+		}
+	}
+	if revenue := shares[len(splits)].Amount; revenue > 0 {
+		lines = append(lines, dto.JournalLineRequest{
+			Account: PlatformRevenueAccount(),
+			Credit:  revenue,
+		})
+	}
+
+	postedAt := time.Now()
+	if payment.PaidAt != nil {
+		postedAt = *payment.PaidAt
+	}
+	description := fmt.Sprintf("settlement of payment %s", payment.UUID)
+	err = l.Post(ctx, tx, &dto.JournalEntryRequest{
+		Reference:   l.settlementReference(payment),
+		Kind:        constants.JournalSettlement,
+		PaymentID:   &payment.ID,
+		Currency:    payment.Currency,
+		Description: &description,
+		PostedAt:    postedAt,
+		Lines:       lines,
+	})
+	if err != nil || feeTotal == 0 {
+		return err
+	}
+
+	description = fmt.Sprintf("fees of payment %s", payment.UUID)
+	return l.Post(ctx, tx, &dto.JournalEntryRequest{
+		Reference:   l.feeReference(payment),
+		Kind:        constants.JournalFee,
+		PaymentID:   &payment.ID,
+		Currency:    payment.Currency,
+		Description: &description,
+		PostedAt:    postedAt,
+		Lines: append(fees, dto.JournalLineRequest{
+			Account: FeeRevenueAccount(),
+			Credit:  feeTotal,
+		}),
+	})
+}
+
+// PostRefund reverses the settlement and fee entries of the payment in
+// proportion to the refunded amount. RefundAmount of the payment is the total
+// refunded so far, it keeps the reference of each refund unique and lets every
+// refund reverse up to the running total, so a full refund leaves nothing.
+func (l *LedgerService) PostRefund(ctx context.Context, tx *gorm.DB, payment *models.Payment, refund money.Money) error {
+	settlement, err := l.repository.GetLedger().FindEntryByReference(ctx, tx, l.settlementReference(payment))
+	if err != nil {
+		return err
+	}
+	if settlement == nil || refund.Amount <= 0 {
+		return nil
+	}
+	settled := l.entryAmount(settlement)
+	refunded := min(payment.RefundAmount, settled)
+	previous := max(refunded-refund.Amount, 0)
+	if refunded <= previous {
+		return nil
+	}
+
+	reference := fmt.Sprintf("payment:%s:refund:%d", payment.UUID, payment.RefundAmount)
+	description := fmt.Sprintf("refund of %s on payment %s", refund.String(), payment.UUID)
+	err = l.Post(ctx, tx, &dto.JournalEntryRequest{
+		Reference:   reference,
+		Kind:        constants.JournalRefund,
+		PaymentID:   &payment.ID,
+		Currency:    settlement.Currency,
+		Description: &description,
+		PostedAt:    time.Now(),
+		Lines:       l.reversal(settlement, previous, refunded),
+	})
+	if err != nil {
+		return err
+	}
+
+	fee, err := l.repository.GetLedger().FindEntryByReference(ctx, tx, l.feeReference(payment))
+	if err != nil || fee == nil {
+		return err
+	}
+	// The share of the fees follows the share of the settlement refunded.
+	feeAmount := money.New(l.entryAmount(fee), fee.Currency)
+	lines := l.reversal(
+		fee,
+		feeAmount.Allocate(previous, settled-previous)[0].Amount,
+		feeAmount.Allocate(refunded, settled-refunded)[0].Amount,
+	)
+	if len(lines) == 0 {
+		return nil
+	}
+	description = fmt.Sprintf("fees returned on the refund of payment %s", payment.UUID)
+	return l.Post(ctx, tx, &dto.JournalEntryRequest{
+		Reference:   reference + ":fee",
+		Kind:        constants.JournalRefund,
+		PaymentID:   &payment.ID,
+		Currency:    fee.Currency,
+		Description: &description,
+		PostedAt:    time.Now(),
+		Lines:       lines,
+	})
+}
+
+// PostGatewayFee books the fee the gateway deducts from the settled amount of
+// a payment. When the fee was booked before as previous, only the difference
+// is posted.
+func (l *LedgerService) PostGatewayFee(ctx context.Context, tx *gorm.DB, payment *models.Payment, previous int64) error {
+	if payment.Fee == nil || *payment.Fee == previous {
+		return nil
+	}
+	expense := dto.JournalLineRequest{Account: GatewayFeeAccount()}
+	receivable := dto.JournalLineRequest{Account: GatewayReceivableAccount(payment.Provider)}
+	if delta := *payment.Fee - previous; delta > 0 {
+		expense.Debit, receivable.Credit = delta, delta
+	} else {
+		expense.Credit, receivable.Debit = -delta, -delta
+	}
+
+	description := fmt.Sprintf("gateway fee of payment %s", payment.UUID)
+	return l.Post(ctx, tx, &dto.JournalEntryRequest{
+		Reference:   fmt.Sprintf("payment:%s:gateway_fee:%d:%d", payment.UUID, previous, *payment.Fee),
+		Kind:        constants.JournalGatewayFee,
+		PaymentID:   &payment.ID,
+		Currency:    payment.Currency,
+		Description: &description,
+		PostedAt:    time.Now(),
+		Lines:       []dto.JournalLineRequest{expense, receivable},
+	})
+}
+
+// reversal returns the lines that take the reversed part of an entry from
+// previous to current. Each side of the entry is shared out on its own, so
+// every posting is reversed in its share of the entry.
+func (l *LedgerService) reversal(entry *models.JournalEntry, previous, current int64) []dto.JournalLineRequest {
+	var debits, credits []models.LedgerPosting
+	var debitRatios, creditRatios []int64
+	for _, posting := range entry.Postings {
+		if posting.Debit > 0 {
+			debits = append(debits, posting)
+			debitRatios = append(debitRatios, posting.Debit)
+		} else {
+			credits = append(credits, posting)
+			creditRatios = append(creditRatios, posting.Credit)
+		}
+	}
+
+	var lines []dto.JournalLineRequest
+	reverse := func(postings []models.LedgerPosting, ratios []int64, debit bool) {
+		before := money.New(previous, entry.Currency).Allocate(ratios...)
+		after := money.New(current, entry.Currency).Allocate(ratios...)
+		for i, posting := range postings {
+			amount := after[i].Amount - before[i].Amount
+			if amount == 0 {
+				continue
+			}
+			// Rounding may take a posting back by a unit, that goes the other way.
+			line := dto.JournalLineRequest{AccountID: posting.AccountID}
+			if (amount > 0) == debit {
+				line.Debit = abs(amount)
+			} else {
+				line.Credit = abs(amount)
+			}
+			lines = append(lines, line)
+		}
+	}
+	reverse(credits, creditRatios, true)
+	reverse(debits, debitRatios, false)
+	return lines
+}
+
+func abs(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
+
+func (l *LedgerService) entryAmount(entry *models.JournalEntry) int64 {
+	var amount int64
+	for _, posting := range entry.Postings {
+		amount += posting.Debit
+	}
+	return amount
+}
+
+func (l *LedgerService) GetMerchantBalances(ctx context.Context, merchantID *string) ([]dto.MerchantBalanceResponse, error) {
+	prefix := constants.AccountMerchantPayable + ":"
+	balances, err := l.repository.GetLedger().Balances(ctx, l.repository.GetTx(), &dto.LedgerBalanceFilter{
+		CodePrefix: &prefix,
+		MerchantID: merchantID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]dto.MerchantBalanceResponse, 0, len(balances))
+	for _, balance := range balances {
+		results = append(results, dto.MerchantBalanceResponse{
+			MerchantID: *balance.MerchantID,
+			Balance:    money.New(balance.Credit-balance.Debit, balance.Currency).Major(),
+			Currency:   balance.Currency,
+		})
+	}
+	return results, nil
+}
+
+// GetTrialBalance sums up every account as of the end of the given date, or
+// of everything posted so far. Debits and credits are totalled per currency,
+// a total that does not balance means an entry was written around the ledger.
+func (l *LedgerService) GetTrialBalance(ctx context.Context, param *dto.TrialBalanceParam) (*dto.TrialBalanceResponse, error) {
+	filter := &dto.LedgerBalanceFilter{Currency: param.Currency}
+	if param.Date != nil {
+		postedBefore := param.Date.AddDate(0, 0, 1)
+		filter.PostedBefore = &postedBefore
+	}
+	balances, err := l.repository.GetLedger().Balances(ctx, l.repository.GetTx(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]dto.TrialBalanceLine, 0, len(balances))
+	debits := make(map[string]int64)
+	credits := make(map[string]int64)
+	for _, balance := range balances {
+		accounts = append(accounts, dto.TrialBalanceLine{
+			AccountUUID: balance.UUID,
+			Code:        balance.Code,
+			Name:        balance.Name,
+			Type:        balance.Type,
+			Currency:    balance.Currency,
+			Debit:       money.New(balance.Debit, balance.Currency).Major(),
+			Credit:      money.New(balance.Credit, balance.Currency).Major(),
+			Balance:     money.New(l.balance(balance.Type, balance.Debit, balance.Credit), balance.Currency).Major(),
+		})
+		debits[balance.Currency] += balance.Debit
+		credits[balance.Currency] += balance.Credit
+	}
+
+	currencies := make([]string, 0, len(debits))
+	for currency := range debits {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	totals := make([]dto.TrialBalanceTotal, 0, len(currencies))
+	for _, currency := range currencies {
+		balanced := debits[currency] == credits[currency]
+		if !balanced {
+			logrus.Errorf("ledger is out of balance in %s: debit %d, credit %d", currency, debits[currency], credits[currency])
+		}
+		totals = append(totals, dto.TrialBalanceTotal{
+			Currency: currency,
+			Debit:    money.New(debits[currency], currency).Major(),
+			Credit:   money.New(credits[currency], currency).Major(),
+			Balanced: balanced,
+		})
+	}
+	return &dto.TrialBalanceResponse{
+		Date:     param.Date,
+		Accounts: accounts,
+		Totals:   totals,
+	}, nil
+}
+
+// GetAccountStatement lists what was posted to an account between both dates
+// inclusive, with the balance before the first and after every posting.
+func (l *LedgerService) GetAccountStatement(
+	ctx context.Context,
+	uuid string,
+	param *dto.AccountStatementParam,
+) (*dto.AccountStatementResponse, error) {
+	if param.EndDate.Before(param.StartDate) {
+		return nil, errLedger.ErrStatementRange
+	}
+	account, err := l.repository.GetLedger().FindAccountByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	opening, err := l.repository.GetLedger().Balances(ctx, l.repository.GetTx(), &dto.LedgerBalanceFilter{
+		AccountID:    &account.ID,
+		PostedBefore: &param.StartDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := l.repository.GetLedger().FindPostings(ctx, account.ID, param.StartDate, param.EndDate.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	var balance int64
+	for _, row := range opening {
+		balance = l.balance(account.Type, row.Debit, row.Credit)
+	}
+	openingBalance := balance
+	lines := make([]dto.AccountStatementLine, 0, len(rows))
+	for _, row := range rows {
+		balance += l.balance(account.Type, row.Debit, row.Credit)
+		lines = append(lines, dto.AccountStatementLine{
+			EntryUUID:   row.EntryUUID,
+			Reference:   row.Reference,
+			Kind:        row.Kind,
+			Description: row.Description,
+			PostedAt:    row.PostedAt,
+			Debit:       money.New(row.Debit, account.Currency).Major(),
+			Credit:      money.New(row.Credit, account.Currency).Major(),
+			Balance:     money.New(balance, account.Currency).Major(),
+		})
+	}
+	return &dto.AccountStatementResponse{
+		AccountUUID:    account.UUID,
+		Code:           account.Code,
+		Name:           account.Name,
+		Type:           account.Type,
+		Currency:       account.Currency,
+		StartDate:      param.StartDate,
+		EndDate:        param.EndDate,
+		OpeningBalance: money.New(openingBalance, account.Currency).Major(),
+		ClosingBalance: money.New(balance, account.Currency).Major(),
+		Lines:          lines,
+	}, nil
+}
+
+// balance is signed so that it grows on the normal side of the account.
+func (l *LedgerService) balance(accountType constants.LedgerAccountType, debit, credit int64) int64 {
+	if accountType.IsDebitNormal() {
+		return debit - credit
+	}
+	return credit - debit
+}
+
+func (l *LedgerService) settlementReference(payment *models.Payment) string {
+	return fmt.Sprintf("payment:%s:settlement", payment.UUID)
+}
+
+func (l *LedgerService) feeReference(payment *models.Payment) string {
+	return fmt.Sprintf("payment:%s:fee", payment.UUID)
+}
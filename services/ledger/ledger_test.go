@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"payment-service/common/money"
+	"payment-service/constants"
+	errLedger "payment-service/constants/error/ledger"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories/fake"
+	repositories "payment-service/repositories/ledger"
+	repositories2 "payment-service/repositories/payment_split"
+)
+
+// fakeLedgerRepository keeps accounts and entries in memory and, like the
+// real one, stores an entry only once per reference.
+type fakeLedgerRepository struct {
+	repositories.ILedgerRepository
+	accounts map[string]uint
+	entries  []*models.JournalEntry
+}
+
+func (f *fakeLedgerRepository) FindOrCreateAccount(
+	_ context.Context,
+	_ *gorm.DB,
+	account *dto.LedgerAccountRequest,
+) (*models.LedgerAccount, error) {
+	id, ok := f.accounts[account.Code]
+	if !ok {
+		id = uint(len(f.accounts) + 1)
+		f.accounts[account.Code] = id
+	}
+	return &models.LedgerAccount{ID: id, Code: account.Code}, nil
+}
+
+func (f *fakeLedgerRepository) FindEntryByReference(_ context.Context, _ *gorm.DB, reference string) (*models.JournalEntry, error) {
+	for _, entry := range f.entries {
+		if entry.Reference == reference {
+			return entry, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeLedgerRepository) CreateEntry(_ context.Context, _ *gorm.DB, entry *models.JournalEntry) (bool, error) {
+	for _, existing := range f.entries {
+		if existing.Reference == entry.Reference {
+			return false, nil
+		}
+	}
+	f.entries = append(f.entries, entry)
+	return true, nil
+}
+
+// net returns what the postings of the entries moved on the account, debits
+// less credits.
+func (f *fakeLedgerRepository) net(code string, entries ...*models.JournalEntry) int64 {
+	var total int64
+	for _, entry := range entries {
+		for _, posting := range entry.Postings {
+			if posting.AccountID == f.accounts[code] {
+				total += posting.Debit - posting.Credit
+			}
+		}
+	}
+	return total
+}
+
+type fakePaymentSplitRepository struct {
+	repositories2.IPaymentSplitRepository
+	splits []models.PaymentSplit
+}
+
+func (f *fakePaymentSplitRepository) FindByPaymentID(context.Context, *gorm.DB, uint) ([]models.PaymentSplit, error) {
+	return f.splits, nil
+}
+
+func newTestLedger(splits ...models.PaymentSplit) (*LedgerService, *fakeLedgerRepository) {
+	ledger := &fakeLedgerRepository{accounts: map[string]uint{}}
+	return &LedgerService{repository: &fake.Registry{
+		Ledger:       ledger,
+		PaymentSplit: &fakePaymentSplitRepository{splits: splits},
+	}}, ledger
+}
+
+func TestPostRejectsInvalidEntries(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []dto.JournalLineRequest
+		want  error
+	}{
+		{
+			name: "unbalanced",
+			lines: []dto.JournalLineRequest{
+				{AccountID: 1, Debit: 1000},
+				{AccountID: 2, Credit: 900},
+			},
+			want: errLedger.ErrEntryUnbalanced,
+		},
+		{
+			name:  "single line",
+			lines: []dto.JournalLineRequest{{AccountID: 1, Debit: 1000}},
+			want:  errLedger.ErrEntryUnbalanced,
+		},
+		{
+			name: "line on both sides",
+			lines: []dto.JournalLineRequest{
+				{AccountID: 1, Debit: 1000, Credit: 1000},
+				{AccountID: 2, Credit: 0},
+			},
+			want: errLedger.ErrEntryLineInvalid,
+		},
+		{
+			name: "negative amount",
+			lines: []dto.JournalLineRequest{
+				{AccountID: 1, Debit: -1000},
+				{AccountID: 2, Credit: -1000},
+			},
+			want: errLedger.ErrEntryLineInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger, repository := newTestLedger()
+			err := ledger.Post(context.Background(), nil, &dto.JournalEntryRequest{
+				Reference: "test:" + tt.name,
+				Currency:  "IDR",
+				PostedAt:  time.Now(),
+				Lines:     tt.lines,
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Post error = %v, want %v", err, tt.want)
+			}
+			if len(repository.entries) != 0 {
+				t.Errorf("Post stored %d entries, want none", len(repository.entries))
+			}
+		})
+	}
+}
+
+func TestPostBalancedEntryOnce(t *testing.T) {
+	ledger, repository := newTestLedger()
+	request := &dto.JournalEntryRequest{
+		Reference: "settlement:1",
+		Currency:  "IDR",
+		PostedAt:  time.Now(),
+		Lines: []dto.JournalLineRequest{
+			{Account: GatewayReceivableAccount("midtrans"), Debit: 1000000},
+			{Account: MerchantPayableAccount("merchant-1"), Credit: 950000},
+			{Account: PlatformRevenueAccount(), Credit: 50000},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		err := ledger.Post(context.Background(), nil, request)
+		if err != nil {
+			t.Fatalf("Post #%d unexpected error: %v", i+1, err)
+		}
+	}
+	if len(repository.entries) != 1 {
+		t.Fatalf("Post stored %d entries, want 1", len(repository.entries))
+	}
+	if postings := repository.entries[0].Postings; len(postings) != 3 {
+		t.Errorf("entry has %d postings, want 3", len(postings))
+	}
+}
+
+func TestPostSettlementSharesCapturedAmount(t *testing.T) {
+	ledger, repository := newTestLedger(
+		models.PaymentSplit{MerchantID: "merchant-1", Amount: 600000, Fee: 10000},
+		models.PaymentSplit{MerchantID: "merchant-2", Amount: 300000},
+	)
+	payment := &models.Payment{ID: 1, UUID: uuid.New(), Provider: constants.ProviderMidtrans, Amount: 1000000, Currency: "IDR"}
+
+	// Half of the payment is captured, so every share is halved.
+	err := ledger.PostSettlement(context.Background(), nil, payment, money.New(500000, "IDR"))
+	if err != nil {
+		t.Fatalf("PostSettlement unexpected error: %v", err)
+	}
+	if len(repository.entries) != 2 {
+		t.Fatalf("PostSettlement stored %d entries, want the settlement and its fees", len(repository.entries))
+	}
+	tests := []struct {
+		account dto.LedgerAccountRequest
+		want    int64
+	}{
+		{account: GatewayReceivableAccount(constants.ProviderMidtrans), want: 500000},
+		{account: MerchantPayableAccount("merchant-1"), want: -290000},
+		{account: MerchantPayableAccount("merchant-2"), want: -150000},
+		{account: PlatformRevenueAccount(), want: -50000},
+		{account: FeeRevenueAccount(), want: -10000},
+	}
+	for _, tt := range tests {
+		if got := repository.net(tt.account.Code, repository.entries...); got != tt.want {
+			t.Errorf("%s moved by %d, want %d", tt.account.Code, got, tt.want)
+		}
+	}
+}
+
+func TestPostRefundReversesSettlement(t *testing.T) {
+	ledger, repository := newTestLedger(
+		models.PaymentSplit{MerchantID: "merchant-1", Amount: 667},
+		models.PaymentSplit{MerchantID: "merchant-2", Amount: 333},
+	)
+	payment := &models.Payment{ID: 1, UUID: uuid.New(), Provider: constants.ProviderMidtrans, Amount: 1000, Currency: "IDR"}
+	err := ledger.PostSettlement(context.Background(), nil, payment, money.New(1000, "IDR"))
+	if err != nil {
+		t.Fatalf("PostSettlement unexpected error: %v", err)
+	}
+
+	// A refund of 600 and then of the remaining 400 reverses every posting in
+	// full, without a unit lost to rounding.
+	for _, refund := range []int64{600, 400} {
+		payment.RefundAmount += refund
+		err = ledger.PostRefund(context.Background(), nil, payment, money.New(refund, "IDR"))
+		if err != nil {
+			t.Fatalf("PostRefund of %d unexpected error: %v", refund, err)
+		}
+	}
+	if len(repository.entries) != 3 {
+		t.Fatalf("stored %d entries, want the settlement and two refunds", len(repository.entries))
+	}
+	for code := range repository.accounts {
+		if got := repository.net(code, repository.entries...); got != 0 {
+			t.Errorf("%s is left at %d after a full refund, want 0", code, got)
+		}
+	}
+}
+
+func TestBalance(t *testing.T) {
+	ledger, _ := newTestLedger()
+	tests := []struct {
+		accountType constants.LedgerAccountType
+		want        int64
+	}{
+		{accountType: constants.AccountAsset, want: 300},
+		{accountType: constants.AccountExpense, want: 300},
+		{accountType: constants.AccountLiability, want: -300},
+		{accountType: constants.AccountRevenue, want: -300},
+	}
+	for _, tt := range tests {
+		if got := ledger.balance(tt.accountType, 1000, 700); got != tt.want {
+			t.Errorf("balance of %s = %d, want %d", tt.accountType, got, tt.want)
+		}
+	}
+}
@@ -5,41 +5,72 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"math"
 	"math/rand"
 	"os"
-	clients "payment-service/clients/midtrans"
+	clients "payment-service/clients/gateway"
+	"payment-service/common/checkout"
+	"payment-service/common/fx"
 	"payment-service/common/gcs"
+	"payment-service/common/money"
 	"payment-service/common/utils"
 	config2 "payment-service/config"
 	"payment-service/constants"
+	errGateway "payment-service/constants/error/gateway"
+	errLedger "payment-service/constants/error/ledger"
 	errPayment "payment-service/constants/error/payment"
+	errPaymentLink "payment-service/constants/error/payment_link"
+	errVoucher "payment-service/constants/error/voucher"
 	"payment-service/controllers/kafka"
 	"payment-service/domain/dto"
 	"payment-service/domain/models"
 	"payment-service/repositories"
+	services "payment-service/services/ledger"
+	services2 "payment-service/services/voucher"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// defaultVoidAfter voids authorisations a day before issuers usually drop the
+// hold on their own.
+const defaultVoidAfter = 6 * 24 * time.Hour
+
 type IPaymentService interface {
 	GetAllWithPagination(context.Context, *dto.PaymentRequestParam) (*utils.PaginationResult, error)
 	GetByUUID(context.Context, string) (*dto.PaymentResponse, error)
+	GetReviewQueue(context.Context, *dto.PaymentReviewParam) (*utils.PaginationResult, error)
+	Summary(context.Context, *dto.PaymentSummaryParam) (*dto.PaymentSummaryResponse, error)
 	Create(context.Context, *dto.PaymentRequest) (*dto.PaymentResponse, error)
-	Webhook(context.Context, *dto.Webhook) error
+	Charge(context.Context, *dto.ChargeRequest) (*dto.PaymentResponse, error)
+	Webhook(context.Context, string, *dto.WebhookRequest) error
+	ApplyNotification(context.Context, *dto.GatewayNotification) error
+	Capture(context.Context, string, *dto.CapturePaymentRequest) (*dto.PaymentResponse, error)
+	Void(context.Context, string) (*dto.PaymentResponse, error)
+	VoidStaleAuthorizations(context.Context) (int, error)
+	DueAmount(*models.Payment) money.Money
+	OutstandingAmount(*models.Payment) money.Money
 }
 
 func NewPaymentService(
 	repository repositories.IRepositoryRegistry,
 	gcs gcs.IGSClient,
 	kafka kafka.IKafkaRegistry,
-	midtrans clients.IMidTransClient,
+	gateway clients.IGatewayRegistry,
+	fx fx.IRateProvider,
+	ledger services.ILedgerService,
+	voucher services2.IVoucherService,
 ) IPaymentService {
 	return &PaymentService{
 		repository: repository,
 		gcs:        gcs,
 		kafka:      kafka,
-		midtrans:   midtrans,
+		gateway:    gateway,
+		fx:         fx,
+		ledger:     ledger,
+		voucher:    voucher,
 	}
 }
 
@@ -47,7 +78,10 @@ type PaymentService struct {
 	repository repositories.IRepositoryRegistry
 	gcs        gcs.IGSClient
 	kafka      kafka.IKafkaRegistry
-	midtrans   clients.IMidTransClient
+	gateway    clients.IGatewayRegistry
+	fx         fx.IRateProvider
+	ledger     services.ILedgerService
+	voucher    services2.IVoucherService
 }
 
 func (p *PaymentService) GetAllWithPagination(ctx context.Context, param *dto.PaymentRequestParam) (*utils.PaginationResult, error) {
@@ -58,20 +92,43 @@ func (p *PaymentService) GetAllWithPagination(ctx context.Context, param *dto.Pa
 	paymentResults := make([]dto.PaymentResponse, 0, len(payments))
 	for _, payment := range payments {
 		paymentResults = append(paymentResults, dto.PaymentResponse{
-			UUID:          payment.UUID,
-			OrderID:       payment.OrderID,
-			Amount:        payment.Amount,
-			Status:        payment.Status.GetStatusString(),
-			PaymentLink:   payment.PaymentLink,
-			TransactionId: payment.TransactionID,
-			PaidAt:        payment.PaidAt,
-			VANumber:      payment.VANumber,
-			Bank:          payment.Bank,
-			InvoiceLink:   payment.InvoiceLink,
-			Acquirer:      payment.Acquirer,
-			Description:   payment.Description,
-			CreatedAt:     payment.CreatedAt,
-			UpdatedAt:     payment.UpdatedAt,
+			UUID:              payment.UUID,
+			OrderID:           payment.OrderID,
+			Attempt:           payment.Attempt,
+			Provider:          payment.Provider,
+			Amount:            p.amount(&payment).Major(),
+			Currency:          payment.Currency,
+			Discount:          money.New(payment.Discount, payment.Currency).Major(),
+			VoucherCode:       payment.VoucherCode,
+			Tax:               p.tax(&payment),
+			FX:                p.fxRate(&payment),
+			AllowPartial:      payment.AllowPartial,
+			PaidAmount:        p.paidAmount(&payment).Major(),
+			OutstandingAmount: p.OutstandingAmount(&payment).Major(),
+			Fee:               p.optionalAmount(payment.Fee, payment.Currency),
+			NetAmount:         p.optionalAmount(payment.NetAmount, payment.Currency),
+			Status:            payment.Status.GetStatusString(),
+			PaymentLink:       payment.PaymentLink,
+			PaymentMethod:     payment.PaymentMethod,
+			PaymentType:       payment.PaymentType,
+			PaymentChannel:    payment.PaymentChannel,
+			PaymentReference:  payment.PaymentReference,
+			QRString:          payment.QRString,
+			QRImageURL:        payment.QRImageURL,
+			Deeplink:          payment.Deeplink,
+			ExpiredAt:         payment.ExpiredAt,
+			TransactionId:     payment.TransactionID,
+			PaidAt:            payment.PaidAt,
+			VANumber:          payment.VANumber,
+			Bank:              payment.Bank,
+			InvoiceLink:       payment.InvoiceLink,
+			Acquirer:          payment.Acquirer,
+			RoutingRule:       payment.RoutingRule,
+			RoutingReason:     payment.RoutingReason,
+			ReviewReason:      payment.ReviewReason,
+			Description:       payment.Description,
+			CreatedAt:         payment.CreatedAt,
+			UpdatedAt:         payment.UpdatedAt,
 		})
 	}
 
@@ -85,79 +142,935 @@ func (p *PaymentService) GetAllWithPagination(ctx context.Context, param *dto.Pa
 	return &response, nil
 }
 
+// GetReviewQueue lists payments parked in needs_review, oldest first.
+func (p *PaymentService) GetReviewQueue(ctx context.Context, param *dto.PaymentReviewParam) (*utils.PaginationResult, error) {
+	status := constants.NeedsReviewString.String()
+	sortColumn := "updated_at"
+	sortOrder := "asc"
+	return p.GetAllWithPagination(ctx, &dto.PaymentRequestParam{
+		PaymentFilter: dto.PaymentFilter{Status: &status},
+		Page:          param.Page,
+		Limit:         param.Limit,
+		SortColumn:    &sortColumn,
+		SortOrder:     &sortOrder,
+	})
+}
+
 func (p *PaymentService) GetByUUID(ctx context.Context, s string) (*dto.PaymentResponse, error) {
 	payment, err := p.repository.GetPayment().FindByUUID(ctx, s)
 	if err != nil {
 		return nil, err
 	}
+	transactions, err := p.repository.GetPaymentTransaction().FindByPaymentID(ctx, payment.ID)
+	if err != nil {
+		return nil, err
+	}
+	transactionResults := make([]dto.PaymentTransactionResponse, 0, len(transactions))
+	for _, transaction := range transactions {
+		transactionResults = append(transactionResults, dto.PaymentTransactionResponse{
+			UUID:      transaction.UUID,
+			Amount:    money.New(transaction.Amount, transaction.Currency).Major(),
+			Currency:  transaction.Currency,
+			PaidAt:    transaction.PaidAt,
+			CreatedAt: transaction.CreatedAt,
+		})
+	}
+	splits, err := p.repository.GetPaymentSplit().FindByPaymentID(ctx, p.repository.GetTx(), payment.ID)
+	if err != nil {
+		return nil, err
+	}
+	splitResults := make([]dto.PaymentSplitResponse, 0, len(splits))
+	for _, split := range splits {
+		splitResults = append(splitResults, dto.PaymentSplitResponse{
+			UUID:       split.UUID,
+			MerchantID: split.MerchantID,
+			Amount:     money.New(split.Amount, split.Currency).Major(),
+			Percentage: split.Percentage,
+			Fee:        money.New(split.Fee, split.Currency).Major(),
+			Currency:   split.Currency,
+		})
+	}
+	return &dto.PaymentResponse{
+		UUID:              payment.UUID,
+		OrderID:           payment.OrderID,
+		Attempt:           payment.Attempt,
+		Provider:          payment.Provider,
+		Amount:            p.amount(payment).Major(),
+		Currency:          payment.Currency,
+		Discount:          money.New(payment.Discount, payment.Currency).Major(),
+		VoucherCode:       payment.VoucherCode,
+		Tax:               p.tax(payment),
+		FX:                p.fxRate(payment),
+		AllowPartial:      payment.AllowPartial,
+		PaidAmount:        p.paidAmount(payment).Major(),
+		OutstandingAmount: p.OutstandingAmount(payment).Major(),
+		Authorize:         payment.Authorize,
+		CaptureAmount:     p.captureAmount(payment),
+		AuthorizedAt:      payment.AuthorizedAt,
+		RefundAmount:      money.New(payment.RefundAmount, payment.Currency).Major(),
+		Fee:               p.optionalAmount(payment.Fee, payment.Currency),
+		NetAmount:         p.optionalAmount(payment.NetAmount, payment.Currency),
+		FeeSource:         payment.FeeSource,
+		Transactions:      transactionResults,
+		Splits:            splitResults,
+		Status:            payment.Status.GetStatusString(),
+		PaymentLink:       payment.PaymentLink,
+		PaymentMethod:     payment.PaymentMethod,
+		PaymentType:       payment.PaymentType,
+		PaymentChannel:    payment.PaymentChannel,
+		PaymentReference:  payment.PaymentReference,
+		QRString:          payment.QRString,
+		QRImageURL:        payment.QRImageURL,
+		Deeplink:          payment.Deeplink,
+		ExpiredAt:         payment.ExpiredAt,
+		TransactionId:     payment.TransactionID,
+		PaidAt:            payment.PaidAt,
+		VANumber:          payment.VANumber,
+		Bank:              payment.Bank,
+		InvoiceLink:       payment.InvoiceLink,
+		StatusURL:         checkout.StatusURL(payment.UUID.String()),
+		Acquirer:          payment.Acquirer,
+		RoutingRule:       payment.RoutingRule,
+		RoutingReason:     payment.RoutingReason,
+		ReviewReason:      payment.ReviewReason,
+		Description:       payment.Description,
+		CreatedAt:         payment.CreatedAt,
+		UpdatedAt:         payment.UpdatedAt,
+	}, nil
+}
+
+func (p *PaymentService) Summary(ctx context.Context, param *dto.PaymentSummaryParam) (*dto.PaymentSummaryResponse, error) {
+	if param.Period == "" {
+		param.Period = "day"
+	}
+	if param.EndDate.Before(param.StartDate) {
+		return nil, errPayment.ErrSummaryRangeInvalid
+	}
+	query := &dto.PaymentSummaryParam{
+		StartDate: param.StartDate,
+		EndDate:   param.EndDate.AddDate(0, 0, 1),
+		Period:    param.Period,
+	}
+
+	repository := p.repository.GetPayment()
+	total, err := repository.SummaryTotal(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	byStatus, err := repository.SummaryGroupBy(ctx, query, "status")
+	if err != nil {
+		return nil, err
+	}
+	for i, group := range byStatus {
+		if group.Key == nil {
+			continue
+		}
+		status, convErr := strconv.Atoi(*group.Key)
+		if convErr != nil {
+			continue
+		}
+		statusString := constants.PaymentStatus(status).GetStatusString().String()
+		byStatus[i].Key = &statusString
+	}
+	byBank, err := repository.SummaryGroupBy(ctx, query, "bank")
+	if err != nil {
+		return nil, err
+	}
+	byAcquirer, err := repository.SummaryGroupBy(ctx, query, "acquirer")
+	if err != nil {
+		return nil, err
+	}
+	byPeriod, err := repository.SummaryByPeriod(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, groups := range [][]dto.PaymentSummaryGroup{byStatus, byBank, byAcquirer} {
+		for i := range groups {
+			groups[i].Amount = p.summaryAmount(groups[i].Amount)
+		}
+	}
+	for i := range byPeriod {
+		byPeriod[i].Amount = p.summaryAmount(byPeriod[i].Amount)
+		byPeriod[i].SettledAmount = p.summaryAmount(byPeriod[i].SettledAmount)
+		byPeriod[i].SettledFee = p.summaryAmount(byPeriod[i].SettledFee)
+		byPeriod[i].SettledNetAmount = p.summaryAmount(byPeriod[i].SettledNetAmount)
+	}
+
+	response := &dto.PaymentSummaryResponse{
+		StartDate:              param.StartDate,
+		EndDate:                param.EndDate,
+		Currency:               fx.ReportingCurrency(),
+		TotalCount:             total.TotalCount,
+		TotalAmount:            p.summaryAmount(total.TotalAmount),
+		SettledCount:           total.SettledCount,
+		SettledAmount:          p.summaryAmount(total.SettledAmount),
+		SettledFee:             p.summaryAmount(total.SettledFee),
+		SettledNetAmount:       p.summaryAmount(total.SettledNetAmount),
+		AverageTimeToPaySecond: total.AverageTimeToPaySecond,
+		ByStatus:               byStatus,
+		ByBank:                 byBank,
+		ByAcquirer:             byAcquirer,
+		ByPeriod:               byPeriod,
+	}
+	if total.TotalCount > 0 {
+		response.ConversionRate = float64(total.SettledCount) / float64(total.TotalCount)
+		response.ExpiryRate = float64(total.ExpiredCount) / float64(total.TotalCount)
+	}
+	return response, nil
+}
+
+func (p *PaymentService) Create(ctx context.Context, request *dto.PaymentRequest) (*dto.PaymentResponse, error) {
+	var (
+		txErr, err error
+		payment    *models.Payment
+		response   *dto.PaymentResponse
+		link       *dto.GatewayPaymentLink
+	)
+
+	if request.PaymentMethodID != nil {
+		return p.chargeSavedMethod(ctx, request)
+	}
+	quote, err := p.applyVoucher(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	p.applyTax(request)
+	if !p.gateway.SupportsCurrency(p.currency(request)) {
+		return nil, errPayment.ErrCurrencyNotSupported
+	}
+	err = p.snapshotRate(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	splits, err := p.resolveSplits(request)
+	if err != nil {
+		return nil, err
+	}
+	err = p.repository.GetTx().Transaction(func(tx *gorm.DB) error {
+		var attempt int
+		payment, attempt, txErr = p.prepareAttempt(ctx, tx, request, func(latest *models.Payment) bool {
+			return latest.PaymentLink != ""
+		})
+		if txErr != nil || payment != nil {
+			return txErr
+		}
+		if request.PaymentLinkID != nil {
+			txErr = p.claimLinkUse(ctx, tx, *request.PaymentLinkID)
+			if txErr != nil {
+				return txErr
+			}
+		}
+
+		gateway, decision, routeErr := p.route(request)
+		if routeErr != nil {
+			return routeErr
+		}
+		link, txErr = gateway.CreatePaymentLink(request)
+		p.gateway.Record(gateway.Provider(), txErr)
+		if txErr != nil {
+			return txErr
+		}
+		paymentRequest := &dto.PaymentRequest{
+			OrderID:          request.OrderID,
+			Provider:         gateway.Provider(),
+			GatewayOrderID:   request.GatewayOrderID,
+			GatewayReference: link.Reference,
+			RoutingRule:      decision.Rule,
+			RoutingReason:    decision.Reason,
+			Attempt:          attempt,
+			Amount:           request.Amount,
+			Currency:         request.Currency,
+			Discount:         request.Discount,
+			VoucherCode:      request.VoucherCode,
+			Tax:              request.Tax,
+			FX:               request.FX,
+			CustomerDetail:   request.CustomerDetail,
+			AllowPartial:     request.AllowPartial,
+			Description:      request.Description,
+			ExpiredAt:        request.ExpiredAt,
+			PaymentLink:      link.RedirectURL,
+			CustomerID:       request.CustomerID,
+			PaymentLinkID:    request.PaymentLinkID,
+			Authorize:        request.Authorize,
+		}
+		payment, txErr = p.repository.GetPayment().Create(ctx, tx, paymentRequest)
+		if txErr != nil {
+			return txErr
+		}
+		txErr = p.createSplits(ctx, tx, payment, splits)
+		if txErr != nil {
+			return txErr
+		}
+		if quote != nil {
+			txErr = p.voucher.Reserve(ctx, tx, quote, payment)
+			if txErr != nil {
+				return txErr
+			}
+		}
+
+		txErr = p.repository.GetPaymentHistory().Create(ctx, tx, &dto.PaymentHistoryRequest{
+			PaymentId: payment.ID,
+			Status:    payment.Status.GetStatusString(),
+		})
+		if txErr != nil {
+			return txErr
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response = &dto.PaymentResponse{
+		UUID:        payment.UUID,
+		OrderID:     payment.OrderID,
+		Attempt:     payment.Attempt,
+		Provider:    payment.Provider,
+		Amount:      p.amount(payment).Major(),
+		Currency:    payment.Currency,
+		Status:      payment.Status.GetStatusString(),
+		PaymentLink: payment.PaymentLink,
+		StatusURL:   checkout.StatusURL(payment.UUID.String()),
+		Description: payment.Description,
+	}
+	return response, nil
+}
+
+// Charge creates the payment through the Midtrans Core API so the client can
+// render the virtual account, QR code or deeplink itself instead of
+// redirecting to a hosted page.
+func (p *PaymentService) Charge(ctx context.Context, request *dto.ChargeRequest) (*dto.PaymentResponse, error) {
+	var (
+		txErr, err error
+		payment    *models.Payment
+		charge     *dto.GatewayCharge
+	)
+
+	if request.Authorize && request.PaymentMethod != constants.PaymentMethodCard {
+		return nil, errPayment.ErrAuthorizeNotSupported
+	}
+	paymentRequest := &request.PaymentRequest
+	paymentRequest.PaymentMethod = request.PaymentMethod
+	quote, err := p.applyVoucher(ctx, paymentRequest)
+	if err != nil {
+		return nil, err
+	}
+	p.applyTax(paymentRequest)
+	// Direct charges always go through Midtrans.
+	gateway, err := p.gateway.Get(constants.ProviderMidtrans)
+	if err != nil {
+		return nil, err
+	}
+	if !gateway.SupportsCurrency(p.currency(paymentRequest)) {
+		return nil, errPayment.ErrCurrencyNotSupported
+	}
+	err = p.snapshotRate(ctx, paymentRequest)
+	if err != nil {
+		return nil, err
+	}
+	splits, err := p.resolveSplits(paymentRequest)
+	if err != nil {
+		return nil, err
+	}
+	err = p.repository.GetTx().Transaction(func(tx *gorm.DB) error {
+		var attempt int
+		payment, attempt, txErr = p.prepareAttempt(ctx, tx, paymentRequest, func(latest *models.Payment) bool {
+			return latest.PaymentMethod != nil && *latest.PaymentMethod == request.PaymentMethod
+		})
+		if txErr != nil || payment != nil {
+			return txErr
+		}
+		if request.PaymentLinkID != nil {
+			txErr = p.claimLinkUse(ctx, tx, *request.PaymentLinkID)
+			if txErr != nil {
+				return txErr
+			}
+		}
+
+		charger, ok := gateway.(clients.DirectCharger)
+		if !ok {
+			return errGateway.ErrGatewayNotSupported
+		}
+		charge, txErr = charger.Charge(paymentRequest)
+		p.gateway.Record(gateway.Provider(), txErr)
+		if txErr != nil {
+			return txErr
+		}
+
+		expiredAt := request.ExpiredAt
+		if charge.ExpiredAt != nil {
+			expiredAt = *charge.ExpiredAt
+		}
+		status := constants.Pending
+		payment, txErr = p.repository.GetPayment().Create(ctx, tx, &dto.PaymentRequest{
+			OrderID:          request.OrderID,
+			Provider:         gateway.Provider(),
+			GatewayOrderID:   paymentRequest.GatewayOrderID,
+			GatewayReference: charge.TransactionID,
+			RoutingReason:    "direct charge is served by the core api",
+			Attempt:          attempt,
+			Amount:           request.Amount,
+			Currency:         request.Currency,
+			Discount:         request.Discount,
+			VoucherCode:      request.VoucherCode,
+			Tax:              request.Tax,
+			FX:               request.FX,
+			CustomerDetail:   request.CustomerDetail,
+			AllowPartial:     request.AllowPartial,
+			Description:      request.Description,
+			ExpiredAt:        expiredAt,
+			PaymentMethod:    request.PaymentMethod,
+			Status:           &status,
+			Instruction:      &charge.Instruction,
+			CustomerID:       request.CustomerID,
+			SubscriptionID:   request.SubscriptionID,
+			PaymentLinkID:    request.PaymentLinkID,
+			Authorize:        request.Authorize,
+		})
+		if txErr != nil {
+			return txErr
+		}
+		txErr = p.createSplits(ctx, tx, payment, splits)
+		if txErr != nil {
+			return txErr
+		}
+		if quote != nil {
+			txErr = p.voucher.Reserve(ctx, tx, quote, payment)
+			if txErr != nil {
+				return txErr
+			}
+		}
+
+		txErr = p.repository.GetPaymentHistory().Create(ctx, tx, &dto.PaymentHistoryRequest{
+			PaymentId: payment.ID,
+			Status:    payment.Status.GetStatusString(),
+		})
+		if txErr != nil {
+			return txErr
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return &dto.PaymentResponse{
 		UUID:          payment.UUID,
 		OrderID:       payment.OrderID,
-		Amount:        payment.Amount,
+		Attempt:       payment.Attempt,
+		Provider:      payment.Provider,
+		Amount:        p.amount(payment).Major(),
+		Currency:      payment.Currency,
 		Status:        payment.Status.GetStatusString(),
 		PaymentLink:   payment.PaymentLink,
-		TransactionId: payment.TransactionID,
-		PaidAt:        payment.PaidAt,
+		PaymentMethod: payment.PaymentMethod,
 		VANumber:      payment.VANumber,
 		Bank:          payment.Bank,
-		InvoiceLink:   payment.InvoiceLink,
-		Acquirer:      payment.Acquirer,
+		QRString:      payment.QRString,
+		QRImageURL:    payment.QRImageURL,
+		Deeplink:      payment.Deeplink,
+		ExpiredAt:     payment.ExpiredAt,
+		StatusURL:     checkout.StatusURL(payment.UUID.String()),
 		Description:   payment.Description,
-		CreatedAt:     payment.CreatedAt,
-		UpdatedAt:     payment.UpdatedAt,
 	}, nil
 }
 
-func (p *PaymentService) Create(ctx context.Context, request *dto.PaymentRequest) (*dto.PaymentResponse, error) {
+// chargeSavedMethod charges a card the customer saved on an earlier payment
+// directly, so there is no payment page to go through.
+func (p *PaymentService) chargeSavedMethod(ctx context.Context, request *dto.PaymentRequest) (*dto.PaymentResponse, error) {
+	method, err := p.repository.GetPaymentMethod().FindByUUID(ctx, *request.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+	if request.CustomerID == nil || method.CustomerID != *request.CustomerID {
+		return nil, errPayment.ErrSavedMethodNotFound
+	}
+	if method.ExpiredAt != nil && method.ExpiredAt.Before(time.Now()) {
+		return nil, errPayment.ErrSavedMethodExpired
+	}
+
+	request.PaymentToken = method.Token
+	response, err := p.Charge(ctx, &dto.ChargeRequest{
+		PaymentRequest: *request,
+		PaymentMethod:  constants.PaymentMethodCard,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = p.repository.GetPaymentMethod().MarkUsed(ctx, method.ID, time.Now())
+	if err != nil {
+		logrus.Errorf("failed to mark payment method %s as used: %v", method.UUID, err)
+	}
+	return response, nil
+}
+
+// claimLinkUse holds the payment link until the payment is created, so
+// concurrent payers cannot use it more often than it allows.
+func (p *PaymentService) claimLinkUse(ctx context.Context, tx *gorm.DB, paymentLinkID uint) error {
+	link, err := p.repository.GetPaymentLink().Lock(ctx, tx, paymentLinkID)
+	if err != nil {
+		return err
+	}
+	if link.MaxUses == nil {
+		return nil
+	}
+	stats, err := p.repository.GetPaymentLink().Stats(ctx, tx, link.ID)
+	if err != nil {
+		return err
+	}
+	if stats.Used >= int64(*link.MaxUses) {
+		return errPaymentLink.ErrPaymentLinkUsedUp
+	}
+	return nil
+}
+
+// applyVoucher takes the discount of the voucher off the amount and adds it as
+// an item with a negative amount, so the items still add up to what the
+// customer pays.
+func (p *PaymentService) applyVoucher(ctx context.Context, request *dto.PaymentRequest) (*dto.VoucherQuote, error) {
+	if request.VoucherCode == nil || *request.VoucherCode == "" {
+		request.VoucherCode = nil
+		return nil, nil
+	}
+	currency := p.currency(request)
+	amount := money.FromMajor(request.Amount, currency)
+	quote, err := p.voucher.Quote(ctx, *request.VoucherCode, amount, request.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Discount.Amount == 0 {
+		return nil, nil
+	}
+	if quote.Discount.Amount >= amount.Amount {
+		return nil, errVoucher.ErrVoucherCoversAmount
+	}
+
+	discounted, _ := amount.Sub(quote.Discount)
+	name := fmt.Sprintf("Discount %s", quote.Code)
+	if len(name) > 50 {
+		name = name[:50]
+	}
+	request.Amount = discounted.Major()
+	request.Discount = quote.Discount.Amount
+	request.VoucherCode = &quote.Code
+	request.ItemDetails = append(request.ItemDetails, dto.ItemDetail{
+		ID:       "VOUCHER",
+		Amount:   -quote.Discount.Major(),
+		Name:     name,
+		Quantity: 1,
+	})
+	return quote, nil
+}
+
+// applyTax computes the PPN of every taxable item once discounts are spread
+// over the items in proportion. Tax that is not included in the item amounts
+// is added to the amount as an item of its own.
+func (p *PaymentService) applyTax(request *dto.PaymentRequest) {
+	rule := config2.Config.Tax
+	if !rule.Enabled || rule.Rate <= 0 {
+		return
+	}
+	currency := p.currency(request)
+
 	var (
-		txErr, err error
-		payment    *models.Payment
-		response   *dto.PaymentResponse
-		midtrans   *clients.MidTransData
+		items    []dto.ItemDetail
+		lines    []int64
+		discount int64
 	)
+	for _, item := range request.ItemDetails {
+		line := money.FromMajor(item.Amount, currency).Multiply(int64(item.Quantity))
+		if line.IsNegative() {
+			discount -= line.Amount
+			continue
+		}
+		items = append(items, item)
+		lines = append(lines, line.Amount)
+	}
+	discounts := make([]money.Money, len(lines))
+	if discount > 0 {
+		discounts = money.New(discount, currency).Allocate(lines...)
+	}
 
-	err = p.repository.GetTx().Transaction(func(tx *gorm.DB) error {
-		if !request.ExpiredAt.After(time.Now()) {
-			return errPayment.ErrExpireAtInvalid
+	tax := &dto.PaymentTax{
+		Rate:         rule.Rate,
+		Inclusive:    rule.Inclusive,
+		MerchantNPWP: rule.MerchantNPWP,
+	}
+	for i, item := range items {
+		if p.taxExempt(item.Category) {
+			continue
+		}
+		base := money.New(lines[i]-discounts[i].Amount, currency)
+		var ppn money.Money
+		if rule.Inclusive {
+			ppn = base.MultiplyRate(rule.Rate / (100 + rule.Rate)).Round(0)
+			base, _ = base.Sub(ppn)
+		} else {
+			ppn = base.MultiplyRate(rule.Rate / 100).Round(0)
+		}
+		tax.Base += base.Amount
+		tax.Amount += ppn.Amount
+	}
+	request.Tax = tax
+	if rule.Inclusive || tax.Amount == 0 {
+		return
+	}
+
+	ppn := money.New(tax.Amount, currency)
+	total, _ := money.FromMajor(request.Amount, currency).Add(ppn)
+	request.Amount = total.Major()
+	request.ItemDetails = append(request.ItemDetails, dto.ItemDetail{
+		ID:       "PPN",
+		Amount:   ppn.Major(),
+		Name:     p.taxLabel(rule.Rate),
+		Quantity: 1,
+	})
+}
+
+func (p *PaymentService) taxExempt(category string) bool {
+	for _, exempt := range config2.Config.Tax.ExemptCategories {
+		if strings.EqualFold(exempt, category) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *PaymentService) taxLabel(rate float64) string {
+	return fmt.Sprintf("PPN %s%%", strconv.FormatFloat(rate, 'f', -1, 64))
+}
+
+func (p *PaymentService) currency(request *dto.PaymentRequest) string {
+	if request.Currency == "" {
+		return constants.DefaultCurrency
+	}
+	return request.Currency
+}
+
+// snapshotRate keeps the exchange rate into the reporting currency at
+// creation, so reports do not move with later rates.
+func (p *PaymentService) snapshotRate(ctx context.Context, request *dto.PaymentRequest) error {
+	currency := p.currency(request)
+	reporting := fx.ReportingCurrency()
+	rate, err := p.fx.Rate(ctx, currency, reporting)
+	if err != nil {
+		return err
+	}
+	request.FX = &dto.PaymentFX{
+		Rate:            rate.Value,
+		Source:          rate.Source,
+		ReportingAmount: money.FromMajor(request.Amount, currency).Convert(rate.Value, reporting).Amount,
+	}
+	return nil
+}
+
+func (p *PaymentService) fxRate(payment *models.Payment) *dto.PaymentFXResponse {
+	if payment.FXRate == nil || payment.ReportingAmount == nil {
+		return nil
+	}
+	response := &dto.PaymentFXResponse{
+		Rate:              *payment.FXRate,
+		ReportingCurrency: fx.ReportingCurrency(),
+		ReportingAmount:   money.New(*payment.ReportingAmount, fx.ReportingCurrency()).Major(),
+	}
+	if payment.FXSource != nil {
+		response.Source = *payment.FXSource
+	}
+	return response
+}
+
+func (p *PaymentService) tax(payment *models.Payment) *dto.PaymentTaxResponse {
+	if payment.TaxRate == nil {
+		return nil
+	}
+	return &dto.PaymentTaxResponse{
+		Rate:         *payment.TaxRate,
+		Inclusive:    payment.TaxInclusive,
+		Base:         money.New(payment.TaxBase, payment.Currency).Major(),
+		Amount:       money.New(payment.TaxAmount, payment.Currency).Major(),
+		MerchantNPWP: payment.MerchantNPWP,
+		CustomerNPWP: payment.CustomerNPWP,
+		CustomerName: payment.CustomerName,
+	}
+}
+
+// resolveSplits turns the split instructions into amounts of the payment
+// currency. Percentages are taken from the full amount, the fee of a split is
+// the platform commission and comes out of the merchant share.
+func (p *PaymentService) resolveSplits(request *dto.PaymentRequest) ([]dto.PaymentSplitRequest, error) {
+	if len(request.Splits) == 0 {
+		return nil, nil
+	}
+	currency := p.currency(request)
+	amount := money.FromMajor(request.Amount, currency)
+
+	var total int64
+	splits := make([]dto.PaymentSplitRequest, 0, len(request.Splits))
+	for _, instruction := range request.Splits {
+		share := money.New(0, currency)
+		if instruction.Percentage != nil {
+			share = amount.MultiplyRate(*instruction.Percentage / 100)
+		} else if instruction.Amount != nil {
+			share = money.FromMajor(*instruction.Amount, currency)
+		}
+		if share.Amount <= 0 {
+			return nil, errLedger.ErrSplitInvalid
+		}
+		fee := money.New(0, currency)
+		if instruction.Fee != nil {
+			fee = money.FromMajor(*instruction.Fee, currency)
+		}
+		if fee.Amount > share.Amount {
+			return nil, errLedger.ErrSplitFeeInvalid
+		}
+		total += share.Amount
+		splits = append(splits, dto.PaymentSplitRequest{
+			MerchantID: instruction.MerchantID,
+			Amount:     share,
+			Percentage: instruction.Percentage,
+			Fee:        fee,
+		})
+	}
+	if total > amount.Amount {
+		return nil, errLedger.ErrSplitExceedsAmount
+	}
+	return splits, nil
+}
+
+func (p *PaymentService) createSplits(
+	ctx context.Context,
+	tx *gorm.DB,
+	payment *models.Payment,
+	splits []dto.PaymentSplitRequest,
+) error {
+	for i := range splits {
+		splits[i].PaymentID = payment.ID
+	}
+	return p.repository.GetPaymentSplit().Create(ctx, tx, splits)
+}
+
+// applyGatewayFee estimates the fee of a settled payment from the fee
+// schedule, the settlement report of the gateway later replaces it with the
+// fee actually deducted.
+func (p *PaymentService) applyGatewayFee(ctx context.Context, tx *gorm.DB, payment *models.Payment) error {
+	if payment.Fee != nil {
+		return nil
+	}
+	fee, ok := p.gatewayFee(payment)
+	if !ok {
+		return nil
+	}
+	net, _ := p.DueAmount(payment).Sub(fee)
+	source := constants.FeeSourceSchedule
+	_, err := p.repository.GetPayment().Update(ctx, tx, payment.ID, &dto.UpdatePaymentRequest{
+		Fee:       &fee.Amount,
+		NetAmount: &net.Amount,
+		FeeSource: &source,
+	})
+	if err != nil {
+		return err
+	}
+	payment.Fee = &fee.Amount
+	payment.NetAmount = &net.Amount
+	payment.FeeSource = &source
+	return p.ledger.PostGatewayFee(ctx, tx, payment, 0)
+}
+
+func (p *PaymentService) gatewayFee(payment *models.Payment) (money.Money, bool) {
+	schedule := p.feeSchedule(payment)
+	if schedule == nil {
+		return money.Money{}, false
+	}
+	due := p.DueAmount(payment)
+	fee, _ := due.MultiplyRate(schedule.Percentage / 100).Add(money.FromMajor(schedule.Fixed, payment.Currency))
+	if fee.Amount > due.Amount {
+		fee = due
+	}
+	return fee, true
+}
+
+// feeSchedule prefers the fee of the payment type reported by the gateway over
+// the one of the payment method the payment was created with, and falls back
+// to the fee of the provider without a payment type.
+func (p *PaymentService) feeSchedule(payment *models.Payment) *config2.GatewayFee {
+	var fallback *config2.GatewayFee
+	fees := config2.Config.Gateway.Fees
+	for _, paymentType := range []*string{payment.PaymentType, payment.PaymentMethod} {
+		for i := range fees {
+			if !strings.EqualFold(fees[i].Provider, payment.Provider) {
+				continue
+			}
+			if fees[i].PaymentType == "" {
+				if fallback == nil {
+					fallback = &fees[i]
+				}
+				continue
+			}
+			if paymentType != nil && strings.EqualFold(fees[i].PaymentType, *paymentType) {
+				return &fees[i]
+			}
+		}
+	}
+	return fallback
+}
+
+func (p *PaymentService) amount(payment *models.Payment) money.Money {
+	return money.New(payment.Amount, payment.Currency)
+}
+
+func (p *PaymentService) paidAmount(payment *models.Payment) money.Money {
+	return money.New(payment.PaidAmount, payment.Currency)
+}
+
+// DueAmount is what the payment collects, capturing an authorisation for less
+// than its amount releases the rest.
+func (p *PaymentService) DueAmount(payment *models.Payment) money.Money {
+	if payment.CaptureAmount != nil {
+		return money.New(*payment.CaptureAmount, payment.Currency)
+	}
+	return p.amount(payment)
+}
+
+func (p *PaymentService) captureAmount(payment *models.Payment) *float64 {
+	if payment.CaptureAmount == nil {
+		return nil
+	}
+	amount := p.DueAmount(payment).Major()
+	return &amount
+}
+
+func (p *PaymentService) optionalAmount(amount *int64, currency string) *float64 {
+	if amount == nil {
+		return nil
+	}
+	value := money.New(*amount, currency).Major()
+	return &value
+}
+
+func (p *PaymentService) OutstandingAmount(payment *models.Payment) money.Money {
+	outstanding, _ := p.DueAmount(payment).Sub(p.paidAmount(payment))
+	if outstanding.IsNegative() {
+		return money.New(0, payment.Currency)
+	}
+	return outstanding
+}
+
+// summaryAmount turns a sum of stored minor units back into major units.
+func (p *PaymentService) summaryAmount(amount float64) float64 {
+	return money.New(int64(math.Round(amount)), fx.ReportingCurrency()).Major()
+}
+
+// prepareAttempt serialises payment creation per order and decides what to do
+// with its latest attempt. A still usable attempt accepted by reusable is
+// returned as is; any other active attempt is closed so the new one becomes
+// the only active payment of the order, and the gateway order id of the new
+// attempt is set on the request.
+func (p *PaymentService) prepareAttempt(
+	ctx context.Context,
+	tx *gorm.DB,
+	request *dto.PaymentRequest,
+	reusable func(*models.Payment) bool,
+) (*models.Payment, int, error) {
+	if !request.ExpiredAt.After(time.Now()) {
+		return nil, 0, errPayment.ErrExpireAtInvalid
+	}
+	if request.ExpiryStartTime != nil && !request.ExpiredAt.After(*request.ExpiryStartTime) {
+		return nil, 0, errPayment.ErrExpireAtInvalid
+	}
+	currency := p.currency(request)
+	var itemTotal int64
+	for _, item := range request.ItemDetails {
+		itemTotal += money.FromMajor(item.Amount, currency).Multiply(int64(item.Quantity)).Amount
+	}
+	if itemTotal != money.FromMajor(request.Amount, currency).Amount {
+		return nil, 0, errPayment.ErrItemTotalMismatch
+	}
+	err := p.repository.GetPayment().LockOrder(ctx, tx, request.OrderID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	attempt := 1
+	latest, err := p.repository.GetPayment().FindByOrderID(ctx, request.OrderID)
+	if err != nil && !errors.Is(err, errPayment.ErrPaymentNotFound) {
+		return nil, 0, err
+	}
+	if latest != nil {
+		attempt = latest.Attempt + 1
+		if *latest.Status == constants.Settlement || *latest.Status == constants.PartiallyRefunded {
+			return nil, 0, errPayment.ErrPaymentAlreadySettled
 		}
-		midtrans, txErr = p.midtrans.CreatePaymentLink(request)
-		if txErr != nil {
-			return txErr
+		if *latest.Status == constants.NeedsReview {
+			return nil, 0, errPayment.ErrPaymentUnderReview
 		}
-		paymentRequest := &dto.PaymentRequest{
-			OrderID:     request.OrderID,
-			Amount:      request.Amount,
-			Description: request.Description,
-			ExpiredAt:   request.ExpiredAt,
-			PaymentLink: midtrans.RedirectURL,
+		if *latest.Status == constants.Authorized {
+			return nil, 0, errPayment.ErrPaymentAuthorized
 		}
-		payment, txErr = p.repository.GetPayment().Create(ctx, tx, paymentRequest)
-		if txErr != nil {
-			return txErr
+		// Transfers already made count towards the current attempt, the
+		// customer keeps paying the outstanding amount on it.
+		if *latest.Status == constants.PartiallyPaid {
+			return latest, latest.Attempt, nil
 		}
-
-		txErr = p.repository.GetPaymentHistory().Create(ctx, tx, &dto.PaymentHistoryRequest{
-			PaymentId: payment.ID,
-			Status:    payment.Status.GetStatusString(),
-		})
-		if txErr != nil {
-			return txErr
+		if latest.Status.IsActive() {
+			status := constants.Expire
+			if latest.ExpiredAt != nil && latest.ExpiredAt.After(time.Now()) {
+				if reusable(latest) {
+					return latest, latest.Attempt, nil
+				}
+				err = p.cancelAtGateway(latest)
+				if err != nil {
+					return nil, 0, err
+				}
+				status = constants.Cancel
+			}
+			err = p.supersede(ctx, tx, latest, status)
+			if err != nil {
+				return nil, 0, err
+			}
 		}
-		return nil
-	})
+	}
+
+	request.GatewayOrderID = request.OrderID
+	if attempt > 1 {
+		request.GatewayOrderID = fmt.Sprintf("%s-%d", request.OrderID, attempt)
+	}
+	return nil, attempt, nil
+}
+
+// cancelAtGateway closes an attempt that can still be paid so the customer
+// cannot settle it after it has been replaced.
+func (p *PaymentService) cancelAtGateway(payment *models.Payment) error {
+	gateway, err := p.gateway.Get(payment.Provider)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	err = gateway.Cancel(payment.GatewayOrderID)
+	if err != nil && !errors.Is(err, errPayment.ErrTransactionNotFound) {
+		return err
+	}
+	return nil
+}
 
-	response = &dto.PaymentResponse{
-		UUID:        payment.UUID,
-		OrderID:     payment.OrderID,
-		Amount:      payment.Amount,
-		Status:      payment.Status.GetStatusString(),
-		PaymentLink: payment.PaymentLink,
-		Description: payment.Description,
+// supersede closes an attempt that is no longer usable so a new attempt can
+// take its place as the active payment of the order.
+func (p *PaymentService) supersede(ctx context.Context, tx *gorm.DB, payment *models.Payment, status constants.PaymentStatus) error {
+	_, err := p.repository.GetPayment().Update(ctx, tx, payment.ID, &dto.UpdatePaymentRequest{
+		Status: &status,
+	})
+	if err != nil {
+		return err
 	}
-	return response, nil
+	if payment.VoucherCode != nil {
+		err = p.voucher.Release(ctx, tx, payment)
+		if err != nil {
+			return err
+		}
+	}
+	return p.repository.GetPaymentHistory().Create(ctx, tx, &dto.PaymentHistoryRequest{
+		PaymentId: payment.ID,
+		Status:    status.GetStatusString(),
+	})
 }
 
 func (p *PaymentService) convertToIndonesiaMonth(englishMonth string) string {
@@ -184,6 +1097,99 @@ func (p *PaymentService) convertToIndonesiaMonth(englishMonth string) string {
 	return indonesiaMonth
 }
 
+type paymentTypeLabel struct {
+	method    string
+	channel   string
+	reference string
+}
+
+var paymentTypeLabels = map[string]paymentTypeLabel{
+	"bank_transfer": {method: "Virtual Account", channel: "Bank", reference: "Nomor VA"},
+	"echannel":      {method: "Mandiri Bill Payment", channel: "Bank", reference: "Kode Biller / Bayar"},
+	"credit_card":   {method: "Kartu Kredit", channel: "Bank", reference: "Nomor Kartu"},
+	"qris":          {method: "QRIS", channel: "Penerbit"},
+	"gopay":         {method: "GoPay"},
+	"shopeepay":     {method: "ShopeePay"},
+	"ewallet":       {method: "E-Wallet", channel: "Penyedia"},
+	"cstore":        {method: "Gerai Retail", channel: "Gerai", reference: "Kode Pembayaran"},
+}
+
+func (p *PaymentService) invoicePaymentDetail(payment *models.Payment) dto.InvoicePaymentDetail {
+	var paymentType, channel, reference string
+	if payment.PaymentType != nil {
+		paymentType = *payment.PaymentType
+	}
+	if payment.PaymentChannel != nil {
+		channel = strings.ToUpper(*payment.PaymentChannel)
+	}
+	if payment.PaymentReference != nil {
+		reference = *payment.PaymentReference
+	}
+	label, ok := paymentTypeLabels[paymentType]
+	if !ok {
+		label = paymentTypeLabel{method: paymentType, channel: "Channel", reference: "Referensi"}
+	}
+	detail := dto.InvoicePaymentDetail{
+		PaymentMethod:  label.method,
+		ChannelLabel:   label.channel,
+		ReferenceLabel: label.reference,
+	}
+	if label.channel != "" {
+		detail.Channel = channel
+	}
+	if label.reference != "" {
+		detail.Reference = reference
+	}
+	return detail
+}
+
+// invoiceItems lists the payment at its price before the discount and before
+// tax that was added on top, the invoice shows both underneath.
+func (p *PaymentService) invoiceItems(payment *models.Payment, description string) []dto.InvoiceItem {
+	price := p.DueAmount(payment)
+	discount := money.New(payment.Discount, payment.Currency)
+	price, _ = price.Add(discount)
+	if !payment.TaxInclusive {
+		price, _ = price.Sub(money.New(payment.TaxAmount, payment.Currency))
+	}
+	items := []dto.InvoiceItem{
+		{
+			Description: description,
+			Price:       price.Format(),
+		},
+	}
+	if payment.Discount > 0 {
+		items = append(items, dto.InvoiceItem{
+			Description: fmt.Sprintf("Diskon %s", *payment.VoucherCode),
+			Price:       money.New(-discount.Amount, discount.Currency).Format(),
+		})
+	}
+	return items
+}
+
+func (p *PaymentService) invoiceTax(payment *models.Payment) *dto.InvoiceTax {
+	if payment.TaxRate == nil {
+		return nil
+	}
+	tax := &dto.InvoiceTax{
+		Label:           p.taxLabel(*payment.TaxRate),
+		Base:            money.New(payment.TaxBase, payment.Currency).Format(),
+		Amount:          money.New(payment.TaxAmount, payment.Currency).Format(),
+		MerchantName:    config2.Config.Tax.MerchantName,
+		MerchantAddress: config2.Config.Tax.MerchantAddress,
+	}
+	if payment.MerchantNPWP != nil {
+		tax.MerchantNPWP = *payment.MerchantNPWP
+	}
+	if payment.CustomerName != nil {
+		tax.CustomerName = *payment.CustomerName
+	}
+	if payment.CustomerNPWP != nil {
+		tax.CustomerNPWP = *payment.CustomerNPWP
+	}
+	return tax
+}
+
 func (p *PaymentService) generatePDF(req *dto.InvoiceRequest) ([]byte, error) {
 	htmlTemplatePath := "templates/invoice.html"
 	htmlTemplate, err := os.ReadFile(htmlTemplatePath)
@@ -224,20 +1230,221 @@ func (p *PaymentService) mapTransactionStatusToEvent(status constants.PaymentSta
 	switch status {
 	case constants.PendingString:
 		paymentStatus = strings.ToUpper(constants.PendingString.String())
+	case constants.PartiallyPaidString:
+		paymentStatus = strings.ToUpper(constants.PartiallyPaidString.String())
+	case constants.AuthorizedString:
+		paymentStatus = strings.ToUpper(constants.AuthorizedString.String())
 	case constants.SettlementString:
 		paymentStatus = strings.ToUpper(constants.SettlementString.String())
+	case constants.PartialRefundString:
+		paymentStatus = strings.ToUpper(constants.PartialRefundString.String())
+	case constants.RefundString:
+		paymentStatus = strings.ToUpper(constants.RefundString.String())
 	case constants.ExpireString:
 		paymentStatus = strings.ToUpper(constants.ExpireString.String())
+	case constants.CancelString:
+		paymentStatus = strings.ToUpper(constants.CancelString.String())
 	}
 	return paymentStatus
 }
 
+// reviewReason explains why a notification can not be applied as is, a
+// notification for another amount or currency must not settle the order.
+func (p *PaymentService) reviewReason(payment *models.Payment, webhook *dto.GatewayNotification) *string {
+	var reason string
+	amount := p.DueAmount(payment)
+	if webhook.Currency != "" && !strings.EqualFold(webhook.Currency, payment.Currency) {
+		reason = fmt.Sprintf("notified currency %s does not match %s", webhook.Currency, payment.Currency)
+	} else if webhook.GrossAmount != "" {
+		grossAmount, err := money.Parse(webhook.GrossAmount, payment.Currency)
+		if err != nil {
+			reason = fmt.Sprintf("notified gross amount %q is not a valid amount", webhook.GrossAmount)
+		} else if !grossAmount.Equal(amount) {
+			reason = fmt.Sprintf("notified gross amount %s does not match %s", grossAmount.String(), amount.String())
+		}
+	}
+	if reason == "" && payment.AllowPartial && len(webhook.PaymentAmounts) > 0 {
+		paid := money.New(0, payment.Currency)
+		for _, paidAmount := range webhook.PaymentAmounts {
+			value, err := money.Parse(paidAmount.Amount, payment.Currency)
+			if err != nil || value.Amount <= 0 {
+				reason = fmt.Sprintf("notified payment amount %q is not a valid amount", paidAmount.Amount)
+				break
+			}
+			paid, _ = paid.Add(value)
+		}
+		if reason == "" && paid.Amount > amount.Amount {
+			reason = fmt.Sprintf("notified payments of %s exceed %s", paid.String(), amount.String())
+		}
+	}
+	if reason == "" && webhook.TransactionStatus.GetStatusInt().IsRefund() {
+		refunded, err := p.refundedAmount(payment, webhook)
+		settled := *payment.Status == constants.Settlement || payment.Status.IsRefund()
+		switch {
+		case !settled:
+			reason = fmt.Sprintf("refund notified for a %s payment", payment.Status.GetStatusString())
+		case err != nil:
+			reason = fmt.Sprintf("notified refund amount %q is not a valid amount", webhook.RefundAmount)
+		case refunded.Amount > p.refundableAmount(payment).Amount:
+			reason = fmt.Sprintf("notified refunds of %s exceed %s", refunded.String(), p.refundableAmount(payment).String())
+		}
+	}
+	if reason == "" {
+		return nil
+	}
+	return &reason
+}
+
+// refundedAmount is the total the gateway refunded so far. A full refund may
+// be notified without an amount.
+func (p *PaymentService) refundedAmount(payment *models.Payment, webhook *dto.GatewayNotification) (money.Money, error) {
+	if webhook.RefundAmount == "" {
+		if webhook.TransactionStatus == constants.RefundString {
+			return p.refundableAmount(payment), nil
+		}
+		return money.New(payment.RefundAmount, payment.Currency), nil
+	}
+	return money.Parse(webhook.RefundAmount, payment.Currency)
+}
+
+// refundableAmount is what was collected, payments settled before paid
+// amounts were recorded collected their due amount.
+func (p *PaymentService) refundableAmount(payment *models.Payment) money.Money {
+	if payment.PaidAmount > 0 {
+		return p.paidAmount(payment)
+	}
+	return p.DueAmount(payment)
+}
+
+// recordPayments stores the transfers of a notification and returns what has
+// been paid so far. A settlement that lists no transfers pays whatever is
+// still outstanding.
+func (p *PaymentService) recordPayments(
+	ctx context.Context,
+	tx *gorm.DB,
+	payment *models.Payment,
+	webhook *dto.GatewayNotification,
+) (money.Money, error) {
+	repository := p.repository.GetPaymentTransaction()
+	paid, err := repository.SumByPaymentID(ctx, tx, payment.ID)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	requests := make([]dto.PaymentTransactionRequest, 0, len(webhook.PaymentAmounts))
+	if payment.AllowPartial {
+		for i, paidAmount := range webhook.PaymentAmounts {
+			// Already validated by reviewReason.
+			amount, _ := money.Parse(paidAmount.Amount, payment.Currency)
+			requests = append(requests, dto.PaymentTransactionRequest{
+				Reference: fmt.Sprintf("%s-%d", webhook.TransactionID, i+1),
+				Amount:    amount,
+				PaidAt:    paidAmount.PaidAt,
+			})
+		}
+	}
+	due := p.DueAmount(payment).Amount
+	if len(requests) == 0 && webhook.TransactionStatus == constants.SettlementString && paid < due {
+		requests = append(requests, dto.PaymentTransactionRequest{
+			Reference: webhook.TransactionID,
+			Amount:    money.New(due-paid, payment.Currency),
+			PaidAt:    webhook.PaidAt,
+		})
+	}
+	for _, request := range requests {
+		request.PaymentID = payment.ID
+		request.TransactionID = webhook.TransactionID
+		err = repository.Create(ctx, tx, &request)
+		if err != nil {
+			return money.Money{}, err
+		}
+	}
+
+	paid, err = repository.SumByPaymentID(ctx, tx, payment.ID)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if paid != payment.PaidAmount {
+		_, err = p.repository.GetPayment().Update(ctx, tx, payment.ID, &dto.UpdatePaymentRequest{
+			PaidAmount: &paid,
+		})
+		if err != nil {
+			return money.Money{}, err
+		}
+	}
+	return money.New(paid, payment.Currency), nil
+}
+
+func (p *PaymentService) paidStatus(payment *models.Payment, paid money.Money, status constants.PaymentStatus) constants.PaymentStatus {
+	switch {
+	case paid.Amount >= p.DueAmount(payment).Amount:
+		return constants.Settlement
+	case paid.Amount > 0:
+		return constants.PartiallyPaid
+	}
+	return status
+}
+
+func (p *PaymentService) park(
+	ctx context.Context,
+	tx *gorm.DB,
+	payment *models.Payment,
+	webhook *dto.GatewayNotification,
+	reason string,
+) error {
+	logrus.Errorf("payment %s needs review: %s", payment.UUID, reason)
+	status := constants.NeedsReview
+	_, err := p.repository.GetPayment().Update(ctx, tx, payment.ID, &dto.UpdatePaymentRequest{
+		TransactionId: &webhook.TransactionID,
+		Status:        &status,
+		ReviewReason:  &reason,
+	})
+	if err != nil {
+		return err
+	}
+	return p.repository.GetPaymentHistory().Create(ctx, tx, &dto.PaymentHistoryRequest{
+		PaymentId: payment.ID,
+		Status:    status.GetStatusString(),
+	})
+}
+
+func (p *PaymentService) produceAlert(payment *models.Payment, webhook *dto.GatewayNotification, reason string) error {
+	message := dto.KafkaAlertMessage{
+		Event: dto.KafkaEvent{
+			Name: strings.ToUpper(constants.NeedsReviewString.String()),
+		},
+		MetaData: dto.KafkaMetaData{
+			Sender:    "payment-service",
+			SendingAt: time.Now().Format(time.RFC3339),
+		},
+		Body: dto.KafkaAlertBody{
+			Type: "JSON",
+			Data: &dto.KafkaAlertData{
+				OrderID:          payment.OrderID,
+				PaymentID:        payment.UUID,
+				Provider:         payment.Provider,
+				Reason:           reason,
+				Amount:           p.amount(payment).String(),
+				Currency:         payment.Currency,
+				NotifiedAmount:   webhook.GrossAmount,
+				NotifiedCurrency: webhook.Currency,
+			},
+		},
+	}
+	topic := config2.Config.Kafka.AlertTopic
+	if topic == "" {
+		topic = config2.Config.Kafka.Topic
+	}
+	messageJson, _ := json.Marshal(message)
+	return p.kafka.GetKafkaProducer().ProduceMessage(topic, messageJson)
+}
+
 func (p *PaymentService) produceToKafka(
-	request *dto.Webhook,
 	payment *models.Payment,
 	paidAt *time.Time) error {
+	status := payment.Status.GetStatusString()
 	event := dto.KafkaEvent{
-		Name: p.mapTransactionStatusToEvent(request.TransactionStatus),
+		Name: p.mapTransactionStatusToEvent(status),
 	}
 	metadata := dto.KafkaMetaData{
 		Sender:    "payment-service",
@@ -248,11 +1455,19 @@ func (p *PaymentService) produceToKafka(
 		Data: &dto.KafkaData{
 			OrderID:   payment.OrderID,
 			PaymentID: payment.UUID,
-			Status:    request.TransactionStatus.String(),
+			Status:    status.String(),
+			Currency:  payment.Currency,
 			PaidAt:    paidAt,
 			ExpiredAt: *payment.ExpiredAt,
 		},
 	}
+	if payment.AllowPartial {
+		body.Data.PaidAmount = p.paidAmount(payment).String()
+		body.Data.OutstandingAmount = p.OutstandingAmount(payment).String()
+	}
+	if payment.Status.IsRefund() {
+		body.Data.RefundAmount = money.New(payment.RefundAmount, payment.Currency).String()
+	}
 	kafkaMessage := dto.KafkaMessage{
 		Event:    event,
 		Body:     body,
@@ -267,40 +1482,133 @@ func (p *PaymentService) produceToKafka(
 	return nil
 }
 
-func (p *PaymentService) Webhook(ctx context.Context, webhook *dto.Webhook) error {
+func (p *PaymentService) Webhook(ctx context.Context, provider string, request *dto.WebhookRequest) error {
+	gateway, err := p.gateway.Get(provider)
+	if err != nil {
+		return err
+	}
+	notification, err := gateway.ParseWebhook(request.Header, request.Body)
+	if err != nil {
+		return err
+	}
+	return p.ApplyNotification(ctx, notification)
+}
+
+func (p *PaymentService) ApplyNotification(ctx context.Context, webhook *dto.GatewayNotification) error {
 	var (
 		txErr, err         error
 		paymentAfterUpdate *models.Payment
 		paidAt             *time.Time
 		invoiceLink        string
 		pdf                []byte
+		reviewReason       *string
+		skip               bool
 	)
 
 	err = p.repository.GetTx().Transaction(func(tx *gorm.DB) error {
-		_, txErr = p.repository.GetPayment().FindByOrderID(ctx, webhook.OrderId.String())
+		var payment *models.Payment
+		payment, txErr = p.repository.GetPayment().LockByGatewayOrderID(ctx, tx, webhook.OrderID)
 		if txErr != nil {
 			return txErr
 		}
+		if payment.Provider != webhook.Provider {
+			return errPayment.ErrPaymentNotFound
+		}
+		if *payment.Status == constants.NeedsReview {
+			logrus.Warnf("payment %s is under review, ignoring %s notification", payment.UUID, webhook.TransactionStatus)
+			skip = true
+			return nil
+		}
+		// Another notification for the order may have been applied while this
+		// one waited for the lock.
+		if p.isStale(*payment.Status, webhook.TransactionStatus.GetStatusInt()) {
+			logrus.Infof("payment %s is already %s, ignoring %s notification",
+				payment.UUID, payment.Status.GetStatusString(), webhook.TransactionStatus)
+			skip = true
+			return nil
+		}
+		reviewReason = p.reviewReason(payment, webhook)
+		if reviewReason != nil {
+			paymentAfterUpdate = payment
+			return p.park(ctx, tx, payment, webhook, *reviewReason)
+		}
+		// A payment paid after it expired had its voucher released, it takes
+		// the voucher again unless others have used it up since.
+		if payment.VoucherCode != nil && (*payment.Status == constants.Expire || *payment.Status == constants.Cancel) {
+			txErr = p.voucher.Retake(ctx, tx, payment)
+			if errors.Is(txErr, errVoucher.ErrVoucherUsageLimit) || errors.Is(txErr, errVoucher.ErrVoucherUserLimit) {
+				reason := fmt.Sprintf("paid after it turned %s, voucher %s has been used up since",
+					payment.Status.GetStatusString(), *payment.VoucherCode)
+				reviewReason = &reason
+				paymentAfterUpdate = payment
+				return p.park(ctx, tx, payment, webhook, reason)
+			}
+			if txErr != nil {
+				return txErr
+			}
+		}
 
-		if webhook.TransactionStatus == constants.SettlementString {
+		status := webhook.TransactionStatus.GetStatusInt()
+		refund := status.IsRefund()
+		paidAmount := payment.PaidAmount
+		refundAmount := payment.RefundAmount
+		if refund {
+			// Already validated by reviewReason.
+			refunded, _ := p.refundedAmount(payment, webhook)
+			refundAmount = refunded.Amount
+		}
+		if (payment.AllowPartial && !refund) || status == constants.Settlement {
+			var paid money.Money
+			paid, txErr = p.recordPayments(ctx, tx, payment, webhook)
+			if txErr != nil {
+				return txErr
+			}
+			status = p.paidStatus(payment, paid, status)
+			expired := webhook.TransactionStatus == constants.ExpireString || webhook.TransactionStatus == constants.CancelString
+			if status == constants.PartiallyPaid && expired {
+				outstanding, _ := p.DueAmount(payment).Sub(paid)
+				reason := fmt.Sprintf("payment turned %s with %s outstanding", webhook.TransactionStatus, outstanding.String())
+				reviewReason = &reason
+				paymentAfterUpdate = payment
+				return p.park(ctx, tx, payment, webhook, reason)
+			}
+			paidAmount = paid.Amount
+		}
+		// Gateways repeat notifications, partial payments list every transfer
+		// in each one and card payments report capture before settlement. One
+		// that changes nothing is not worth another history row or event.
+		unchanged := status == *payment.Status && paidAmount == payment.PaidAmount && refundAmount == payment.RefundAmount
+		if unchanged && (payment.AllowPartial || status != constants.Pending) {
+			skip = true
+			return nil
+		}
+		if status == constants.Settlement {
 			now := time.Now()
 			paidAt = &now
+			if webhook.PaidAt != nil {
+				paidAt = webhook.PaidAt
+			}
 		}
-		status := webhook.TransactionStatus.GetStatusInt()
-		vaNumber := webhook.VANumbers[0].VANumber
-		bank := webhook.VANumbers[0].Bank
-		_, txErr = p.repository.GetPayment().Update(ctx, tx, webhook.OrderId.String(), &dto.UpdatePaymentRequest{
-			TransactionId: &webhook.TransactionId,
+		var authorizedAt *time.Time
+		if status == constants.Authorized {
+			now := time.Now()
+			authorizedAt = &now
+		}
+		_, txErr = p.repository.GetPayment().Update(ctx, tx, payment.ID, &dto.UpdatePaymentRequest{
+			TransactionId: &webhook.TransactionID,
 			Status:        &status,
 			PaidAt:        paidAt,
-			VANumber:      &vaNumber,
-			Bank:          &bank,
+			AuthorizedAt:  authorizedAt,
+			RefundAmount:  &refundAmount,
+			VANumber:      webhook.VANumber,
+			Bank:          webhook.Bank,
 			Acquirer:      webhook.Acquirer,
+			MethodDetail:  &webhook.MethodDetail,
 		})
 		if txErr != nil {
 			return txErr
 		}
-		paymentAfterUpdate, txErr = p.repository.GetPayment().FindByOrderID(ctx, webhook.OrderId.String())
+		paymentAfterUpdate, txErr = p.repository.GetPayment().FindByID(ctx, tx, payment.ID)
 		if txErr != nil {
 			return txErr
 		}
@@ -308,30 +1616,69 @@ func (p *PaymentService) Webhook(ctx context.Context, webhook *dto.Webhook) erro
 			PaymentId: paymentAfterUpdate.ID,
 			Status:    paymentAfterUpdate.Status.GetStatusString(),
 		})
+		if txErr != nil {
+			return txErr
+		}
+		if status == constants.Settlement {
+			txErr = p.ledger.PostSettlement(ctx, tx, paymentAfterUpdate, p.DueAmount(paymentAfterUpdate))
+			if txErr != nil {
+				return txErr
+			}
+			txErr = p.applyGatewayFee(ctx, tx, paymentAfterUpdate)
+			if txErr != nil {
+				return txErr
+			}
+		}
+		if payment.VoucherCode != nil {
+			switch status {
+			case constants.Settlement:
+				txErr = p.voucher.Redeem(ctx, tx, paymentAfterUpdate)
+			case constants.Expire, constants.Cancel:
+				txErr = p.voucher.Release(ctx, tx, paymentAfterUpdate)
+			}
+			if txErr != nil {
+				return txErr
+			}
+		}
+		if refundAmount > payment.RefundAmount {
+			refunded := money.New(refundAmount-payment.RefundAmount, payment.Currency)
+			txErr = p.ledger.PostRefund(ctx, tx, paymentAfterUpdate, refunded)
+			if txErr != nil {
+				return txErr
+			}
+		}
+		if webhook.SavedCard != nil && payment.CustomerID != nil {
+			txErr = p.repository.GetPaymentMethod().Save(ctx, tx, &dto.PaymentMethodRequest{
+				CustomerID: *payment.CustomerID,
+				Provider:   payment.Provider,
+				Type:       constants.PaymentMethodCard,
+				SavedCard:  webhook.SavedCard,
+			})
+			if txErr != nil {
+				return txErr
+			}
+		}
 
-		if webhook.TransactionStatus == constants.SettlementString {
+		if status == constants.Settlement {
 			paidDay := paidAt.Format("02")
 			paidMonth := paidAt.Format("January")
 			paidYear := paidAt.Format("2006")
 			invoiceNumber := fmt.Sprintf("INV/%s/ORD/%d", time.Now().Format(time.DateOnly), p.randomNumber())
-			total := utils.RupiahFormat(&paymentAfterUpdate.Amount)
+			total := p.DueAmount(paymentAfterUpdate).Format()
+			paymentDetail := p.invoicePaymentDetail(paymentAfterUpdate)
+			paymentDetail.Date = fmt.Sprintf("%s %s %s", paidDay, paidMonth, paidYear)
+			paymentDetail.IsPaid = true
+			var description string
+			if paymentAfterUpdate.Description != nil {
+				description = *paymentAfterUpdate.Description
+			}
 			invoiceRequest := &dto.InvoiceRequest{
 				InvoiceNumber: invoiceNumber,
 				Data: dto.InvoiceData{
-					PaymentDetail: dto.InvoicePaymentDetail{
-						BankName:      strings.ToUpper(*paymentAfterUpdate.Bank),
-						PaymentMethod: webhook.PaymentType,
-						VANumber:      *paymentAfterUpdate.VANumber,
-						Date:          fmt.Sprintf("%s %s %s", paidDay, paidMonth, paidYear),
-						IsPaid:        true,
-					},
-					Items: []dto.InvoiceItem{
-						{
-							Description: *paymentAfterUpdate.Description,
-							Price:       total,
-						},
-					},
-					Total: total,
+					PaymentDetail: paymentDetail,
+					Items:         p.invoiceItems(paymentAfterUpdate, description),
+					Tax:           p.invoiceTax(paymentAfterUpdate),
+					Total:         total,
 				},
 			}
 			pdf, txErr = p.generatePDF(invoiceRequest)
@@ -342,7 +1689,7 @@ func (p *PaymentService) Webhook(ctx context.Context, webhook *dto.Webhook) erro
 			if txErr != nil {
 				return txErr
 			}
-			_, txErr = p.repository.GetPayment().Update(ctx, tx, webhook.OrderId.String(), &dto.UpdatePaymentRequest{
+			_, txErr = p.repository.GetPayment().Update(ctx, tx, payment.ID, &dto.UpdatePaymentRequest{
 				InvoiceLink: &invoiceLink,
 			})
 			if txErr != nil {
@@ -354,9 +1701,165 @@ func (p *PaymentService) Webhook(ctx context.Context, webhook *dto.Webhook) erro
 	if err != nil {
 		return err
 	}
-	err = p.produceToKafka(webhook, paymentAfterUpdate, paidAt)
+	if skip {
+		return nil
+	}
+	if reviewReason != nil {
+		return p.produceAlert(paymentAfterUpdate, webhook, *reviewReason)
+	}
+	err = p.produceToKafka(paymentAfterUpdate, paidAt)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// isStale tells whether a notification arrived after the payment moved past
+// it. A paid payment only takes refunds, and a closed one is not reopened,
+// although money that arrived late still settles it.
+func (p *PaymentService) isStale(current, notified constants.PaymentStatus) bool {
+	switch current {
+	case constants.Settlement, constants.PartiallyRefunded, constants.Refunded:
+		return !notified.IsRefund()
+	case constants.Expire, constants.Cancel:
+		return notified != constants.Settlement
+	}
+	return false
+}
+
+// Capture takes the funds of an authorised card payment, all of them unless a
+// lower amount is given.
+func (p *PaymentService) Capture(ctx context.Context, uuid string, request *dto.CapturePaymentRequest) (*dto.PaymentResponse, error) {
+	payment, err := p.repository.GetPayment().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if *payment.Status != constants.Authorized || payment.TransactionID == nil {
+		return nil, errPayment.ErrPaymentNotAuthorized
+	}
+	amount := p.amount(payment)
+	if request.Amount != nil {
+		amount = money.FromMajor(*request.Amount, payment.Currency)
+		if amount.Amount <= 0 || amount.Amount > payment.Amount {
+			return nil, errPayment.ErrCaptureAmountInvalid
+		}
+	}
+	authorizer, err := p.authorizer(payment)
+	if err != nil {
+		return nil, err
+	}
+
+	// The amount is stored before the gateway is asked, with the payment held,
+	// so the capture notification, whether it is the response below or the
+	// webhook that waits for the lock, is checked against it. A refused
+	// capture rolls the amount back with the transaction.
+	var notification *dto.GatewayNotification
+	err = p.repository.GetTx().Transaction(func(tx *gorm.DB) error {
+		locked, txErr := p.repository.GetPayment().LockByGatewayOrderID(ctx, tx, payment.GatewayOrderID)
+		if txErr != nil {
+			return txErr
+		}
+		if *locked.Status != constants.Authorized {
+			return errPayment.ErrPaymentNotAuthorized
+		}
+		txErr = p.repository.GetPayment().UpdateCaptureAmount(ctx, tx, locked.ID, &amount.Amount)
+		if txErr != nil {
+			return txErr
+		}
+		notification, txErr = authorizer.Capture(&dto.GatewayCaptureRequest{
+			TransactionID: *payment.TransactionID,
+			Amount:        amount.Major(),
+		})
+		return txErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = p.ApplyNotification(ctx, notification)
+	if err != nil {
+		return nil, err
+	}
+	return p.GetByUUID(ctx, uuid)
+}
+
+func (p *PaymentService) Void(ctx context.Context, uuid string) (*dto.PaymentResponse, error) {
+	payment, err := p.repository.GetPayment().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	err = p.void(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	return p.GetByUUID(ctx, uuid)
+}
+
+// VoidStaleAuthorizations releases the authorisations nobody captured within
+// authorization.voidAfterHours.
+func (p *PaymentService) VoidStaleAuthorizations(ctx context.Context) (int, error) {
+	voidAfter := defaultVoidAfter
+	if hours := config2.Config.Authorization.VoidAfterHours; hours > 0 {
+		voidAfter = time.Duration(hours) * time.Hour
+	}
+	payments, err := p.repository.GetPayment().FindAuthorizedBefore(ctx, time.Now().Add(-voidAfter))
+	if err != nil {
+		return 0, err
+	}
+
+	var voided int
+	for _, payment := range payments {
+		err = p.void(ctx, &payment)
+		if err != nil {
+			logrus.Errorf("failed to void stale authorization of payment %s: %v", payment.UUID, err)
+			continue
+		}
+		voided++
+	}
+	return voided, nil
+}
+
+func (p *PaymentService) void(ctx context.Context, payment *models.Payment) error {
+	if *payment.Status != constants.Authorized {
+		return errPayment.ErrPaymentNotAuthorized
+	}
+	authorizer, err := p.authorizer(payment)
+	if err != nil {
+		return err
+	}
+	notification, err := authorizer.Void(payment.GatewayOrderID)
+	if err != nil {
+		return err
+	}
+	return p.ApplyNotification(ctx, notification)
+}
+
+func (p *PaymentService) authorizer(payment *models.Payment) (clients.Authorizer, error) {
+	gateway, err := p.gateway.Get(payment.Provider)
+	if err != nil {
+		return nil, err
+	}
+	authorizer, ok := gateway.(clients.Authorizer)
+	if !ok {
+		return nil, errGateway.ErrGatewayNotSupported
+	}
+	return authorizer, nil
+}
+
+// route sends authorisations to Midtrans, the only gateway that can capture
+// them later, every other payment goes through the routing rules.
+func (p *PaymentService) route(request *dto.PaymentRequest) (clients.PaymentGateway, *dto.GatewayDecision, error) {
+	if !request.Authorize {
+		return p.gateway.Route(request)
+	}
+	gateway, err := p.gateway.Get(constants.ProviderMidtrans)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := gateway.(clients.Authorizer); !ok {
+		return nil, nil, errGateway.ErrGatewayNotSupported
+	}
+	return gateway, &dto.GatewayDecision{
+		Provider: gateway.Provider(),
+		Reason:   "card authorisations are served by midtrans",
+	}, nil
+}
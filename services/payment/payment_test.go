@@ -0,0 +1,1077 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	clients "payment-service/clients/gateway"
+	"payment-service/common/fx"
+	"payment-service/common/money"
+	"payment-service/config"
+	"payment-service/constants"
+	errGateway "payment-service/constants/error/gateway"
+	errPayment "payment-service/constants/error/payment"
+	errVoucher "payment-service/constants/error/voucher"
+	"payment-service/controllers/kafka"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories/fake"
+	repositories "payment-service/repositories/payment"
+	repositories2 "payment-service/repositories/payment_history"
+	repositories4 "payment-service/repositories/payment_method"
+	repositories5 "payment-service/repositories/payment_split"
+	repositories3 "payment-service/repositories/payment_transaction"
+	services "payment-service/services/voucher"
+)
+
+func status(value constants.PaymentStatus) *constants.PaymentStatus {
+	return &value
+}
+
+func amount(value int64) *int64 {
+	return &value
+}
+
+// fakePaymentRepository keeps the payments of a single order in memory.
+type fakePaymentRepository struct {
+	repositories.IPaymentRepository
+	payments []*models.Payment
+	created  []*dto.PaymentRequest
+}
+
+func (f *fakePaymentRepository) LockOrder(context.Context, *gorm.DB, string) error {
+	return nil
+}
+
+func (f *fakePaymentRepository) FindByOrderID(context.Context, string) (*models.Payment, error) {
+	if len(f.payments) == 0 {
+		return nil, errPayment.ErrPaymentNotFound
+	}
+	return f.payments[len(f.payments)-1], nil
+}
+
+func (f *fakePaymentRepository) LockByGatewayOrderID(_ context.Context, _ *gorm.DB, orderID string) (*models.Payment, error) {
+	for _, payment := range f.payments {
+		if payment.GatewayOrderID == orderID {
+			return payment, nil
+		}
+	}
+	return nil, errPayment.ErrPaymentNotFound
+}
+
+func (f *fakePaymentRepository) FindByUUID(_ context.Context, id string) (*models.Payment, error) {
+	for _, payment := range f.payments {
+		if payment.UUID.String() == id {
+			return payment, nil
+		}
+	}
+	return nil, errPayment.ErrPaymentNotFound
+}
+
+func (f *fakePaymentRepository) FindByID(_ context.Context, _ *gorm.DB, id uint) (*models.Payment, error) {
+	return f.payments[id-1], nil
+}
+
+func (f *fakePaymentRepository) Create(_ context.Context, _ *gorm.DB, request *dto.PaymentRequest) (*models.Payment, error) {
+	payment := &models.Payment{
+		ID:             uint(len(f.payments) + 1),
+		Provider:       request.Provider,
+		GatewayOrderID: request.GatewayOrderID,
+		Attempt:        request.Attempt,
+		Amount:         money.FromMajor(request.Amount, constants.DefaultCurrency).Amount,
+		Currency:       constants.DefaultCurrency,
+		PaymentLink:    request.PaymentLink,
+		Status:         status(constants.Initial),
+	}
+	if request.Status != nil {
+		payment.Status = request.Status
+	}
+	if request.Instruction != nil {
+		payment.VANumber = request.Instruction.VANumber
+		payment.Bank = request.Instruction.Bank
+	}
+	f.payments = append(f.payments, payment)
+	f.created = append(f.created, request)
+	return payment, nil
+}
+
+func (f *fakePaymentRepository) Update(
+	_ context.Context,
+	_ *gorm.DB,
+	id uint,
+	request *dto.UpdatePaymentRequest,
+) (*models.Payment, error) {
+	payment := f.payments[id-1]
+	if request.Status != nil {
+		payment.Status = request.Status
+	}
+	if request.ReviewReason != nil {
+		payment.ReviewReason = request.ReviewReason
+	}
+	if request.PaidAmount != nil {
+		payment.PaidAmount = *request.PaidAmount
+	}
+	if request.CaptureAmount != nil {
+		payment.CaptureAmount = request.CaptureAmount
+	}
+	return payment, nil
+}
+
+func (f *fakePaymentRepository) UpdateCaptureAmount(_ context.Context, _ *gorm.DB, id uint, amount *int64) error {
+	f.payments[id-1].CaptureAmount = amount
+	return nil
+}
+
+type fakePaymentHistoryRepository struct {
+	repositories2.IPaymentHistoryRepository
+}
+
+func (f *fakePaymentHistoryRepository) Create(context.Context, *gorm.DB, *dto.PaymentHistoryRequest) error {
+	return nil
+}
+
+// fakePaymentTransactionRepository ignores a transfer already recorded under
+// the same reference like the real one.
+type fakePaymentTransactionRepository struct {
+	repositories3.IPaymentTransactionRepository
+	amounts map[string]int64
+}
+
+func (f *fakePaymentTransactionRepository) Create(_ context.Context, _ *gorm.DB, request *dto.PaymentTransactionRequest) error {
+	if _, ok := f.amounts[request.Reference]; !ok {
+		f.amounts[request.Reference] = request.Amount.Amount
+	}
+	return nil
+}
+
+func (f *fakePaymentTransactionRepository) FindByPaymentID(context.Context, uint) ([]models.PaymentTransaction, error) {
+	return nil, nil
+}
+
+func (f *fakePaymentTransactionRepository) SumByPaymentID(context.Context, *gorm.DB, uint) (int64, error) {
+	var total int64
+	for _, amount := range f.amounts {
+		total += amount
+	}
+	return total, nil
+}
+
+type fakePaymentMethodRepository struct {
+	repositories4.IPaymentMethodRepository
+	method *models.PaymentMethod
+	used   bool
+}
+
+func (f *fakePaymentMethodRepository) FindByUUID(context.Context, string) (*models.PaymentMethod, error) {
+	return f.method, nil
+}
+
+func (f *fakePaymentMethodRepository) MarkUsed(context.Context, uint, time.Time) error {
+	f.used = true
+	return nil
+}
+
+type fakePaymentSplitRepository struct {
+	repositories5.IPaymentSplitRepository
+}
+
+func (f *fakePaymentSplitRepository) Create(context.Context, *gorm.DB, []dto.PaymentSplitRequest) error {
+	return nil
+}
+
+func (f *fakePaymentSplitRepository) FindByPaymentID(context.Context, *gorm.DB, uint) ([]models.PaymentSplit, error) {
+	return nil, nil
+}
+
+type fakeVoucherService struct {
+	services.IVoucherService
+	retaken []uint
+	err     error
+}
+
+func (f *fakeVoucherService) Retake(_ context.Context, _ *gorm.DB, payment *models.Payment) error {
+	f.retaken = append(f.retaken, payment.ID)
+	return f.err
+}
+
+type fakeKafka struct {
+	messages [][]byte
+}
+
+func (f *fakeKafka) GetKafkaProducer() kafka.IKafka {
+	return f
+}
+
+func (f *fakeKafka) ProduceMessage(_ string, message []byte) error {
+	f.messages = append(f.messages, message)
+	return nil
+}
+
+type fakeGateway struct {
+	clients.PaymentGateway
+	requests []*dto.PaymentRequest
+}
+
+func (f *fakeGateway) Provider() string {
+	return constants.ProviderMidtrans
+}
+
+func (f *fakeGateway) SupportsCurrency(currency string) bool {
+	return currency == constants.DefaultCurrency
+}
+
+func (f *fakeGateway) CreatePaymentLink(request *dto.PaymentRequest) (*dto.GatewayPaymentLink, error) {
+	f.requests = append(f.requests, request)
+	return &dto.GatewayPaymentLink{RedirectURL: "https://pay.example/" + request.GatewayOrderID}, nil
+}
+
+// fakeCharger charges virtual accounts directly like the Midtrans Core API.
+type fakeCharger struct {
+	fakeGateway
+	cancelled []string
+}
+
+func (f *fakeCharger) Charge(request *dto.PaymentRequest) (*dto.GatewayCharge, error) {
+	f.requests = append(f.requests, request)
+	bank := strings.TrimSuffix(request.PaymentMethod, "_va")
+	vaNumber := "8808" + request.GatewayOrderID
+	return &dto.GatewayCharge{
+		TransactionID: "tx-" + request.GatewayOrderID,
+		Instruction:   dto.PaymentInstruction{VANumber: &vaNumber, Bank: &bank},
+	}, nil
+}
+
+func (f *fakeCharger) Cancel(orderID string) error {
+	f.cancelled = append(f.cancelled, orderID)
+	return nil
+}
+
+// fakeAuthorizer answers captures and voids like the Midtrans Core API.
+type fakeAuthorizer struct {
+	fakeGateway
+	captured []*dto.GatewayCaptureRequest
+	err      error
+	// capturing is called while the gateway handles the capture.
+	capturing func()
+}
+
+func (f *fakeAuthorizer) Capture(request *dto.GatewayCaptureRequest) (*dto.GatewayNotification, error) {
+	f.captured = append(f.captured, request)
+	if f.capturing != nil {
+		f.capturing()
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &dto.GatewayNotification{
+		Provider:          constants.ProviderMidtrans,
+		TransactionStatus: constants.SettlementString,
+		GrossAmount:       fmt.Sprintf("%.2f", request.Amount),
+	}, nil
+}
+
+func (f *fakeAuthorizer) Void(orderID string) (*dto.GatewayNotification, error) {
+	return &dto.GatewayNotification{
+		Provider:          constants.ProviderMidtrans,
+		OrderID:           orderID,
+		TransactionStatus: constants.CancelString,
+	}, nil
+}
+
+func items(amount float64) []dto.ItemDetail {
+	return []dto.ItemDetail{{ID: "item", Name: "Item", Amount: amount, Quantity: 1}}
+}
+
+func newService(gateway clients.PaymentGateway, payments ...*models.Payment) (*PaymentService, *fakePaymentRepository) {
+	repository := &fakePaymentRepository{payments: payments}
+	return &PaymentService{
+		repository: &fake.Registry{
+			DB:                 fake.DB(),
+			Payment:            repository,
+			PaymentHistory:     &fakePaymentHistoryRepository{},
+			PaymentTransaction: &fakePaymentTransactionRepository{amounts: map[string]int64{}},
+			PaymentSplit:       &fakePaymentSplitRepository{},
+		},
+		kafka:   &fakeKafka{},
+		gateway: clients.NewGatewayRegistry(config.Gateway{DefaultProvider: constants.ProviderMidtrans}, gateway),
+		fx:      fx.NewStaticProvider(constants.DefaultCurrency, map[string]float64{"SGD": 11800}),
+	}, repository
+}
+
+func newCreateService(payments ...*models.Payment) (*PaymentService, *fakePaymentRepository, *fakeGateway) {
+	gateway := &fakeGateway{}
+	service, repository := newService(gateway, payments...)
+	return service, repository, gateway
+}
+
+func TestCreateReusesActivePayment(t *testing.T) {
+	expiredAt := time.Now().Add(time.Hour)
+	active := &models.Payment{ID: 1, Attempt: 1, PaymentLink: "https://pay.example/order", Status: status(constants.Pending), ExpiredAt: &expiredAt}
+	service, repository, gateway := newCreateService(active)
+
+	response, err := service.Create(context.Background(), &dto.PaymentRequest{OrderID: "order", Amount: 10000, ItemDetails: items(10000), ExpiredAt: expiredAt})
+	if err != nil {
+		t.Fatalf("Create unexpected error: %v", err)
+	}
+	if len(gateway.requests) != 0 || len(repository.payments) != 1 {
+		t.Errorf("Create opened %d payment links, want the active one reused", len(gateway.requests))
+	}
+	if response.PaymentLink != active.PaymentLink || response.Attempt != 1 {
+		t.Errorf("response = %+v, want the link of the active attempt", response)
+	}
+}
+
+func TestCreateSupersedesExpiredAttempt(t *testing.T) {
+	expiredAt := time.Now().Add(-time.Minute)
+	stale := &models.Payment{ID: 1, Attempt: 1, Status: status(constants.Pending), ExpiredAt: &expiredAt}
+	service, repository, gateway := newCreateService(stale)
+
+	response, err := service.Create(context.Background(), &dto.PaymentRequest{
+		OrderID:     "order",
+		Amount:      10000,
+		ItemDetails: items(10000),
+		ExpiredAt:   time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Create unexpected error: %v", err)
+	}
+	if *stale.Status != constants.Expire {
+		t.Errorf("previous attempt status = %v, want expire", *stale.Status)
+	}
+	// Every attempt needs its own order id at the gateway.
+	if len(gateway.requests) != 1 || gateway.requests[0].GatewayOrderID != "order-2" {
+		t.Fatalf("payment link requests = %+v, want one for order-2", gateway.requests)
+	}
+	if response.Attempt != 2 || len(repository.payments) != 2 {
+		t.Errorf("response = %+v, want a second attempt", response)
+	}
+}
+
+func TestCreateRejectsSettledOrder(t *testing.T) {
+	service, _, gateway := newCreateService(&models.Payment{ID: 1, Attempt: 1, Status: status(constants.Settlement)})
+
+	_, err := service.Create(context.Background(), &dto.PaymentRequest{OrderID: "order", ExpiredAt: time.Now().Add(time.Hour)})
+	if !errors.Is(err, errPayment.ErrPaymentAlreadySettled) {
+		t.Errorf("Create error = %v, want %v", err, errPayment.ErrPaymentAlreadySettled)
+	}
+	if len(gateway.requests) != 0 {
+		t.Errorf("Create opened a payment link for a settled order")
+	}
+}
+
+func TestChargeReturnsInstructions(t *testing.T) {
+	charger := &fakeCharger{}
+	service, repository := newService(charger)
+
+	response, err := service.Charge(context.Background(), &dto.ChargeRequest{
+		PaymentRequest: dto.PaymentRequest{
+			OrderID:     "order",
+			Amount:      10000,
+			ItemDetails: items(10000),
+			ExpiredAt:   time.Now().Add(time.Hour),
+		},
+		PaymentMethod: constants.PaymentMethodBCAVA,
+	})
+	if err != nil {
+		t.Fatalf("Charge unexpected error: %v", err)
+	}
+	if response.Status != constants.PendingString || response.Provider != constants.ProviderMidtrans {
+		t.Errorf("response = %+v, want a pending midtrans payment", response)
+	}
+	if response.VANumber == nil || *response.VANumber != "8808order" || response.Bank == nil || *response.Bank != "bca" {
+		t.Errorf("instruction = %v %v, want the bca virtual account", response.VANumber, response.Bank)
+	}
+	if created := repository.created[0]; created.PaymentMethod != constants.PaymentMethodBCAVA || created.GatewayReference != "tx-order" {
+		t.Errorf("stored %+v, want the method and the gateway transaction", created)
+	}
+}
+
+func TestChargeCancelsAttemptWithOtherMethod(t *testing.T) {
+	expiredAt := time.Now().Add(time.Hour)
+	method := constants.PaymentMethodQRIS
+	active := &models.Payment{
+		ID:             1,
+		Attempt:        1,
+		Provider:       constants.ProviderMidtrans,
+		GatewayOrderID: "order",
+		PaymentMethod:  &method,
+		Status:         status(constants.Pending),
+		ExpiredAt:      &expiredAt,
+	}
+	charger := &fakeCharger{}
+	service, _ := newService(charger, active)
+
+	response, err := service.Charge(context.Background(), &dto.ChargeRequest{
+		PaymentRequest: dto.PaymentRequest{OrderID: "order", Amount: 10000, ItemDetails: items(10000), ExpiredAt: expiredAt},
+		PaymentMethod:  constants.PaymentMethodBNIVA,
+	})
+	if err != nil {
+		t.Fatalf("Charge unexpected error: %v", err)
+	}
+	// The qris attempt could still be paid, so it is closed at the gateway.
+	if len(charger.cancelled) != 1 || charger.cancelled[0] != "order" || *active.Status != constants.Cancel {
+		t.Errorf("cancelled %v with status %v, want the qris attempt cancelled", charger.cancelled, *active.Status)
+	}
+	if response.Attempt != 2 || len(charger.requests) != 1 || charger.requests[0].GatewayOrderID != "order-2" {
+		t.Errorf("response = %+v, want a second attempt charged as order-2", response)
+	}
+}
+
+func TestChargeRequiresDirectCharger(t *testing.T) {
+	service, _ := newService(&fakeGateway{})
+
+	_, err := service.Charge(context.Background(), &dto.ChargeRequest{
+		PaymentRequest: dto.PaymentRequest{
+			OrderID:     "order",
+			Amount:      10000,
+			ItemDetails: items(10000),
+			ExpiredAt:   time.Now().Add(time.Hour),
+		},
+		PaymentMethod: constants.PaymentMethodQRIS,
+	})
+	if !errors.Is(err, errGateway.ErrGatewayNotSupported) {
+		t.Errorf("Charge error = %v, want %v", err, errGateway.ErrGatewayNotSupported)
+	}
+}
+
+func TestCreateValidatesRequest(t *testing.T) {
+	expiredAt := time.Now().Add(time.Hour)
+	startTime := expiredAt.Add(time.Minute)
+	tests := []struct {
+		name    string
+		request dto.PaymentRequest
+		want    error
+	}{
+		{
+			name: "item total below the amount",
+			request: dto.PaymentRequest{
+				Amount:      10000,
+				ItemDetails: []dto.ItemDetail{{ID: "item", Name: "Item", Amount: 4000, Quantity: 2}},
+				ExpiredAt:   expiredAt,
+			},
+			want: errPayment.ErrItemTotalMismatch,
+		},
+		{
+			name: "item total under a cent off",
+			request: dto.PaymentRequest{
+				Amount:      10000.009,
+				ItemDetails: items(10000),
+				ExpiredAt:   expiredAt,
+			},
+			want: errPayment.ErrItemTotalMismatch,
+		},
+		{
+			name: "expiry before its start time",
+			request: dto.PaymentRequest{
+				Amount:          10000,
+				ItemDetails:     items(10000),
+				ExpiredAt:       expiredAt,
+				ExpiryStartTime: &startTime,
+			},
+			want: errPayment.ErrExpireAtInvalid,
+		},
+		{
+			name: "currency no gateway takes",
+			request: dto.PaymentRequest{
+				Amount:      100,
+				Currency:    "SGD",
+				ItemDetails: items(100),
+				ExpiredAt:   expiredAt,
+			},
+			want: errPayment.ErrCurrencyNotSupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, gateway := newCreateService()
+			tt.request.OrderID = "order"
+			_, err := service.Create(context.Background(), &tt.request)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Create error = %v, want %v", err, tt.want)
+			}
+			if len(gateway.requests) != 0 {
+				t.Errorf("Create reached the gateway with an invalid request")
+			}
+		})
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	service := &PaymentService{}
+	tests := []struct {
+		current  constants.PaymentStatus
+		notified constants.PaymentStatus
+		want     bool
+	}{
+		{current: constants.Pending, notified: constants.Settlement, want: false},
+		{current: constants.PartiallyPaid, notified: constants.Settlement, want: false},
+		{current: constants.Authorized, notified: constants.Settlement, want: false},
+		{current: constants.Settlement, notified: constants.Settlement, want: true},
+		{current: constants.Settlement, notified: constants.Expire, want: true},
+		{current: constants.Settlement, notified: constants.Pending, want: true},
+		{current: constants.Settlement, notified: constants.PartiallyRefunded, want: false},
+		{current: constants.Settlement, notified: constants.Refunded, want: false},
+		{current: constants.PartiallyRefunded, notified: constants.Refunded, want: false},
+		{current: constants.Refunded, notified: constants.Settlement, want: true},
+		{current: constants.Expire, notified: constants.Settlement, want: false},
+		{current: constants.Expire, notified: constants.Cancel, want: true},
+		{current: constants.Cancel, notified: constants.Pending, want: true},
+	}
+	for _, tt := range tests {
+		name := string(tt.current.GetStatusString()) + " to " + string(tt.notified.GetStatusString())
+		t.Run(name, func(t *testing.T) {
+			if got := service.isStale(tt.current, tt.notified); got != tt.want {
+				t.Errorf("isStale = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewReason(t *testing.T) {
+	service := &PaymentService{}
+	tests := []struct {
+		name    string
+		payment models.Payment
+		webhook dto.GatewayNotification
+		want    string
+	}{
+		{
+			name:    "matching settlement",
+			payment: models.Payment{Amount: 1000000, Currency: "IDR", Status: status(constants.Pending)},
+			webhook: dto.GatewayNotification{TransactionStatus: constants.SettlementString, GrossAmount: "10000.00", Currency: "IDR"},
+		},
+		{
+			name:    "other currency",
+			payment: models.Payment{Amount: 1000000, Currency: "IDR", Status: status(constants.Pending)},
+			webhook: dto.GatewayNotification{TransactionStatus: constants.SettlementString, GrossAmount: "10000.00", Currency: "USD"},
+			want:    "currency",
+		},
+		{
+			name:    "other amount",
+			payment: models.Payment{Amount: 1000000, Currency: "IDR", Status: status(constants.Pending)},
+			webhook: dto.GatewayNotification{TransactionStatus: constants.SettlementString, GrossAmount: "9000.00"},
+			want:    "gross amount",
+		},
+		{
+			name:    "invalid amount",
+			payment: models.Payment{Amount: 1000000, Currency: "IDR", Status: status(constants.Pending)},
+			webhook: dto.GatewayNotification{TransactionStatus: constants.SettlementString, GrossAmount: "ten"},
+			want:    "not a valid amount",
+		},
+		{
+			name:    "captured amount",
+			payment: models.Payment{Amount: 1000000, CaptureAmount: amount(600000), Currency: "IDR", Status: status(constants.Authorized)},
+			webhook: dto.GatewayNotification{TransactionStatus: constants.SettlementString, GrossAmount: "6000.00"},
+		},
+		{
+			name:    "installments above amount",
+			payment: models.Payment{Amount: 1000000, Currency: "IDR", AllowPartial: true, Status: status(constants.PartiallyPaid)},
+			webhook: dto.GatewayNotification{
+				TransactionStatus: constants.SettlementString,
+				PaymentAmounts:    []dto.GatewayPaidAmount{{Amount: "6000.00"}, {Amount: "5000.00"}},
+			},
+			want: "exceed",
+		},
+		{
+			name:    "partial refund",
+			payment: models.Payment{Amount: 1000000, Currency: "IDR", Status: status(constants.Settlement)},
+			webhook: dto.GatewayNotification{TransactionStatus: constants.PartialRefundString, GrossAmount: "10000.00", RefundAmount: "4000.00"},
+		},
+		{
+			name:    "refund above amount",
+			payment: models.Payment{Amount: 1000000, Currency: "IDR", Status: status(constants.Settlement)},
+			webhook: dto.GatewayNotification{TransactionStatus: constants.PartialRefundString, GrossAmount: "10000.00", RefundAmount: "12000.00"},
+			want:    "exceed",
+		},
+		{
+			name:    "refund before settlement",
+			payment: models.Payment{Amount: 1000000, Currency: "IDR", Status: status(constants.Pending)},
+			webhook: dto.GatewayNotification{TransactionStatus: constants.RefundString, GrossAmount: "10000.00"},
+			want:    "refund notified for a pending payment",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := service.reviewReason(&tt.payment, &tt.webhook)
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("reviewReason = %q, want none", *got)
+			case tt.want != "" && got == nil:
+				t.Errorf("reviewReason = none, want one containing %q", tt.want)
+			case tt.want != "" && !strings.Contains(*got, tt.want):
+				t.Errorf("reviewReason = %q, want one containing %q", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyNotificationParksMismatch(t *testing.T) {
+	expiredAt := time.Now().Add(time.Hour)
+	payment := &models.Payment{
+		ID:             1,
+		Provider:       constants.ProviderMidtrans,
+		GatewayOrderID: "order",
+		Amount:         1000000,
+		Currency:       "IDR",
+		Status:         status(constants.Pending),
+		ExpiredAt:      &expiredAt,
+	}
+	service, _ := newService(&fakeGateway{}, payment)
+
+	err := service.ApplyNotification(context.Background(), &dto.GatewayNotification{
+		Provider:          constants.ProviderMidtrans,
+		OrderID:           "order",
+		TransactionStatus: constants.SettlementString,
+		GrossAmount:       "1000.00",
+	})
+	if err != nil {
+		t.Fatalf("ApplyNotification unexpected error: %v", err)
+	}
+	if *payment.Status != constants.NeedsReview || payment.ReviewReason == nil {
+		t.Errorf("status = %v with reason %v, want needs review", *payment.Status, payment.ReviewReason)
+	}
+	messages := service.kafka.(*fakeKafka).messages
+	if len(messages) != 1 || !strings.Contains(string(messages[0]), strings.ToUpper(constants.NeedsReviewString.String())) {
+		t.Errorf("produced %d messages, want one admin alert", len(messages))
+	}
+
+	// Later notifications leave a payment under review alone.
+	err = service.ApplyNotification(context.Background(), &dto.GatewayNotification{
+		Provider:          constants.ProviderMidtrans,
+		OrderID:           "order",
+		TransactionStatus: constants.SettlementString,
+		GrossAmount:       "10000.00",
+	})
+	if err != nil || *payment.Status != constants.NeedsReview {
+		t.Errorf("second notification gave %v with status %v, want it ignored", err, *payment.Status)
+	}
+}
+
+func TestApplyNotificationParksLatePaymentWithUsedUpVoucher(t *testing.T) {
+	expiredAt := time.Now().Add(-time.Minute)
+	code := "HEMAT5K"
+	payment := &models.Payment{
+		ID:             1,
+		Provider:       constants.ProviderMidtrans,
+		GatewayOrderID: "order",
+		Amount:         1000000,
+		Currency:       "IDR",
+		VoucherCode:    &code,
+		Status:         status(constants.Expire),
+		ExpiredAt:      &expiredAt,
+	}
+	service, _ := newService(&fakeGateway{}, payment)
+	voucher := &fakeVoucherService{err: errVoucher.ErrVoucherUsageLimit}
+	service.voucher = voucher
+
+	err := service.ApplyNotification(context.Background(), &dto.GatewayNotification{
+		Provider:          constants.ProviderMidtrans,
+		OrderID:           "order",
+		TransactionStatus: constants.SettlementString,
+		GrossAmount:       "10000.00",
+	})
+	if err != nil {
+		t.Fatalf("ApplyNotification unexpected error: %v", err)
+	}
+	if len(voucher.retaken) != 1 {
+		t.Errorf("voucher retaken %d times, want once", len(voucher.retaken))
+	}
+	if *payment.Status != constants.NeedsReview || payment.ReviewReason == nil {
+		t.Errorf("status = %v with reason %v, want needs review", *payment.Status, payment.ReviewReason)
+	}
+}
+
+func TestMapTransactionStatusToEvent(t *testing.T) {
+	service := &PaymentService{}
+	tests := []struct {
+		status constants.PaymentStatusString
+		want   string
+	}{
+		{status: constants.PendingString, want: "PENDING"},
+		{status: constants.PartiallyPaidString, want: "PARTIALLY_PAID"},
+		{status: constants.AuthorizedString, want: "AUTHORIZED"},
+		{status: constants.SettlementString, want: "SETTLEMENT"},
+		{status: constants.PartialRefundString, want: "PARTIAL_REFUND"},
+		{status: constants.RefundString, want: "REFUND"},
+		{status: constants.ExpireString, want: "EXPIRE"},
+		{status: constants.CancelString, want: "CANCEL"},
+		{status: constants.InitialString, want: ""},
+	}
+	for _, tt := range tests {
+		if got := service.mapTransactionStatusToEvent(tt.status); got != tt.want {
+			t.Errorf("mapTransactionStatusToEvent(%s) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestPaidStatus(t *testing.T) {
+	service := &PaymentService{}
+	payment := &models.Payment{Amount: 1000000, Currency: "IDR", AllowPartial: true}
+	captured := &models.Payment{Amount: 1000000, CaptureAmount: amount(600000), Currency: "IDR"}
+	tests := []struct {
+		name    string
+		payment *models.Payment
+		paid    int64
+		want    constants.PaymentStatus
+	}{
+		{name: "nothing paid", payment: payment, paid: 0, want: constants.Pending},
+		{name: "installment", payment: payment, paid: 400000, want: constants.PartiallyPaid},
+		{name: "paid in full", payment: payment, paid: 1000000, want: constants.Settlement},
+		{name: "overpaid", payment: payment, paid: 1200000, want: constants.Settlement},
+		{name: "captured for less", payment: captured, paid: 600000, want: constants.Settlement},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := service.paidStatus(tt.payment, money.New(tt.paid, "IDR"), constants.Pending)
+			if got != tt.want {
+				t.Errorf("paidStatus = %s, want %s", got.GetStatusString(), tt.want.GetStatusString())
+			}
+		})
+	}
+}
+
+func TestOutstandingAmount(t *testing.T) {
+	service := &PaymentService{}
+	tests := []struct {
+		name    string
+		payment models.Payment
+		want    int64
+	}{
+		{name: "unpaid", payment: models.Payment{Amount: 1000000, Currency: "IDR"}, want: 1000000},
+		{name: "installment", payment: models.Payment{Amount: 1000000, PaidAmount: 400000, Currency: "IDR"}, want: 600000},
+		{name: "overpaid", payment: models.Payment{Amount: 1000000, PaidAmount: 1200000, Currency: "IDR"}, want: 0},
+		{name: "captured for less", payment: models.Payment{Amount: 1000000, CaptureAmount: amount(600000), Currency: "IDR"}, want: 600000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := service.OutstandingAmount(&tt.payment); got.Amount != tt.want {
+				t.Errorf("OutstandingAmount = %d, want %d", got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyNotificationRecordsInstallments(t *testing.T) {
+	expiredAt := time.Now().Add(time.Hour)
+	payment := &models.Payment{
+		ID:             1,
+		Provider:       constants.ProviderMidtrans,
+		GatewayOrderID: "order",
+		Amount:         1000000,
+		Currency:       "IDR",
+		AllowPartial:   true,
+		Status:         status(constants.Pending),
+		ExpiredAt:      &expiredAt,
+	}
+	service, _ := newService(&fakeGateway{}, payment)
+	notification := &dto.GatewayNotification{
+		Provider:          constants.ProviderMidtrans,
+		OrderID:           "order",
+		TransactionID:     "tx-1",
+		TransactionStatus: constants.PendingString,
+		PaymentAmounts:    []dto.GatewayPaidAmount{{Amount: "4000.00"}},
+	}
+
+	err := service.ApplyNotification(context.Background(), notification)
+	if err != nil {
+		t.Fatalf("ApplyNotification unexpected error: %v", err)
+	}
+	if *payment.Status != constants.PartiallyPaid || payment.PaidAmount != 400000 {
+		t.Errorf("payment is %s with %d paid, want partially paid with 400000", payment.Status.GetStatusString(), payment.PaidAmount)
+	}
+	messages := service.kafka.(*fakeKafka).messages
+	if len(messages) != 1 || !strings.Contains(string(messages[0]), "PARTIALLY_PAID") {
+		t.Fatalf("produced %d messages, want one partially paid event", len(messages))
+	}
+
+	// The same transfer notified again changes nothing.
+	err = service.ApplyNotification(context.Background(), notification)
+	if err != nil {
+		t.Fatalf("ApplyNotification unexpected error: %v", err)
+	}
+	if payment.PaidAmount != 400000 || len(service.kafka.(*fakeKafka).messages) != 1 {
+		t.Errorf("repeated transfer counted again, paid %d", payment.PaidAmount)
+	}
+
+	// Expiring with transfers made leaves the outstanding amount to an admin.
+	notification.TransactionStatus = constants.ExpireString
+	err = service.ApplyNotification(context.Background(), notification)
+	if err != nil {
+		t.Fatalf("ApplyNotification unexpected error: %v", err)
+	}
+	outstanding := money.New(600000, "IDR").String()
+	if *payment.Status != constants.NeedsReview || payment.ReviewReason == nil || !strings.Contains(*payment.ReviewReason, outstanding) {
+		t.Errorf("status = %v with reason %v, want needs review with %s outstanding", *payment.Status, payment.ReviewReason, outstanding)
+	}
+}
+
+func TestCreateChargesSavedMethod(t *testing.T) {
+	customerID := uuid.New()
+	expired := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name   string
+		method models.PaymentMethod
+		want   error
+	}{
+		{name: "own card", method: models.PaymentMethod{CustomerID: customerID, Token: "saved-token"}},
+		{name: "card of another customer", method: models.PaymentMethod{CustomerID: uuid.New(), Token: "saved-token"}, want: errPayment.ErrSavedMethodNotFound},
+		{name: "expired card", method: models.PaymentMethod{CustomerID: customerID, Token: "saved-token", ExpiredAt: &expired}, want: errPayment.ErrSavedMethodExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			charger := &fakeCharger{}
+			service, _ := newService(charger)
+			methods := &fakePaymentMethodRepository{method: &tt.method}
+			service.repository.(*fake.Registry).PaymentMethod = methods
+			methodID := uuid.NewString()
+
+			_, err := service.Create(context.Background(), &dto.PaymentRequest{
+				OrderID:         "order",
+				Amount:          10000,
+				ItemDetails:     items(10000),
+				ExpiredAt:       time.Now().Add(time.Hour),
+				CustomerID:      &customerID,
+				PaymentMethodID: &methodID,
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Create error = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if len(charger.requests) != 0 {
+					t.Error("Create charged a card it must not use")
+				}
+				return
+			}
+			if len(charger.requests) != 1 || charger.requests[0].PaymentToken != "saved-token" ||
+				charger.requests[0].PaymentMethod != constants.PaymentMethodCard {
+				t.Errorf("charges = %+v, want the saved card charged", charger.requests)
+			}
+			if !methods.used {
+				t.Error("saved method was not marked as used")
+			}
+		})
+	}
+}
+
+func authorizedPayment() *models.Payment {
+	transactionID := "tx-order"
+	expiredAt := time.Now().Add(time.Hour)
+	return &models.Payment{
+		ID:             1,
+		UUID:           uuid.New(),
+		Provider:       constants.ProviderMidtrans,
+		GatewayOrderID: "order",
+		TransactionID:  &transactionID,
+		Amount:         1000000,
+		Currency:       "IDR",
+		Status:         status(constants.Authorized),
+		ExpiredAt:      &expiredAt,
+	}
+}
+
+func TestCaptureValidatesAmount(t *testing.T) {
+	over, zero := 10000.01, 0.0
+	tests := []struct {
+		name   string
+		status constants.PaymentStatus
+		amount *float64
+		want   error
+	}{
+		{name: "not authorized", status: constants.Pending, want: errPayment.ErrPaymentNotAuthorized},
+		{name: "above the authorized amount", status: constants.Authorized, amount: &over, want: errPayment.ErrCaptureAmountInvalid},
+		{name: "zero", status: constants.Authorized, amount: &zero, want: errPayment.ErrCaptureAmountInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := authorizedPayment()
+			payment.Status = status(tt.status)
+			gateway := &fakeAuthorizer{}
+			service, _ := newService(gateway, payment)
+
+			_, err := service.Capture(context.Background(), payment.UUID.String(), &dto.CapturePaymentRequest{Amount: tt.amount})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Capture error = %v, want %v", err, tt.want)
+			}
+			if len(gateway.captured) != 0 || payment.CaptureAmount != nil {
+				t.Error("rejected capture reached the gateway")
+			}
+		})
+	}
+}
+
+func TestCaptureRefusedByGateway(t *testing.T) {
+	payment := authorizedPayment()
+	refused := errors.New("capture refused")
+	var stored *int64
+	gateway := &fakeAuthorizer{err: refused, capturing: func() { stored = payment.CaptureAmount }}
+	service, _ := newService(gateway, payment)
+
+	partial := 6000.0
+	_, err := service.Capture(context.Background(), payment.UUID.String(), &dto.CapturePaymentRequest{Amount: &partial})
+	if !errors.Is(err, refused) {
+		t.Errorf("Capture error = %v, want %v", err, refused)
+	}
+	// The webhook of the capture is checked against the amount stored before
+	// the gateway is asked, a refusal rolls it back with the transaction.
+	if stored == nil || *stored != 600000 {
+		t.Errorf("capture amount while capturing = %v, want 600000", stored)
+	}
+	if *payment.Status != constants.Authorized {
+		t.Errorf("status = %v, want the authorisation kept", *payment.Status)
+	}
+}
+
+func TestCreateStoresAuthorize(t *testing.T) {
+	service, repository := newService(&fakeAuthorizer{})
+	expiredAt := time.Now().Add(time.Hour)
+
+	_, err := service.Create(context.Background(), &dto.PaymentRequest{
+		OrderID:     "order",
+		Amount:      10000,
+		ItemDetails: items(10000),
+		ExpiredAt:   expiredAt,
+		Authorize:   true,
+	})
+	if err != nil {
+		t.Fatalf("Create unexpected error: %v", err)
+	}
+	if !repository.created[0].Authorize {
+		t.Error("stored payment is not an authorisation, it would settle on the first notification")
+	}
+}
+
+func TestVoidCancelsAuthorization(t *testing.T) {
+	payment := authorizedPayment()
+	service, _ := newService(&fakeAuthorizer{}, payment)
+
+	_, err := service.Void(context.Background(), payment.UUID.String())
+	if err != nil {
+		t.Fatalf("Void unexpected error: %v", err)
+	}
+	if *payment.Status != constants.Cancel {
+		t.Errorf("status = %v, want cancelled", *payment.Status)
+	}
+
+	// A voided authorisation cannot be voided or captured again.
+	if _, err = service.Void(context.Background(), payment.UUID.String()); !errors.Is(err, errPayment.ErrPaymentNotAuthorized) {
+		t.Errorf("second Void error = %v, want %v", err, errPayment.ErrPaymentNotAuthorized)
+	}
+}
+
+func TestGatewayFee(t *testing.T) {
+	fees := config.Config.Gateway.Fees
+	defer func() { config.Config.Gateway.Fees = fees }()
+	config.Config.Gateway.Fees = []config.GatewayFee{
+		{Provider: constants.ProviderMidtrans, Percentage: 2},
+		{Provider: constants.ProviderMidtrans, PaymentType: "bank_transfer", Fixed: 4000},
+		{Provider: constants.ProviderMidtrans, PaymentType: "qris", Percentage: 0.7},
+	}
+
+	bankTransfer, qris, card := "bank_transfer", "QRIS", "credit_card"
+	tests := []struct {
+		name    string
+		payment models.Payment
+		want    int64
+		ok      bool
+	}{
+		{name: "reported payment type", payment: models.Payment{PaymentType: &bankTransfer, PaymentMethod: &qris}, want: 400000, ok: true},
+		{name: "payment method", payment: models.Payment{PaymentMethod: &qris}, want: 70000, ok: true},
+		{name: "provider fallback", payment: models.Payment{PaymentType: &card}, want: 200000, ok: true},
+		{name: "captured for less", payment: models.Payment{CaptureAmount: amount(500000)}, want: 10000, ok: true},
+		{name: "unknown provider", payment: models.Payment{Provider: constants.ProviderXendit}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := tt.payment
+			if payment.Provider == "" {
+				payment.Provider = constants.ProviderMidtrans
+			}
+			payment.Amount, payment.Currency = 10000000, "IDR"
+			fee, ok := (&PaymentService{}).gatewayFee(&payment)
+			if ok != tt.ok || fee.Amount != tt.want {
+				t.Errorf("gatewayFee = %d, %v, want %d, %v", fee.Amount, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestApplyTax(t *testing.T) {
+	tax := config.Config.Tax
+	defer func() { config.Config.Tax = tax }()
+
+	newRequest := func() *dto.PaymentRequest {
+		return &dto.PaymentRequest{
+			Amount: 120000,
+			ItemDetails: []dto.ItemDetail{
+				{ID: "laptop-bag", Name: "Laptop bag", Amount: 100000, Quantity: 1},
+				{ID: "rice", Name: "Rice", Amount: 50000, Quantity: 1, Category: "staple"},
+				{ID: "discount", Name: "Discount", Amount: -30000, Quantity: 1},
+			},
+		}
+	}
+	tests := []struct {
+		name      string
+		inclusive bool
+		base      int64
+		ppn       int64
+		amount    float64
+	}{
+		// The discount is spread over both items, the exempt rice carries a
+		// third of it and no tax.
+		{name: "exclusive", base: 8000000, ppn: 880000, amount: 128800},
+		{name: "inclusive", inclusive: true, base: 7207200, ppn: 792800, amount: 120000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Config.Tax = config.Tax{Enabled: true, Rate: 11, Inclusive: tt.inclusive, ExemptCategories: []string{"STAPLE"}}
+			request := newRequest()
+			(&PaymentService{}).applyTax(request)
+
+			if request.Tax == nil || request.Tax.Base != tt.base || request.Tax.Amount != tt.ppn {
+				t.Fatalf("tax = %+v, want base %d and ppn %d", request.Tax, tt.base, tt.ppn)
+			}
+			if request.Amount != tt.amount {
+				t.Errorf("amount = %.2f, want %.2f", request.Amount, tt.amount)
+			}
+			added := len(request.ItemDetails) == 4
+			if added != !tt.inclusive {
+				t.Errorf("items = %+v, want a PPN item only for exclusive tax", request.ItemDetails)
+			}
+		})
+	}
+}
+
+func TestSnapshotRate(t *testing.T) {
+	service, _ := newService(&fakeGateway{})
+	tests := []struct {
+		name    string
+		request dto.PaymentRequest
+		rate    float64
+		want    int64
+	}{
+		{name: "reporting currency", request: dto.PaymentRequest{Amount: 150000}, rate: 1, want: 15000000},
+		{name: "converted", request: dto.PaymentRequest{Amount: 12.5, Currency: "SGD"}, rate: 11800, want: 14750000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.snapshotRate(context.Background(), &tt.request)
+			if err != nil {
+				t.Fatalf("snapshotRate unexpected error: %v", err)
+			}
+			if tt.request.FX.Rate != tt.rate || tt.request.FX.ReportingAmount != tt.want {
+				t.Errorf("fx = %+v, want rate %v and %d in the reporting currency", tt.request.FX, tt.rate, tt.want)
+			}
+		})
+	}
+
+	err := service.snapshotRate(context.Background(), &dto.PaymentRequest{Amount: 10, Currency: "USD"})
+	if err == nil {
+		t.Error("snapshotRate error = nil, want a missing rate error")
+	}
+}
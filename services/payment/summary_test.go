@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"payment-service/constants"
+	errPayment "payment-service/constants/error/payment"
+	"payment-service/domain/dto"
+	"payment-service/repositories/fake"
+	repositories "payment-service/repositories/payment"
+)
+
+type fakeSummaryRepository struct {
+	repositories.IPaymentRepository
+	query *dto.PaymentSummaryParam
+}
+
+func (f *fakeSummaryRepository) SummaryTotal(_ context.Context, query *dto.PaymentSummaryParam) (*dto.PaymentSummaryTotal, error) {
+	f.query = query
+	return &dto.PaymentSummaryTotal{
+		TotalCount:    8,
+		TotalAmount:   80000000,
+		SettledCount:  6,
+		SettledAmount: 60000000,
+		ExpiredCount:  2,
+	}, nil
+}
+
+func (f *fakeSummaryRepository) SummaryGroupBy(
+	_ context.Context,
+	_ *dto.PaymentSummaryParam,
+	column string,
+) ([]dto.PaymentSummaryGroup, error) {
+	if column != "status" {
+		return []dto.PaymentSummaryGroup{{Key: nil, Count: 8, Amount: 80000000}}, nil
+	}
+	settlement := "200"
+	expire := "300"
+	return []dto.PaymentSummaryGroup{
+		{Key: &settlement, Count: 6, Amount: 60000000},
+		{Key: &expire, Count: 2, Amount: 20000000},
+	}, nil
+}
+
+func (f *fakeSummaryRepository) SummaryByPeriod(context.Context, *dto.PaymentSummaryParam) ([]dto.PaymentSummaryPeriod, error) {
+	return []dto.PaymentSummaryPeriod{}, nil
+}
+
+func TestSummary(t *testing.T) {
+	repository := &fakeSummaryRepository{}
+	service := &PaymentService{repository: &fake.Registry{Payment: repository}}
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(2024, 5, 31, 0, 0, 0, 0, time.Local)
+
+	summary, err := service.Summary(context.Background(), &dto.PaymentSummaryParam{StartDate: start, EndDate: end})
+	if err != nil {
+		t.Fatalf("Summary unexpected error: %v", err)
+	}
+	// The end date is inclusive, the query runs up to the next day.
+	if !repository.query.EndDate.Equal(end.AddDate(0, 0, 1)) || repository.query.Period != "day" {
+		t.Errorf("query = %+v, want the day after the end date grouped by day", repository.query)
+	}
+	if summary.ConversionRate != 0.75 || summary.ExpiryRate != 0.25 {
+		t.Errorf("rates = %v and %v, want 0.75 and 0.25", summary.ConversionRate, summary.ExpiryRate)
+	}
+	if summary.TotalAmount != 800000 || summary.SettledAmount != 600000 {
+		t.Errorf("amounts = %v and %v, want 800000 and 600000", summary.TotalAmount, summary.SettledAmount)
+	}
+	if len(summary.ByStatus) != 2 ||
+		*summary.ByStatus[0].Key != constants.SettlementString.String() ||
+		*summary.ByStatus[1].Key != constants.ExpireString.String() {
+		t.Errorf("statuses = %+v, want settlement and expire", summary.ByStatus)
+	}
+}
+
+func TestSummaryRejectsReversedRange(t *testing.T) {
+	service := &PaymentService{repository: &fake.Registry{Payment: &fakeSummaryRepository{}}}
+	_, err := service.Summary(context.Background(), &dto.PaymentSummaryParam{
+		StartDate: time.Date(2024, 5, 2, 0, 0, 0, 0, time.Local),
+		EndDate:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local),
+	})
+	if !errors.Is(err, errPayment.ErrSummaryRangeInvalid) {
+		t.Errorf("Summary error = %v, want %v", err, errPayment.ErrSummaryRangeInvalid)
+	}
+}
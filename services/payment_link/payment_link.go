@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"math/big"
+	"payment-service/common/money"
+	"payment-service/common/utils"
+	"payment-service/config"
+	errPaymentLink "payment-service/constants/error/payment_link"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories"
+	services "payment-service/services/payment"
+	"strings"
+	"time"
+)
+
+const (
+	// codeAlphabet leaves out characters that are easily mistaken for one
+	// another when a link is read out or typed.
+	codeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	codeLength   = 8
+	codeAttempts = 5
+
+	// Pending payments hold a use of the link, so they expire quickly.
+	defaultPaymentExpiry = time.Hour
+)
+
+type IPaymentLinkService interface {
+	GetAllWithPagination(context.Context, *dto.PaymentLinkRequestParam) (*utils.PaginationResult, error)
+	GetByUUID(context.Context, string) (*dto.PaymentLinkResponse, error)
+	Create(context.Context, *dto.PaymentLinkRequest) (*dto.PaymentLinkResponse, error)
+	Deactivate(context.Context, string) (*dto.PaymentLinkResponse, error)
+	GetByCode(context.Context, string) (*dto.PublicPaymentLinkResponse, error)
+	Use(context.Context, string, *dto.PaymentLinkUseRequest) (*dto.PaymentResponse, error)
+}
+
+func NewPaymentLinkService(repository repositories.IRepositoryRegistry, payment services.IPaymentService) IPaymentLinkService {
+	return &PaymentLinkService{
+		repository: repository,
+		payment:    payment,
+	}
+}
+
+type PaymentLinkService struct {
+	repository repositories.IRepositoryRegistry
+	payment    services.IPaymentService
+}
+
+func (p *PaymentLinkService) GetAllWithPagination(ctx context.Context, param *dto.PaymentLinkRequestParam) (*utils.PaginationResult, error) {
+	links, total, err := p.repository.GetPaymentLink().FindAllWithPagination(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]dto.PaymentLinkResponse, 0, len(links))
+	for _, link := range links {
+		response, err := p.toResponse(ctx, &link)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *response)
+	}
+
+	paginationParam := utils.PaginationParam{
+		Count: total,
+		Page:  param.Page,
+		Limit: param.Limit,
+		Data:  results,
+	}
+	response := utils.GeneratePagination(paginationParam)
+	return &response, nil
+}
+
+func (p *PaymentLinkService) GetByUUID(ctx context.Context, uuid string) (*dto.PaymentLinkResponse, error) {
+	link, err := p.repository.GetPaymentLink().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	response, err := p.toResponse(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := p.repository.GetPayment().FindByPaymentLinkID(ctx, link.ID)
+	if err != nil {
+		return nil, err
+	}
+	response.Payments = make([]dto.PaymentLinkPaymentResponse, 0, len(payments))
+	for _, payment := range payments {
+		response.Payments = append(response.Payments, dto.PaymentLinkPaymentResponse{
+			UUID:         payment.UUID,
+			Amount:       money.New(payment.Amount, payment.Currency).Major(),
+			Status:       payment.Status.GetStatusString(),
+			CustomerName: payment.CustomerName,
+			PaidAt:       payment.PaidAt,
+			CreatedAt:    payment.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+func (p *PaymentLinkService) Create(ctx context.Context, request *dto.PaymentLinkRequest) (*dto.PaymentLinkResponse, error) {
+	if request.MinAmount != nil && request.MaxAmount != nil && *request.MinAmount > *request.MaxAmount {
+		return nil, errPaymentLink.ErrPaymentLinkBoundsInvalid
+	}
+	if request.ExpiredAt != nil && !request.ExpiredAt.After(time.Now()) {
+		return nil, errPaymentLink.ErrPaymentLinkExpireInvalid
+	}
+	code, err := p.generateCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	request.Code = code
+	link, err := p.repository.GetPaymentLink().Create(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return p.toResponse(ctx, link)
+}
+
+func (p *PaymentLinkService) Deactivate(ctx context.Context, uuid string) (*dto.PaymentLinkResponse, error) {
+	link, err := p.repository.GetPaymentLink().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	err = p.repository.GetPaymentLink().Deactivate(ctx, link.ID)
+	if err != nil {
+		return nil, err
+	}
+	link.IsActive = false
+	return p.toResponse(ctx, link)
+}
+
+func (p *PaymentLinkService) GetByCode(ctx context.Context, code string) (*dto.PublicPaymentLinkResponse, error) {
+	link, err := p.usableLink(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PublicPaymentLinkResponse{
+		Code:        link.Code,
+		Title:       link.Title,
+		Description: link.Description,
+		Amount:      p.optionalAmount(link.Amount, link.Currency),
+		MinAmount:   p.optionalAmount(link.MinAmount, link.Currency),
+		MaxAmount:   p.optionalAmount(link.MaxAmount, link.Currency),
+		Currency:    link.Currency,
+		ExpiredAt:   link.ExpiredAt,
+	}, nil
+}
+
+// Use creates the payment of one payer under an order id of its own, the
+// maximum number of uses is enforced when the payment is created.
+func (p *PaymentLinkService) Use(ctx context.Context, code string, request *dto.PaymentLinkUseRequest) (*dto.PaymentResponse, error) {
+	link, err := p.usableLink(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := p.amount(link, request)
+	if err != nil {
+		return nil, err
+	}
+
+	expiry := defaultPaymentExpiry
+	if config.Config.PaymentLink.PaymentExpiryInMinutes > 0 {
+		expiry = time.Duration(config.Config.PaymentLink.PaymentExpiryInMinutes) * time.Minute
+	}
+	expiredAt := time.Now().Add(expiry)
+	if link.ExpiredAt != nil && link.ExpiredAt.Before(expiredAt) {
+		expiredAt = *link.ExpiredAt
+	}
+	name := link.Title
+	if len(name) > 50 {
+		name = name[:50]
+	}
+	return p.payment.Create(ctx, &dto.PaymentRequest{
+		OrderID:        uuid.New().String(),
+		ExpiredAt:      expiredAt,
+		Amount:         amount.Major(),
+		Currency:       link.Currency,
+		Description:    &link.Title,
+		CustomerDetail: request.CustomerDetail,
+		FinishURL:      request.FinishURL,
+		PaymentLinkID:  &link.ID,
+		ItemDetails: []dto.ItemDetail{
+			{
+				ID:       link.Code,
+				Amount:   amount.Major(),
+				Name:     name,
+				Quantity: 1,
+			},
+		},
+	})
+}
+
+func (p *PaymentLinkService) usableLink(ctx context.Context, code string) (*models.PaymentLink, error) {
+	link, err := p.repository.GetPaymentLink().FindByCode(ctx, strings.ToUpper(code))
+	if err != nil {
+		return nil, err
+	}
+	if !link.IsActive {
+		return nil, errPaymentLink.ErrPaymentLinkInactive
+	}
+	if link.ExpiredAt != nil && !link.ExpiredAt.After(time.Now()) {
+		return nil, errPaymentLink.ErrPaymentLinkExpired
+	}
+	return link, nil
+}
+
+func (p *PaymentLinkService) amount(link *models.PaymentLink, request *dto.PaymentLinkUseRequest) (money.Money, error) {
+	if link.Amount != nil {
+		return money.New(*link.Amount, link.Currency), nil
+	}
+	if request.Amount == nil {
+		return money.Money{}, errPaymentLink.ErrPaymentLinkAmountRequired
+	}
+	amount := money.FromMajor(*request.Amount, link.Currency)
+	if link.MinAmount != nil && amount.Amount < *link.MinAmount {
+		return money.Money{}, errPaymentLink.ErrPaymentLinkAmountRange
+	}
+	if link.MaxAmount != nil && amount.Amount > *link.MaxAmount {
+		return money.Money{}, errPaymentLink.ErrPaymentLinkAmountRange
+	}
+	return amount, nil
+}
+
+func (p *PaymentLinkService) generateCode(ctx context.Context) (string, error) {
+	for i := 0; i < codeAttempts; i++ {
+		var code strings.Builder
+		for j := 0; j < codeLength; j++ {
+			index, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeAlphabet))))
+			if err != nil {
+				return "", err
+			}
+			code.WriteByte(codeAlphabet[index.Int64()])
+		}
+		_, err := p.repository.GetPaymentLink().FindByCode(ctx, code.String())
+		if errors.Is(err, errPaymentLink.ErrPaymentLinkNotFound) {
+			return code.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("no unused payment link code after %d attempts", codeAttempts)
+}
+
+func (p *PaymentLinkService) optionalAmount(amount *int64, currency string) *float64 {
+	if amount == nil {
+		return nil
+	}
+	value := money.New(*amount, currency).Major()
+	return &value
+}
+
+func (p *PaymentLinkService) toResponse(ctx context.Context, link *models.PaymentLink) (*dto.PaymentLinkResponse, error) {
+	stats, err := p.repository.GetPaymentLink().Stats(ctx, p.repository.GetTx(), link.ID)
+	if err != nil {
+		return nil, err
+	}
+	response := &dto.PaymentLinkResponse{
+		UUID:        link.UUID,
+		Code:        link.Code,
+		Title:       link.Title,
+		Description: link.Description,
+		Amount:      p.optionalAmount(link.Amount, link.Currency),
+		MinAmount:   p.optionalAmount(link.MinAmount, link.Currency),
+		MaxAmount:   p.optionalAmount(link.MaxAmount, link.Currency),
+		Currency:    link.Currency,
+		MaxUses:     link.MaxUses,
+		UsedCount:   stats.Used,
+		PaidCount:   stats.Paid,
+		PaidAmount:  money.New(stats.PaidAmount, link.Currency).Major(),
+		ExpiredAt:   link.ExpiredAt,
+		IsActive:    link.IsActive,
+		CreatedAt:   link.CreatedAt,
+	}
+	if config.Config.PaymentLink.BaseURL != "" {
+		response.URL = fmt.Sprintf("%s/%s", strings.TrimRight(config.Config.PaymentLink.BaseURL, "/"), link.Code)
+	}
+	return response, nil
+}
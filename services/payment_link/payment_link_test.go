@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	errPaymentLink "payment-service/constants/error/payment_link"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories/fake"
+	repositories "payment-service/repositories/payment_link"
+	services "payment-service/services/payment"
+)
+
+type fakePaymentLinkRepository struct {
+	repositories.IPaymentLinkRepository
+	link *models.PaymentLink
+}
+
+func (f *fakePaymentLinkRepository) FindByCode(_ context.Context, code string) (*models.PaymentLink, error) {
+	if code != f.link.Code {
+		return nil, errPaymentLink.ErrPaymentLinkNotFound
+	}
+	return f.link, nil
+}
+
+type fakePaymentService struct {
+	services.IPaymentService
+	requests []*dto.PaymentRequest
+}
+
+func (f *fakePaymentService) Create(_ context.Context, request *dto.PaymentRequest) (*dto.PaymentResponse, error) {
+	f.requests = append(f.requests, request)
+	return &dto.PaymentResponse{UUID: uuid.New()}, nil
+}
+
+func newTestLink(link *models.PaymentLink) (*PaymentLinkService, *fakePaymentService) {
+	link.Code = "PAYME123"
+	link.Currency = "IDR"
+	payment := &fakePaymentService{}
+	return &PaymentLinkService{
+		repository: &fake.Registry{PaymentLink: &fakePaymentLinkRepository{link: link}},
+		payment:    payment,
+	}, payment
+}
+
+func amount(value int64) *int64 {
+	return &value
+}
+
+func major(value float64) *float64 {
+	return &value
+}
+
+func TestUseChecksLink(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name    string
+		link    models.PaymentLink
+		request dto.PaymentLinkUseRequest
+		want    error
+	}{
+		{name: "inactive", link: models.PaymentLink{Amount: amount(1000000)}, want: errPaymentLink.ErrPaymentLinkInactive},
+		{name: "expired", link: models.PaymentLink{Amount: amount(1000000), IsActive: true, ExpiredAt: &past}, want: errPaymentLink.ErrPaymentLinkExpired},
+		{name: "amount missing", link: models.PaymentLink{IsActive: true}, want: errPaymentLink.ErrPaymentLinkAmountRequired},
+		{
+			name:    "below the minimum",
+			link:    models.PaymentLink{IsActive: true, MinAmount: amount(1000000)},
+			request: dto.PaymentLinkUseRequest{Amount: major(9999)},
+			want:    errPaymentLink.ErrPaymentLinkAmountRange,
+		},
+		{
+			name:    "above the maximum",
+			link:    models.PaymentLink{IsActive: true, MaxAmount: amount(5000000)},
+			request: dto.PaymentLinkUseRequest{Amount: major(50000.01)},
+			want:    errPaymentLink.ErrPaymentLinkAmountRange,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, payment := newTestLink(&tt.link)
+			_, err := service.Use(context.Background(), "payme123", &tt.request)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Use error = %v, want %v", err, tt.want)
+			}
+			if len(payment.requests) != 0 {
+				t.Errorf("Use created %d payments, want none", len(payment.requests))
+			}
+		})
+	}
+}
+
+func TestUseCreatesPayment(t *testing.T) {
+	linkExpiry := time.Now().Add(10 * time.Minute)
+	tests := []struct {
+		name    string
+		link    models.PaymentLink
+		request dto.PaymentLinkUseRequest
+		want    float64
+	}{
+		{name: "fixed amount", link: models.PaymentLink{ID: 7, IsActive: true, Amount: amount(2500000)}, request: dto.PaymentLinkUseRequest{Amount: major(1)}, want: 25000},
+		{name: "chosen amount", link: models.PaymentLink{ID: 7, IsActive: true, MinAmount: amount(1000000)}, request: dto.PaymentLinkUseRequest{Amount: major(15000)}, want: 15000},
+		{name: "link expiring first", link: models.PaymentLink{ID: 7, IsActive: true, Amount: amount(2500000), ExpiredAt: &linkExpiry}, want: 25000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, payment := newTestLink(&tt.link)
+			for i := 0; i < 2; i++ {
+				_, err := service.Use(context.Background(), "PAYME123", &tt.request)
+				if err != nil {
+					t.Fatalf("Use unexpected error: %v", err)
+				}
+			}
+
+			first, second := payment.requests[0], payment.requests[1]
+			if first.Amount != tt.want || first.ItemDetails[0].Amount != tt.want {
+				t.Errorf("payment amount = %.2f, want %.2f", first.Amount, tt.want)
+			}
+			if first.PaymentLinkID == nil || *first.PaymentLinkID != 7 {
+				t.Errorf("payment link id = %v, want 7", first.PaymentLinkID)
+			}
+			// Every payer pays under an order of their own.
+			if first.OrderID == second.OrderID {
+				t.Errorf("both uses created order %s", first.OrderID)
+			}
+			// A pending payment holds a use of the link, so it expires within
+			// the hour or with the link.
+			wantExpiry := time.Now().Add(time.Hour)
+			if tt.link.ExpiredAt != nil {
+				wantExpiry = *tt.link.ExpiredAt
+			}
+			if first.ExpiredAt.Sub(wantExpiry).Abs() > time.Minute {
+				t.Errorf("payment expires at %v, want %v", first.ExpiredAt, wantExpiry)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+	errPayment "payment-service/constants/error/payment"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories"
+)
+
+type IPaymentMethodService interface {
+	GetAll(context.Context, uuid.UUID) ([]dto.PaymentMethodResponse, error)
+	Delete(context.Context, string, uuid.UUID) error
+}
+
+func NewPaymentMethodService(repository repositories.IRepositoryRegistry) IPaymentMethodService {
+	return &PaymentMethodService{repository: repository}
+}
+
+type PaymentMethodService struct {
+	repository repositories.IRepositoryRegistry
+}
+
+func (p *PaymentMethodService) GetAll(ctx context.Context, customerID uuid.UUID) ([]dto.PaymentMethodResponse, error) {
+	methods, err := p.repository.GetPaymentMethod().FindByCustomerID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]dto.PaymentMethodResponse, 0, len(methods))
+	for _, method := range methods {
+		results = append(results, p.toResponse(&method))
+	}
+	return results, nil
+}
+
+// Delete only forgets the token on our side, Midtrans has no API to revoke a
+// saved card token.
+func (p *PaymentMethodService) Delete(ctx context.Context, uuid string, customerID uuid.UUID) error {
+	method, err := p.repository.GetPaymentMethod().FindByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if method.CustomerID != customerID {
+		return errPayment.ErrSavedMethodNotFound
+	}
+	return p.repository.GetPaymentMethod().Delete(ctx, method.ID)
+}
+
+func (p *PaymentMethodService) toResponse(method *models.PaymentMethod) dto.PaymentMethodResponse {
+	return dto.PaymentMethodResponse{
+		UUID:       method.UUID,
+		Provider:   method.Provider,
+		Type:       method.Type,
+		MaskedCard: method.MaskedCard,
+		Brand:      method.Brand,
+		Bank:       method.Bank,
+		CardType:   method.CardType,
+		ExpiredAt:  method.ExpiredAt,
+		LastUsedAt: method.LastUsedAt,
+		CreatedAt:  method.CreatedAt,
+	}
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"payment-service/common/export"
+	"payment-service/common/gcs"
+	"payment-service/common/money"
+	"payment-service/config"
+	"payment-service/constants"
+	errLedger "payment-service/constants/error/ledger"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories"
+	services "payment-service/services/ledger"
+	"sort"
+	"time"
+)
+
+type IPayoutService interface {
+	CreateBatch(context.Context) (*dto.PayoutBatchResponse, error)
+	GetBatchByUUID(context.Context, string) (*dto.PayoutBatchResponse, error)
+	UploadBatch(context.Context, string) (*dto.PayoutBatchResponse, error)
+}
+
+func NewPayoutService(
+	repository repositories.IRepositoryRegistry,
+	gcs gcs.IGSClient,
+	ledger services.ILedgerService,
+) IPayoutService {
+	return &PayoutService{
+		repository: repository,
+		gcs:        gcs,
+		ledger:     ledger,
+	}
+}
+
+type PayoutService struct {
+	repository repositories.IRepositoryRegistry
+	gcs        gcs.IGSClient
+	ledger     services.ILedgerService
+}
+
+var disbursementHeader = []string{
+	"Payout ID",
+	"Batch ID",
+	"Merchant ID",
+	"Amount",
+	"Currency",
+}
+
+// CreateBatch pays out every merchant balance that reached the minimum. The
+// balances move to the payout clearing account in the same transaction, so a
+// balance is never paid twice, and the disbursement file is uploaded after.
+func (p *PayoutService) CreateBatch(ctx context.Context) (*dto.PayoutBatchResponse, error) {
+	var batch *models.PayoutBatch
+	err := p.repository.GetTx().Transaction(func(tx *gorm.DB) error {
+		err := p.repository.GetLedger().Lock(ctx, tx, constants.JournalPayout)
+		if err != nil {
+			return err
+		}
+		prefix := constants.AccountMerchantPayable + ":"
+		balances, err := p.repository.GetLedger().Balances(ctx, tx, &dto.LedgerBalanceFilter{CodePrefix: &prefix})
+		if err != nil {
+			return err
+		}
+
+		requests := make([]dto.PayoutRequest, 0, len(balances))
+		lines := make(map[string][]dto.JournalLineRequest)
+		for _, balance := range balances {
+			amount := money.New(balance.Credit-balance.Debit, balance.Currency)
+			minimum := money.FromMajor(config.Config.Payout.MinimumAmount, balance.Currency)
+			if amount.Amount <= 0 || amount.Amount < minimum.Amount {
+				continue
+			}
+			requests = append(requests, dto.PayoutRequest{
+				MerchantID: *balance.MerchantID,
+				Amount:     amount,
+			})
+			lines[amount.Currency] = append(lines[amount.Currency], dto.JournalLineRequest{
+				AccountID: balance.AccountID,
+				Debit:     amount.Amount,
+			})
+		}
+		if len(requests) == 0 {
+			return errLedger.ErrNoPayableBalance
+		}
+
+		batch, err = p.repository.GetPayout().CreateBatch(ctx, tx, requests)
+		if err != nil {
+			return err
+		}
+		currencies := make([]string, 0, len(lines))
+		for currency := range lines {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+		for _, currency := range currencies {
+			var total int64
+			for _, line := range lines[currency] {
+				total += line.Debit
+			}
+			description := fmt.Sprintf("payout batch %s", batch.UUID)
+			err = p.ledger.Post(ctx, tx, &dto.JournalEntryRequest{
+				Reference:     fmt.Sprintf("payout:%s:%s", batch.UUID, currency),
+				Kind:          constants.JournalPayout,
+				PayoutBatchID: &batch.ID,
+				Currency:      currency,
+				Description:   &description,
+				PostedAt:      time.Now(),
+				Lines: append(lines[currency], dto.JournalLineRequest{
+					Account: services.PayoutClearingAccount(),
+					Credit:  total,
+				}),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.upload(ctx, batch)
+}
+
+func (p *PayoutService) GetBatchByUUID(ctx context.Context, uuid string) (*dto.PayoutBatchResponse, error) {
+	batch, err := p.repository.GetPayout().FindBatchByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	return p.toResponse(batch), nil
+}
+
+// UploadBatch retries the disbursement file of a batch whose upload failed.
+func (p *PayoutService) UploadBatch(ctx context.Context, uuid string) (*dto.PayoutBatchResponse, error) {
+	batch, err := p.repository.GetPayout().FindBatchByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status == constants.PayoutBatchUploaded {
+		return nil, errLedger.ErrPayoutBatchCompleted
+	}
+	return p.upload(ctx, batch)
+}
+
+func (p *PayoutService) upload(ctx context.Context, batch *models.PayoutBatch) (*dto.PayoutBatchResponse, error) {
+	request := &dto.UpdatePayoutBatchRequest{Status: constants.PayoutBatchUploaded}
+	url, err := p.uploadFile(ctx, batch)
+	if err != nil {
+		logrus.Errorf("failed to upload payout batch %s: %v", batch.UUID, err)
+		message := err.Error()
+		request = &dto.UpdatePayoutBatchRequest{
+			Status: constants.PayoutBatchFailed,
+			Error:  &message,
+		}
+	} else {
+		request.FileURL = &url
+	}
+
+	err = p.repository.GetPayout().UpdateBatch(ctx, batch.ID, request)
+	if err != nil {
+		return nil, err
+	}
+	batch.Status = request.Status
+	batch.FileURL = request.FileURL
+	batch.Error = request.Error
+	return p.toResponse(batch), nil
+}
+
+func (p *PayoutService) uploadFile(ctx context.Context, batch *models.PayoutBatch) (string, error) {
+	var buffer bytes.Buffer
+	writer, err := export.NewWriter(export.FormatCSV, &buffer)
+	if err != nil {
+		return "", err
+	}
+	err = writer.Write(disbursementHeader)
+	if err != nil {
+		return "", err
+	}
+	for _, payout := range batch.Payouts {
+		err = writer.Write([]string{
+			payout.UUID.String(),
+			batch.UUID.String(),
+			payout.MerchantID,
+			money.New(payout.Amount, payout.Currency).String(),
+			payout.Currency,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	err = writer.Close()
+	if err != nil {
+		return "", err
+	}
+	if len(batch.Payouts) == 0 {
+		return "", errors.New("payout batch has no payouts")
+	}
+
+	filename := fmt.Sprintf("payouts/%s/%s.csv", batch.CreatedAt.Format(time.DateOnly), batch.UUID)
+	return p.gcs.UploadFile(ctx, filename, buffer.Bytes())
+}
+
+func (p *PayoutService) toResponse(batch *models.PayoutBatch) *dto.PayoutBatchResponse {
+	payouts := make([]dto.PayoutResponse, 0, len(batch.Payouts))
+	for _, payout := range batch.Payouts {
+		payouts = append(payouts, dto.PayoutResponse{
+			UUID:       payout.UUID,
+			MerchantID: payout.MerchantID,
+			Amount:     money.New(payout.Amount, payout.Currency).Major(),
+			Currency:   payout.Currency,
+		})
+	}
+	return &dto.PayoutBatchResponse{
+		UUID:        batch.UUID,
+		Status:      batch.Status,
+		PayoutCount: batch.PayoutCount,
+		FileURL:     batch.FileURL,
+		Error:       batch.Error,
+		Payouts:     payouts,
+		CreatedAt:   batch.CreatedAt,
+		UpdatedAt:   batch.UpdatedAt,
+	}
+}
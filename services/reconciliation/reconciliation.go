@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	clients "payment-service/clients/gateway"
+	"payment-service/common/money"
+	"payment-service/constants"
+	errPayment "payment-service/constants/error/payment"
+	errReconciliation "payment-service/constants/error/reconciliation"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories"
+	services "payment-service/services/payment"
+)
+
+type IReconciliationService interface {
+	Reconcile(context.Context, *dto.ReconcileRequest) (*dto.ReconcileReport, error)
+}
+
+func NewReconciliationService(
+	repository repositories.IRepositoryRegistry,
+	gateway clients.IGatewayRegistry,
+	payment services.IPaymentService,
+) IReconciliationService {
+	return &ReconciliationService{
+		repository: repository,
+		gateway:    gateway,
+		payment:    payment,
+	}
+}
+
+type ReconciliationService struct {
+	repository repositories.IRepositoryRegistry
+	gateway    clients.IGatewayRegistry
+	payment    services.IPaymentService
+}
+
+// lockKey keeps one reconciliation running across all replicas.
+const lockKey = "status_reconciliation"
+
+var reconcilableStatuses = map[constants.PaymentStatusString]bool{
+	constants.PendingString:    true,
+	constants.AuthorizedString: true,
+	constants.SettlementString: true,
+	constants.ExpireString:     true,
+	constants.CancelString:     true,
+}
+
+// refundStatuses are picked up from the gateway once a payment is paid, a
+// refund made in the gateway dashboard sends no notification of its own.
+var refundStatuses = map[constants.PaymentStatusString]bool{
+	constants.PartialRefundString: true,
+	constants.RefundString:        true,
+}
+
+func (r *ReconciliationService) Reconcile(ctx context.Context, request *dto.ReconcileRequest) (*dto.ReconcileReport, error) {
+	if len(request.OrderIDs) == 0 && (request.StartDate == nil || request.EndDate == nil) {
+		return nil, errReconciliation.ErrReconcileFilterRequired
+	}
+	if request.StartDate != nil && request.EndDate != nil && !request.StartDate.Before(*request.EndDate) {
+		return nil, errReconciliation.ErrReconcileRangeInvalid
+	}
+
+	var report *dto.ReconcileReport
+	err := r.repository.GetStatusReconciliation().RunExclusive(ctx, lockKey, func() error {
+		var err error
+		report, err = r.reconcile(ctx, request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (r *ReconciliationService) reconcile(ctx context.Context, request *dto.ReconcileRequest) (*dto.ReconcileReport, error) {
+	payments, err := r.repository.GetPayment().FindForReconciliation(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.ReconcileReport{
+		UUID:          uuid.New(),
+		StartDate:     request.StartDate,
+		EndDate:       request.EndDate,
+		OrderIDs:      request.OrderIDs,
+		Discrepancies: make([]dto.ReconcileDiscrepancy, 0),
+	}
+	for _, payment := range payments {
+		report.TotalChecked++
+		discrepancy := r.reconcilePayment(ctx, &payment)
+		if discrepancy == nil {
+			continue
+		}
+		if discrepancy.Action == dto.ReconcileApplied {
+			report.TotalApplied++
+		}
+		report.Discrepancies = append(report.Discrepancies, *discrepancy)
+	}
+	report.TotalDiscrepancies = len(report.Discrepancies)
+
+	reconciliation, err := r.repository.GetStatusReconciliation().Create(ctx, r.repository.GetTx(), report)
+	if err != nil {
+		return nil, err
+	}
+	report.CreatedAt = reconciliation.CreatedAt
+	return report, nil
+}
+
+func (r *ReconciliationService) reconcilePayment(ctx context.Context, payment *models.Payment) *dto.ReconcileDiscrepancy {
+	localStatus := payment.Status.GetStatusString()
+	discrepancy := &dto.ReconcileDiscrepancy{
+		PaymentID:   payment.ID,
+		OrderID:     payment.OrderID,
+		LocalStatus: localStatus.String(),
+	}
+
+	notification, err := r.getStatus(payment)
+	if err != nil {
+		if errors.Is(err, errPayment.ErrTransactionNotFound) {
+			// The customer never opened the payment page, nothing to compare yet.
+			if localStatus == constants.InitialString {
+				return nil
+			}
+			discrepancy.Action = dto.ReconcileSkipped
+		} else {
+			discrepancy.Action = dto.ReconcileFailed
+		}
+		note := err.Error()
+		discrepancy.Note = &note
+		return discrepancy
+	}
+
+	discrepancy.GatewayStatus = notification.TransactionStatus.String()
+	if r.isUnchanged(payment, localStatus, notification) {
+		return nil
+	}
+
+	if !r.isApplicable(localStatus, notification.TransactionStatus) {
+		note := "transition is not applied automatically"
+		discrepancy.Action = dto.ReconcileSkipped
+		discrepancy.Note = &note
+		return discrepancy
+	}
+
+	err = r.payment.ApplyNotification(ctx, notification)
+	if err != nil {
+		logrus.Errorf("failed to apply status for order %s: %v", payment.OrderID, err)
+		note := err.Error()
+		discrepancy.Action = dto.ReconcileFailed
+		discrepancy.Note = &note
+		return discrepancy
+	}
+	discrepancy.Action = dto.ReconcileApplied
+	return discrepancy
+}
+
+func (r *ReconciliationService) getStatus(payment *models.Payment) (*dto.GatewayNotification, error) {
+	gateway, err := r.gateway.Get(payment.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return gateway.GetStatus(payment.GatewayOrderID)
+}
+
+func (r *ReconciliationService) isApplicable(local, gateway constants.PaymentStatusString) bool {
+	switch local {
+	case constants.InitialString, constants.PendingString, constants.PartiallyPaidString, constants.AuthorizedString:
+		return reconcilableStatuses[gateway]
+	case constants.SettlementString, constants.PartialRefundString:
+		return refundStatuses[gateway]
+	}
+	return false
+}
+
+// isUnchanged tells whether the gateway status maps onto the local one. The
+// gateway keeps reporting a partially paid payment as pending until it is
+// paid in full.
+func (r *ReconciliationService) isUnchanged(
+	payment *models.Payment,
+	local constants.PaymentStatusString,
+	notification *dto.GatewayNotification,
+) bool {
+	if local == constants.PartiallyPaidString && notification.TransactionStatus == constants.PendingString {
+		return true
+	}
+	return notification.TransactionStatus == local && !r.refundGrew(payment, notification)
+}
+
+// refundGrew tells a further partial refund apart from one already applied,
+// both report the same status.
+func (r *ReconciliationService) refundGrew(payment *models.Payment, notification *dto.GatewayNotification) bool {
+	if notification.TransactionStatus != constants.PartialRefundString || notification.RefundAmount == "" {
+		return false
+	}
+	refunded, err := money.Parse(notification.RefundAmount, payment.Currency)
+	return err == nil && refunded.Amount > payment.RefundAmount
+}
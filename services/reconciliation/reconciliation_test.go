@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	clients "payment-service/clients/gateway"
+	"payment-service/config"
+	"payment-service/constants"
+	errPayment "payment-service/constants/error/payment"
+	errReconciliation "payment-service/constants/error/reconciliation"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories/fake"
+	repositories "payment-service/repositories/payment"
+	repositories2 "payment-service/repositories/status_reconciliation"
+	services "payment-service/services/payment"
+)
+
+type fakePaymentRepository struct {
+	repositories.IPaymentRepository
+	payments []models.Payment
+}
+
+func (f *fakePaymentRepository) FindForReconciliation(context.Context, *dto.ReconcileRequest) ([]models.Payment, error) {
+	return f.payments, nil
+}
+
+type fakeStatusReconciliationRepository struct {
+	repositories2.IStatusReconciliationRepository
+	reports []*dto.ReconcileReport
+	running bool
+}
+
+func (f *fakeStatusReconciliationRepository) RunExclusive(_ context.Context, _ string, fn func() error) error {
+	if f.running {
+		return errReconciliation.ErrReconcileInProgress
+	}
+	return fn()
+}
+
+func (f *fakeStatusReconciliationRepository) Create(
+	_ context.Context,
+	_ *gorm.DB,
+	report *dto.ReconcileReport,
+) (*models.StatusReconciliation, error) {
+	f.reports = append(f.reports, report)
+	return &models.StatusReconciliation{UUID: report.UUID}, nil
+}
+
+// fakeGateway reports the gateway status of each order, orders it does not
+// know are not found.
+type fakeGateway struct {
+	clients.PaymentGateway
+	statuses map[string]constants.PaymentStatusString
+}
+
+func (f *fakeGateway) Provider() string {
+	return constants.ProviderMidtrans
+}
+
+func (f *fakeGateway) GetStatus(orderID string) (*dto.GatewayNotification, error) {
+	status, ok := f.statuses[orderID]
+	if !ok {
+		return nil, errPayment.ErrTransactionNotFound
+	}
+	notification := &dto.GatewayNotification{Provider: f.Provider(), OrderID: orderID, TransactionStatus: status}
+	if status == constants.PartialRefundString {
+		notification.RefundAmount = "4000.00"
+	}
+	return notification, nil
+}
+
+type fakePaymentService struct {
+	services.IPaymentService
+	applied []string
+}
+
+func (f *fakePaymentService) ApplyNotification(_ context.Context, notification *dto.GatewayNotification) error {
+	f.applied = append(f.applied, notification.OrderID)
+	return nil
+}
+
+func newPayment(id uint, status constants.PaymentStatus) models.Payment {
+	orderID := uuid.New()
+	return models.Payment{
+		ID:             id,
+		OrderID:        orderID,
+		GatewayOrderID: orderID.String(),
+		Provider:       constants.ProviderMidtrans,
+		Currency:       "IDR",
+		Status:         &status,
+	}
+}
+
+func TestReconcileAppliesMissedTransitions(t *testing.T) {
+	settled := newPayment(1, constants.Pending)
+	expired := newPayment(2, constants.Initial)
+	inSync := newPayment(3, constants.Settlement)
+	unopened := newPayment(4, constants.Initial)
+	lost := newPayment(5, constants.Pending)
+	reversed := newPayment(6, constants.Settlement)
+	refunded := newPayment(7, constants.Settlement)
+	refundApplied := newPayment(8, constants.PartiallyRefunded)
+	refundApplied.RefundAmount = 400000
+	partiallyPaid := newPayment(9, constants.PartiallyPaid)
+
+	gateway := &fakeGateway{statuses: map[string]constants.PaymentStatusString{
+		settled.OrderID.String():  constants.SettlementString,
+		expired.OrderID.String():  constants.ExpireString,
+		inSync.OrderID.String():   constants.SettlementString,
+		reversed.OrderID.String(): constants.PendingString,
+		// A refund made in the dashboard is picked up, one already applied
+		// is not applied again.
+		refunded.OrderID.String():      constants.PartialRefundString,
+		refundApplied.OrderID.String(): constants.PartialRefundString,
+		// The gateway reports a partially paid payment as pending.
+		partiallyPaid.OrderID.String(): constants.PendingString,
+	}}
+	payment := &fakePaymentService{}
+	reconciliation := &fakeStatusReconciliationRepository{}
+	service := NewReconciliationService(&fake.Registry{
+		DB: fake.DB(),
+		Payment: &fakePaymentRepository{
+			payments: []models.Payment{settled, expired, inSync, unopened, lost, reversed, refunded, refundApplied, partiallyPaid},
+		},
+		StatusReconciliation: reconciliation,
+	}, clients.NewGatewayRegistry(config.Gateway{DefaultProvider: constants.ProviderMidtrans}, gateway), payment)
+
+	report, err := service.Reconcile(context.Background(), &dto.ReconcileRequest{OrderIDs: []string{"any"}})
+	if err != nil {
+		t.Fatalf("Reconcile unexpected error: %v", err)
+	}
+	if report.TotalChecked != 9 || report.TotalApplied != 3 || report.TotalDiscrepancies != 5 {
+		t.Errorf("report checked %d, applied %d, found %d discrepancies, want 9, 3 and 5",
+			report.TotalChecked, report.TotalApplied, report.TotalDiscrepancies)
+	}
+	want := []string{settled.OrderID.String(), expired.OrderID.String(), refunded.OrderID.String()}
+	if !slices.Equal(payment.applied, want) {
+		t.Errorf("applied %v, want the settled, the expired and the refunded order", payment.applied)
+	}
+
+	actions := map[uuid.UUID]dto.ReconcileAction{}
+	for _, discrepancy := range report.Discrepancies {
+		actions[discrepancy.OrderID] = discrepancy.Action
+	}
+	// A payment the gateway no longer knows and a paid payment the gateway
+	// reports as pending are left to an admin.
+	if actions[lost.OrderID] != dto.ReconcileSkipped || actions[reversed.OrderID] != dto.ReconcileSkipped {
+		t.Errorf("actions = %v, want the lost and the reversed order skipped", actions)
+	}
+	if len(reconciliation.reports) != 1 {
+		t.Errorf("stored %d reports, want 1", len(reconciliation.reports))
+	}
+}
+
+func TestReconcileRunsOneAtATime(t *testing.T) {
+	payment := &fakePaymentService{}
+	service := NewReconciliationService(&fake.Registry{
+		Payment:              &fakePaymentRepository{payments: []models.Payment{newPayment(1, constants.Pending)}},
+		StatusReconciliation: &fakeStatusReconciliationRepository{running: true},
+	}, clients.NewGatewayRegistry(config.Gateway{DefaultProvider: constants.ProviderMidtrans}, &fakeGateway{}), payment)
+
+	_, err := service.Reconcile(context.Background(), &dto.ReconcileRequest{OrderIDs: []string{"any"}})
+	if !errors.Is(err, errReconciliation.ErrReconcileInProgress) {
+		t.Errorf("Reconcile error = %v, want %v", err, errReconciliation.ErrReconcileInProgress)
+	}
+	if len(payment.applied) != 0 {
+		t.Errorf("applied %v while another reconciliation was running", payment.applied)
+	}
+}
+
+func TestReconcileRequiresFilter(t *testing.T) {
+	service := NewReconciliationService(&fake.Registry{}, clients.NewGatewayRegistry(config.Gateway{DefaultProvider: constants.ProviderMidtrans}), &fakePaymentService{})
+	_, err := service.Reconcile(context.Background(), &dto.ReconcileRequest{})
+	if err != errReconciliation.ErrReconcileFilterRequired {
+		t.Errorf("Reconcile error = %v, want %v", err, errReconciliation.ErrReconcileFilterRequired)
+	}
+}
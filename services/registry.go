@@ -1,37 +1,107 @@
 package services
 
 import (
-	clients "payment-service/clients/midtrans"
+	clients "payment-service/clients/gateway"
+	"payment-service/common/fx"
 	"payment-service/common/gcs"
 	"payment-service/controllers/kafka"
 	"payment-service/repositories"
+	services12 "payment-service/services/checkout"
+	services4 "payment-service/services/export"
+	services5 "payment-service/services/idempotency"
+	services8 "payment-service/services/ledger"
 	services "payment-service/services/payment"
+	services11 "payment-service/services/payment_link"
+	services7 "payment-service/services/payment_method"
+	services9 "payment-service/services/payout"
+	services2 "payment-service/services/reconciliation"
+	services3 "payment-service/services/settlement"
+	services6 "payment-service/services/subscription"
+	services10 "payment-service/services/voucher"
 )
 
 type Registry struct {
 	repository repositories.IRepositoryRegistry
 	gcs        gcs.IGSClient
 	kafka      kafka.IKafkaRegistry
-	midtrans   clients.IMidTransClient
+	gateway    clients.IGatewayRegistry
+	fx         fx.IRateProvider
 }
 
 type IServiceRegistry interface {
 	GetPayment() services.IPaymentService
+	GetReconciliation() services2.IReconciliationService
+	GetSettlement() services3.ISettlementService
+	GetExport() services4.IExportService
+	GetIdempotency() services5.IIdempotencyService
+	GetSubscription() services6.ISubscriptionService
+	GetPaymentMethod() services7.IPaymentMethodService
+	GetLedger() services8.ILedgerService
+	GetPayout() services9.IPayoutService
+	GetVoucher() services10.IVoucherService
+	GetPaymentLink() services11.IPaymentLinkService
+	GetCheckout() services12.ICheckoutService
 }
 
 func NewServiceRegistry(
 	repository repositories.IRepositoryRegistry,
 	gcs gcs.IGSClient,
 	kafka kafka.IKafkaRegistry,
-	midtrans clients.IMidTransClient) IServiceRegistry {
+	gateway clients.IGatewayRegistry,
+	fx fx.IRateProvider) IServiceRegistry {
 	return &Registry{
 		repository: repository,
 		gcs:        gcs,
 		kafka:      kafka,
-		midtrans:   midtrans,
+		gateway:    gateway,
+		fx:         fx,
 	}
 }
 
 func (r *Registry) GetPayment() services.IPaymentService {
-	return services.NewPaymentService(r.repository, r.gcs, r.kafka, r.midtrans)
+	return services.NewPaymentService(r.repository, r.gcs, r.kafka, r.gateway, r.fx, r.GetLedger(), r.GetVoucher())
+}
+
+func (r *Registry) GetReconciliation() services2.IReconciliationService {
+	return services2.NewReconciliationService(r.repository, r.gateway, r.GetPayment())
+}
+
+func (r *Registry) GetSettlement() services3.ISettlementService {
+	return services3.NewSettlementService(r.repository, r.GetLedger(), r.GetPayment())
+}
+
+func (r *Registry) GetExport() services4.IExportService {
+	return services4.NewExportService(r.repository, r.gcs)
+}
+
+func (r *Registry) GetIdempotency() services5.IIdempotencyService {
+	return services5.NewIdempotencyService(r.repository)
+}
+
+func (r *Registry) GetSubscription() services6.ISubscriptionService {
+	return services6.NewSubscriptionService(r.repository, r.kafka, r.gateway, r.GetPayment())
+}
+
+func (r *Registry) GetPaymentMethod() services7.IPaymentMethodService {
+	return services7.NewPaymentMethodService(r.repository)
+}
+
+func (r *Registry) GetLedger() services8.ILedgerService {
+	return services8.NewLedgerService(r.repository)
+}
+
+func (r *Registry) GetPayout() services9.IPayoutService {
+	return services9.NewPayoutService(r.repository, r.gcs, r.GetLedger())
+}
+
+func (r *Registry) GetVoucher() services10.IVoucherService {
+	return services10.NewVoucherService(r.repository)
+}
+
+func (r *Registry) GetPaymentLink() services11.IPaymentLinkService {
+	return services11.NewPaymentLinkService(r.repository, r.GetPayment())
+}
+
+func (r *Registry) GetCheckout() services12.ICheckoutService {
+	return services12.NewCheckoutService(r.repository, r.GetPayment())
 }
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"payment-service/common/money"
+	"payment-service/constants"
+	errSettlement "payment-service/constants/error/settlement"
+	"payment-service/domain/dto"
+	"strings"
+	"time"
+)
+
+const (
+	columnOrderID        = "orderID"
+	columnTransactionID  = "transactionID"
+	columnPaymentType    = "paymentType"
+	columnAmount         = "amount"
+	columnFee            = "fee"
+	columnNetAmount      = "netAmount"
+	columnSettlementTime = "settlementTime"
+)
+
+var settlementColumnAliases = map[string][]string{
+	columnOrderID:        {"order id", "order_id", "orderid"},
+	columnTransactionID:  {"transaction id", "transaction_id", "transactionid"},
+	columnPaymentType:    {"payment type", "payment_type", "payment method"},
+	columnAmount:         {"gross amount", "gross_amount", "amount"},
+	columnFee:            {"fee", "fee amount", "fee_amount", "mdr", "mdr amount", "transaction fee"},
+	columnNetAmount:      {"net amount", "net_amount", "nett amount"},
+	columnSettlementTime: {"settlement time", "settlement_time", "settlement date", "transaction time"},
+}
+
+var settlementTimeLayouts = []string{
+	time.DateTime,
+	time.RFC3339,
+	time.DateOnly,
+	"02/01/2006 15:04:05",
+	"02/01/2006",
+}
+
+func parseSettlementReport(file io.Reader) ([]dto.SettlementRow, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errSettlement.ErrSettlementColumnNotFound
+		}
+		return nil, errSettlement.ErrSettlementFileInvalid
+	}
+	columns := mapSettlementColumns(header)
+	_, hasOrderID := columns[columnOrderID]
+	_, hasAmount := columns[columnAmount]
+	if !hasOrderID || !hasAmount {
+		return nil, errSettlement.ErrSettlementColumnNotFound
+	}
+
+	rows := make([]dto.SettlementRow, 0)
+	rowNumber := 1
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		rowNumber++
+		if err != nil {
+			return nil, errSettlement.ErrSettlementRowInvalid
+		}
+		if isEmptyRecord(record) {
+			continue
+		}
+
+		row := dto.SettlementRow{
+			RowNumber:     rowNumber,
+			OrderID:       field(record, columns, columnOrderID),
+			TransactionID: field(record, columns, columnTransactionID),
+			PaymentType:   field(record, columns, columnPaymentType),
+		}
+		row.Amount, err = parseAmount(field(record, columns, columnAmount))
+		if err != nil {
+			return nil, errSettlement.ErrSettlementRowInvalid
+		}
+		row.Fee, err = parseFee(record, columns, row.Amount)
+		if err != nil {
+			return nil, errSettlement.ErrSettlementRowInvalid
+		}
+		if settlementTime := field(record, columns, columnSettlementTime); settlementTime != "" {
+			row.SettlementTime = parseSettlementTime(settlementTime)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func mapSettlementColumns(header []string) map[string]int {
+	columns := make(map[string]int)
+	for index, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for column, aliases := range settlementColumnAliases {
+			if _, ok := columns[column]; ok {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					columns[column] = index
+					break
+				}
+			}
+		}
+	}
+	return columns
+}
+
+func field(record []string, columns map[string]int, column string) string {
+	index, ok := columns[column]
+	if !ok || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+func isEmptyRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseAmount(value string) (money.Money, error) {
+	return money.Parse(value, constants.DefaultCurrency)
+}
+
+// parseFee reads the fee deducted from the row, reports that only carry the
+// net amount deduct the rest of the gross amount.
+func parseFee(record []string, columns map[string]int, amount money.Money) (*money.Money, error) {
+	if value := field(record, columns, columnFee); value != "" {
+		fee, err := parseAmount(value)
+		if err != nil {
+			return nil, err
+		}
+		return &fee, nil
+	}
+	if value := field(record, columns, columnNetAmount); value != "" {
+		net, err := parseAmount(value)
+		if err != nil {
+			return nil, err
+		}
+		fee, err := amount.Sub(net)
+		if err != nil {
+			return nil, err
+		}
+		return &fee, nil
+	}
+	return nil, nil
+}
+
+func parseSettlementTime(value string) *time.Time {
+	for _, layout := range settlementTimeLayouts {
+		parsed, err := time.ParseInLocation(layout, value, time.Local)
+		if err == nil {
+			return &parsed
+		}
+	}
+	return nil
+}
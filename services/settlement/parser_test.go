@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"payment-service/common/money"
+	"payment-service/constants"
+	errSettlement "payment-service/constants/error/settlement"
+)
+
+func TestParseSettlementReport(t *testing.T) {
+	report := "\ufeffOrder ID,Transaction ID,Payment Type,Gross Amount,Settlement Time\n" +
+		"6f1c1b2e-4c8e-4a52-9c55-3f0d1c0b1a01,tx-1,bank_transfer,\"150,000.00\",2024-05-01 10:00:00\n" +
+		",,,,\n" +
+		"6f1c1b2e-4c8e-4a52-9c55-3f0d1c0b1a02,tx-2,gopay,20000,01/05/2024\n"
+
+	rows, err := parseSettlementReport(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("parseSettlementReport unexpected error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("parsed %d rows, want 2", len(rows))
+	}
+
+	first := rows[0]
+	if first.RowNumber != 2 || first.OrderID != "6f1c1b2e-4c8e-4a52-9c55-3f0d1c0b1a01" ||
+		first.TransactionID != "tx-1" || first.PaymentType != "bank_transfer" {
+		t.Errorf("first row = %+v", first)
+	}
+	if !first.Amount.Equal(money.New(15000000, constants.DefaultCurrency)) {
+		t.Errorf("first row amount = %v, want 150000.00", first.Amount)
+	}
+	if first.SettlementTime == nil || first.SettlementTime.Format("2006-01-02 15:04") != "2024-05-01 10:00" {
+		t.Errorf("first row settlement time = %v, want 2024-05-01 10:00", first.SettlementTime)
+	}
+
+	// The empty line is skipped but still counted, so row numbers match the file.
+	second := rows[1]
+	if second.RowNumber != 4 || second.Amount.Amount != 2000000 {
+		t.Errorf("second row = %+v, want row 4 of 20000", second)
+	}
+	if second.SettlementTime == nil || second.SettlementTime.Format("2006-01-02") != "2024-05-01" {
+		t.Errorf("second row settlement time = %v, want 2024-05-01", second.SettlementTime)
+	}
+}
+
+func TestParseSettlementReportErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		report string
+		want   error
+	}{
+		{name: "empty file", report: "", want: errSettlement.ErrSettlementColumnNotFound},
+		{name: "missing amount column", report: "order_id,status\nabc,settlement\n", want: errSettlement.ErrSettlementColumnNotFound},
+		{name: "invalid amount", report: "order_id,gross_amount\nabc,ten\n", want: errSettlement.ErrSettlementRowInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSettlementReport(strings.NewReader(tt.report))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("parseSettlementReport error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSettlementReportFees(t *testing.T) {
+	tests := []struct {
+		name   string
+		report string
+		want   *int64
+	}{
+		{name: "fee column", report: "order_id,gross_amount,mdr\nabc,150000,4000\n", want: ptr(int64(400000))},
+		{name: "net amount column", report: "order_id,gross_amount,net amount\nabc,150000,146000\n", want: ptr(int64(400000))},
+		{name: "no fee", report: "order_id,gross_amount\nabc,150000\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseSettlementReport(strings.NewReader(tt.report))
+			if err != nil {
+				t.Fatalf("parseSettlementReport unexpected error: %v", err)
+			}
+			fee := rows[0].Fee
+			switch {
+			case tt.want == nil && fee != nil:
+				t.Errorf("fee = %v, want none", fee)
+			case tt.want != nil && (fee == nil || fee.Amount != *tt.want):
+				t.Errorf("fee = %v, want %d", fee, *tt.want)
+			}
+		})
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
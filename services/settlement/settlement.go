@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"payment-service/common/money"
+	"payment-service/common/utils"
+	"payment-service/constants"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories"
+	services "payment-service/services/ledger"
+	services2 "payment-service/services/payment"
+	"strings"
+	"time"
+)
+
+type ISettlementService interface {
+	GetAllWithPagination(context.Context, *dto.SettlementReconciliationRequestParam) (*utils.PaginationResult, error)
+	GetByUUID(context.Context, string) (*dto.SettlementReconciliationResponse, error)
+	Import(context.Context, *dto.SettlementImportRequest) (*dto.SettlementReconciliationResponse, error)
+}
+
+func NewSettlementService(
+	repository repositories.IRepositoryRegistry,
+	ledger services.ILedgerService,
+	payment services2.IPaymentService,
+) ISettlementService {
+	return &SettlementService{repository: repository, ledger: ledger, payment: payment}
+}
+
+type SettlementService struct {
+	repository repositories.IRepositoryRegistry
+	ledger     services.ILedgerService
+	payment    services2.IPaymentService
+}
+
+// settledFee is the fee a settlement report deducted from a matched payment.
+type settledFee struct {
+	payment *models.Payment
+	gross   money.Money
+	fee     money.Money
+}
+
+func (s *SettlementService) GetAllWithPagination(
+	ctx context.Context,
+	param *dto.SettlementReconciliationRequestParam,
+) (*utils.PaginationResult, error) {
+	reconciliations, total, err := s.repository.GetSettlementReconciliation().FindAllWithPagination(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]dto.SettlementReconciliationResponse, 0, len(reconciliations))
+	for _, reconciliation := range reconciliations {
+		results = append(results, *s.toResponse(&reconciliation))
+	}
+
+	paginationParam := utils.PaginationParam{
+		Count: total,
+		Page:  param.Page,
+		Limit: param.Limit,
+		Data:  results,
+	}
+	response := utils.GeneratePagination(paginationParam)
+	return &response, nil
+}
+
+func (s *SettlementService) GetByUUID(ctx context.Context, uuid string) (*dto.SettlementReconciliationResponse, error) {
+	reconciliation, err := s.repository.GetSettlementReconciliation().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(reconciliation), nil
+}
+
+func (s *SettlementService) Import(
+	ctx context.Context,
+	request *dto.SettlementImportRequest,
+) (*dto.SettlementReconciliationResponse, error) {
+	rows, err := parseSettlementReport(request.File)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.findPayments(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	byOrderID := make(map[string]*models.Payment, len(payments))
+	byTransactionID := make(map[string]*models.Payment, len(payments))
+	for i := range payments {
+		byOrderID[strings.ToLower(payments[i].GatewayOrderID)] = &payments[i]
+		if payments[i].TransactionID != nil {
+			byTransactionID[*payments[i].TransactionID] = &payments[i]
+		}
+	}
+
+	var (
+		reconciliation = &dto.SettlementReconciliationRequest{
+			UUID:      uuid.New(),
+			FileName:  request.FileName,
+			Source:    request.Source,
+			TotalRows: len(rows),
+			Lines:     make([]dto.SettlementLineRequest, 0, len(rows)),
+		}
+		seenRows     = make(map[string]int)
+		matched      = make(map[uint]bool)
+		fees         = make([]settledFee, 0)
+		firstSettled *time.Time
+		lastSettled  *time.Time
+	)
+	for _, row := range rows {
+		line := s.newLine(row)
+		if row.SettlementTime != nil {
+			if firstSettled == nil || row.SettlementTime.Before(*firstSettled) {
+				firstSettled = row.SettlementTime
+			}
+			if lastSettled == nil || row.SettlementTime.After(*lastSettled) {
+				lastSettled = row.SettlementTime
+			}
+		}
+
+		key := strings.ToLower(row.OrderID)
+		if key == "" {
+			key = row.TransactionID
+		}
+		if firstRow, ok := seenRows[key]; ok {
+			note := fmt.Sprintf("duplicate of row %d", firstRow)
+			line.Result = dto.SettlementDuplicated
+			line.Note = &note
+			reconciliation.TotalDuplicated++
+			reconciliation.Lines = append(reconciliation.Lines, line)
+			continue
+		}
+		seenRows[key] = row.RowNumber
+
+		payment, ok := byOrderID[strings.ToLower(row.OrderID)]
+		if !ok && row.TransactionID != "" {
+			payment, ok = byTransactionID[row.TransactionID]
+		}
+		if !ok {
+			line.Result = dto.SettlementMissingPayment
+			reconciliation.TotalMissing++
+			reconciliation.Lines = append(reconciliation.Lines, line)
+			continue
+		}
+
+		matched[payment.ID] = true
+		line.PaymentID = &payment.ID
+		// A partially captured payment settles for what was captured.
+		paymentAmount := s.payment.DueAmount(payment)
+		line.PaymentAmount = &paymentAmount
+		switch {
+		case payment.Status == nil || *payment.Status != constants.Settlement:
+			note := fmt.Sprintf("payment status is %s", payment.Status.GetStatusString())
+			line.Result = dto.SettlementStatusMismatch
+			line.Note = &note
+			reconciliation.TotalStatusMismatch++
+		case !paymentAmount.Equal(row.Amount):
+			line.Result = dto.SettlementAmountMismatch
+			reconciliation.TotalAmountMismatch++
+		default:
+			line.Result = dto.SettlementMatched
+			reconciliation.TotalMatched++
+			if row.Fee != nil {
+				fees = append(fees, settledFee{payment: payment, gross: row.Amount, fee: *row.Fee})
+			}
+		}
+		reconciliation.Lines = append(reconciliation.Lines, line)
+	}
+
+	if firstSettled != nil && lastSettled != nil {
+		startDate := startOfDay(*firstSettled)
+		endDate := startOfDay(*lastSettled).AddDate(0, 0, 1)
+		settled, err := s.repository.GetPayment().FindSettledBetween(ctx, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		for _, payment := range settled {
+			if matched[payment.ID] {
+				continue
+			}
+			orderID := payment.GatewayOrderID
+			due := s.payment.DueAmount(&payment)
+			reconciliation.Lines = append(reconciliation.Lines, dto.SettlementLineRequest{
+				PaymentID:      &payment.ID,
+				OrderID:        &orderID,
+				TransactionID:  payment.TransactionID,
+				PaymentAmount:  &due,
+				SettlementTime: payment.PaidAt,
+				Result:         dto.SettlementMissingInReport,
+			})
+			reconciliation.TotalMissing++
+		}
+	}
+
+	err = s.applyFees(ctx, fees)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.repository.GetSettlementReconciliation().Create(ctx, s.repository.GetTx(), reconciliation)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(result), nil
+}
+
+// applyFees replaces the fees estimated from the fee schedule with the ones
+// the gateway actually deducted, and books the difference in the ledger.
+func (s *SettlementService) applyFees(ctx context.Context, fees []settledFee) error {
+	if len(fees) == 0 {
+		return nil
+	}
+	return s.repository.GetTx().Transaction(func(tx *gorm.DB) error {
+		source := constants.FeeSourceReport
+		for _, settled := range fees {
+			payment := settled.payment
+			if payment.Fee != nil && *payment.Fee == settled.fee.Amount &&
+				payment.FeeSource != nil && *payment.FeeSource == source {
+				continue
+			}
+			var previous int64
+			if payment.Fee != nil {
+				previous = *payment.Fee
+			}
+			net, err := settled.gross.Sub(settled.fee)
+			if err != nil {
+				return err
+			}
+			_, err = s.repository.GetPayment().Update(ctx, tx, payment.ID, &dto.UpdatePaymentRequest{
+				Fee:       &settled.fee.Amount,
+				NetAmount: &net.Amount,
+				FeeSource: &source,
+			})
+			if err != nil {
+				return err
+			}
+			payment.Fee = &settled.fee.Amount
+			payment.NetAmount = &net.Amount
+			payment.FeeSource = &source
+			err = s.ledger.PostGatewayFee(ctx, tx, payment, previous)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SettlementService) findPayments(ctx context.Context, rows []dto.SettlementRow) ([]models.Payment, error) {
+	orderIDs := make([]string, 0, len(rows))
+	transactionIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.OrderID != "" {
+			orderIDs = append(orderIDs, row.OrderID)
+		}
+		if row.TransactionID != "" {
+			transactionIDs = append(transactionIDs, row.TransactionID)
+		}
+	}
+	return s.repository.GetPayment().FindByGatewayOrderIDsOrTransactionIDs(ctx, orderIDs, transactionIDs)
+}
+
+func (s *SettlementService) newLine(row dto.SettlementRow) dto.SettlementLineRequest {
+	line := dto.SettlementLineRequest{
+		RowNumber:      &row.RowNumber,
+		ReportAmount:   &row.Amount,
+		SettlementTime: row.SettlementTime,
+	}
+	if row.Fee != nil {
+		line.ReportFee = row.Fee
+	}
+	if row.OrderID != "" {
+		line.OrderID = &row.OrderID
+	}
+	if row.TransactionID != "" {
+		line.TransactionID = &row.TransactionID
+	}
+	if row.PaymentType != "" {
+		line.PaymentType = &row.PaymentType
+	}
+	return line
+}
+
+func (s *SettlementService) major(amount *int64, currency string) *float64 {
+	if amount == nil {
+		return nil
+	}
+	value := money.New(*amount, currency).Major()
+	return &value
+}
+
+func (s *SettlementService) toResponse(reconciliation *models.SettlementReconciliation) *dto.SettlementReconciliationResponse {
+	lines := make([]dto.SettlementLine, 0, len(reconciliation.Lines))
+	for _, line := range reconciliation.Lines {
+		lines = append(lines, dto.SettlementLine{
+			RowNumber:      line.RowNumber,
+			OrderID:        line.OrderID,
+			TransactionID:  line.TransactionID,
+			PaymentType:    line.PaymentType,
+			ReportAmount:   s.major(line.ReportAmount, line.Currency),
+			ReportFee:      s.major(line.ReportFee, line.Currency),
+			PaymentAmount:  s.major(line.PaymentAmount, line.Currency),
+			Currency:       line.Currency,
+			SettlementTime: line.SettlementTime,
+			Result:         dto.SettlementResult(line.Result),
+			Note:           line.Note,
+		})
+	}
+	return &dto.SettlementReconciliationResponse{
+		UUID:                reconciliation.UUID,
+		FileName:            reconciliation.FileName,
+		Source:              reconciliation.Source,
+		TotalRows:           reconciliation.TotalRows,
+		TotalMatched:        reconciliation.TotalMatched,
+		TotalAmountMismatch: reconciliation.TotalAmountMismatch,
+		TotalStatusMismatch: reconciliation.TotalStatusMismatch,
+		TotalMissing:        reconciliation.TotalMissing,
+		TotalDuplicated:     reconciliation.TotalDuplicated,
+		Lines:               lines,
+		CreatedAt:           reconciliation.CreatedAt,
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,527 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	clients "payment-service/clients/gateway"
+	"payment-service/common/money"
+	"payment-service/common/utils"
+	"payment-service/config"
+	"payment-service/constants"
+	errGateway "payment-service/constants/error/gateway"
+	errSubscription "payment-service/constants/error/subscription"
+	"payment-service/controllers/kafka"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories"
+	services "payment-service/services/payment"
+	"time"
+)
+
+const (
+	defaultChargeExpiry = time.Hour
+
+	eventSubscriptionCreated   = "SUBSCRIPTION_CREATED"
+	eventSubscriptionRenewed   = "SUBSCRIPTION_RENEWED"
+	eventSubscriptionPastDue   = "SUBSCRIPTION_PAST_DUE"
+	eventSubscriptionCancelled = "SUBSCRIPTION_CANCELLED"
+)
+
+var defaultRetrySchedule = []int{24, 72, 168}
+
+type ISubscriptionService interface {
+	GetPlans(context.Context) ([]dto.SubscriptionPlanResponse, error)
+	CreatePlan(context.Context, *dto.SubscriptionPlanRequest) (*dto.SubscriptionPlanResponse, error)
+	GetAllWithPagination(context.Context, *dto.SubscriptionRequestParam) (*utils.PaginationResult, error)
+	GetByUUID(context.Context, string, *uuid.UUID) (*dto.SubscriptionResponse, error)
+	Create(context.Context, *dto.SubscriptionRequest) (*dto.SubscriptionResponse, error)
+	Cancel(context.Context, string, *uuid.UUID, *dto.CancelSubscriptionRequest) (*dto.SubscriptionResponse, error)
+	Bill(context.Context) (*dto.SubscriptionBillingResult, error)
+}
+
+func NewSubscriptionService(
+	repository repositories.IRepositoryRegistry,
+	kafka kafka.IKafkaRegistry,
+	gateway clients.IGatewayRegistry,
+	payment services.IPaymentService,
+) ISubscriptionService {
+	return &SubscriptionService{
+		repository: repository,
+		kafka:      kafka,
+		gateway:    gateway,
+		payment:    payment,
+	}
+}
+
+type SubscriptionService struct {
+	repository repositories.IRepositoryRegistry
+	kafka      kafka.IKafkaRegistry
+	gateway    clients.IGatewayRegistry
+	payment    services.IPaymentService
+}
+
+func (s *SubscriptionService) GetPlans(ctx context.Context) ([]dto.SubscriptionPlanResponse, error) {
+	plans, err := s.repository.GetSubscriptionPlan().FindAllActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]dto.SubscriptionPlanResponse, 0, len(plans))
+	for _, plan := range plans {
+		results = append(results, s.toPlanResponse(&plan))
+	}
+	return results, nil
+}
+
+func (s *SubscriptionService) CreatePlan(ctx context.Context, request *dto.SubscriptionPlanRequest) (*dto.SubscriptionPlanResponse, error) {
+	_, err := s.repository.GetSubscriptionPlan().FindByCode(ctx, request.Code)
+	if err == nil {
+		return nil, errSubscription.ErrPlanCodeExist
+	}
+	if !errors.Is(err, errSubscription.ErrPlanNotFound) {
+		return nil, err
+	}
+	plan, err := s.repository.GetSubscriptionPlan().Create(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	response := s.toPlanResponse(plan)
+	return &response, nil
+}
+
+func (s *SubscriptionService) GetAllWithPagination(
+	ctx context.Context,
+	param *dto.SubscriptionRequestParam,
+) (*utils.PaginationResult, error) {
+	subscriptions, total, err := s.repository.GetSubscription().FindAllWithPagination(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]dto.SubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		results = append(results, *s.toResponse(&subscription))
+	}
+
+	paginationParam := utils.PaginationParam{
+		Count: total,
+		Page:  param.Page,
+		Limit: param.Limit,
+		Data:  results,
+	}
+	response := utils.GeneratePagination(paginationParam)
+	return &response, nil
+}
+
+func (s *SubscriptionService) GetByUUID(ctx context.Context, uuid string, customerID *uuid.UUID) (*dto.SubscriptionResponse, error) {
+	subscription, err := s.find(ctx, uuid, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(subscription), nil
+}
+
+func (s *SubscriptionService) Create(ctx context.Context, request *dto.SubscriptionRequest) (*dto.SubscriptionResponse, error) {
+	plan, err := s.repository.GetSubscriptionPlan().FindByCode(ctx, request.PlanCode)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.IsActive {
+		return nil, errSubscription.ErrPlanNotFound
+	}
+
+	var activationURL *string
+	token := request.PaymentToken
+	switch request.PaymentMethod {
+	case constants.PaymentMethodCard:
+		if token == "" {
+			return nil, errSubscription.ErrPaymentTokenRequired
+		}
+	case constants.PaymentMethodGopay:
+		if request.Phone == "" {
+			return nil, errSubscription.ErrPhoneNumberRequired
+		}
+		link, linkErr := s.linkAccount(request)
+		if linkErr != nil {
+			return nil, linkErr
+		}
+		token = link.AccountID
+		activationURL = link.ActivationURL
+	}
+
+	nextBillingAt := time.Now().AddDate(0, 0, plan.TrialDays)
+	subscription := &models.Subscription{
+		PlanID:        plan.ID,
+		CustomerID:    request.CustomerID,
+		CustomerName:  request.CustomerName,
+		CustomerEmail: request.CustomerEmail,
+		PaymentMethod: request.PaymentMethod,
+		PaymentToken:  token,
+		Status:        constants.SubscriptionActive,
+		NextBillingAt: &nextBillingAt,
+	}
+	if request.Phone != "" {
+		subscription.CustomerPhone = &request.Phone
+	}
+	subscription, err = s.repository.GetSubscription().Create(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Plan = *plan
+
+	err = s.produceEvent(subscription, eventSubscriptionCreated, nil, nil)
+	if err != nil {
+		logrus.Errorf("failed to produce %s for subscription %s: %v", eventSubscriptionCreated, subscription.UUID, err)
+	}
+	response := s.toResponse(subscription)
+	response.ActivationURL = activationURL
+	return response, nil
+}
+
+func (s *SubscriptionService) Cancel(
+	ctx context.Context,
+	uuid string,
+	customerID *uuid.UUID,
+	request *dto.CancelSubscriptionRequest,
+) (*dto.SubscriptionResponse, error) {
+	subscription, err := s.find(ctx, uuid, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.Status == constants.SubscriptionCancelled {
+		return nil, errSubscription.ErrSubscriptionCancelled
+	}
+	reason := request.Reason
+	if reason == "" {
+		reason = "cancelled on request"
+	}
+	err = s.cancel(ctx, subscription, reason, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(subscription), nil
+}
+
+// Bill first settles the charges in flight, renewing or dunning their
+// subscriptions, then charges every subscription that is due.
+func (s *SubscriptionService) Bill(ctx context.Context) (*dto.SubscriptionBillingResult, error) {
+	result := &dto.SubscriptionBillingResult{}
+	awaiting, err := s.repository.GetSubscription().FindAwaitingPayment(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, subscription := range awaiting {
+		err = s.checkPayment(ctx, &subscription, result)
+		if err != nil {
+			logrus.Errorf("failed to check payment of subscription %s: %v", subscription.UUID, err)
+		}
+	}
+
+	due, err := s.repository.GetSubscription().FindDue(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, subscription := range due {
+		err = s.charge(ctx, &subscription, result)
+		if err != nil {
+			logrus.Errorf("failed to charge subscription %s: %v", subscription.UUID, err)
+		}
+	}
+	return result, nil
+}
+
+func (s *SubscriptionService) find(ctx context.Context, uuid string, customerID *uuid.UUID) (*models.Subscription, error) {
+	subscription, err := s.repository.GetSubscription().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if customerID != nil && subscription.CustomerID != *customerID {
+		return nil, errSubscription.ErrSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+func (s *SubscriptionService) linkAccount(request *dto.SubscriptionRequest) (*dto.GatewayAccountLink, error) {
+	gateway, err := s.gateway.Get(constants.ProviderMidtrans)
+	if err != nil {
+		return nil, err
+	}
+	linker, ok := gateway.(clients.AccountLinker)
+	if !ok {
+		return nil, errGateway.ErrGatewayNotSupported
+	}
+	return linker.LinkAccount(&dto.GatewayAccountLinkRequest{
+		Phone:       request.Phone,
+		RedirectURL: request.RedirectURL,
+	})
+}
+
+func (s *SubscriptionService) chargeExpiry() time.Duration {
+	if config.Config.Subscription.ChargeExpiryInMinutes <= 0 {
+		return defaultChargeExpiry
+	}
+	return time.Duration(config.Config.Subscription.ChargeExpiryInMinutes) * time.Minute
+}
+
+func (s *SubscriptionService) retrySchedule() []int {
+	if len(config.Config.Subscription.RetryScheduleInHours) == 0 {
+		return defaultRetrySchedule
+	}
+	return config.Config.Subscription.RetryScheduleInHours
+}
+
+// charge creates the payment of the next period. The order id is derived from
+// the subscription, period and retry, so a charge repeated after a crash
+// finds the payment of the first try instead of charging twice.
+func (s *SubscriptionService) charge(ctx context.Context, subscription *models.Subscription, result *dto.SubscriptionBillingResult) error {
+	now := time.Now()
+	claimed, err := s.repository.GetSubscription().Claim(ctx, subscription, now.Add(s.chargeExpiry()))
+	if err != nil || !claimed {
+		return err
+	}
+
+	plan := subscription.Plan
+	period := subscription.Cycle + 1
+	orderID := uuid.NewSHA1(subscription.UUID, []byte(fmt.Sprintf("%d-%d", period, subscription.RetryCount)))
+	amount := money.New(plan.Amount, plan.Currency)
+	description := fmt.Sprintf("%s subscription, period %d", plan.Name, period)
+	customer := &dto.CustomerDetail{
+		Name:  subscription.CustomerName,
+		Email: subscription.CustomerEmail,
+	}
+	if subscription.CustomerPhone != nil {
+		customer.Phone = *subscription.CustomerPhone
+	}
+	payment, err := s.payment.Charge(ctx, &dto.ChargeRequest{
+		PaymentRequest: dto.PaymentRequest{
+			OrderID:        orderID.String(),
+			PaymentToken:   subscription.PaymentToken,
+			SubscriptionID: &subscription.ID,
+			ExpiredAt:      now.Add(s.chargeExpiry()),
+			Amount:         amount.Major(),
+			Currency:       amount.Currency,
+			Description:    &description,
+			CustomerDetail: customer,
+			ItemDetails: []dto.ItemDetail{
+				{
+					ID:       plan.Code,
+					Name:     plan.Name,
+					Amount:   amount.Major(),
+					Quantity: 1,
+				},
+			},
+		},
+		PaymentMethod: subscription.PaymentMethod,
+	})
+	if err != nil {
+		result.Failed++
+		return s.fail(ctx, subscription, nil, err.Error())
+	}
+	result.Charged++
+	return s.repository.GetSubscription().Update(ctx, subscription.ID, &dto.UpdateSubscriptionRequest{
+		PendingPaymentID: &payment.UUID,
+	})
+}
+
+func (s *SubscriptionService) checkPayment(ctx context.Context, subscription *models.Subscription, result *dto.SubscriptionBillingResult) error {
+	payment, err := s.repository.GetPayment().FindByUUID(ctx, subscription.PendingPaymentID.String())
+	if err != nil {
+		return err
+	}
+	switch *payment.Status {
+	case constants.Settlement:
+		result.Renewed++
+		return s.renew(ctx, subscription, payment)
+	case constants.Expire, constants.Cancel:
+		result.Failed++
+		return s.fail(ctx, subscription, &payment.UUID, fmt.Sprintf("payment %s", payment.Status.GetStatusString()))
+	case constants.Initial, constants.Pending:
+		if payment.ExpiredAt != nil && time.Now().After(*payment.ExpiredAt) {
+			result.Failed++
+			return s.fail(ctx, subscription, &payment.UUID, "payment was not completed before it expired")
+		}
+	}
+	return nil
+}
+
+func (s *SubscriptionService) renew(ctx context.Context, subscription *models.Subscription, payment *models.Payment) error {
+	start := time.Now()
+	if subscription.Status == constants.SubscriptionActive && subscription.CurrentPeriodEnd != nil {
+		start = *subscription.CurrentPeriodEnd
+	}
+	end := s.periodEnd(&subscription.Plan, start)
+	status := constants.SubscriptionActive
+	cycle := subscription.Cycle + 1
+	retryCount := 0
+	updated, err := s.update(ctx, subscription, &payment.UUID, &dto.UpdateSubscriptionRequest{
+		Status:              &status,
+		Cycle:               &cycle,
+		RetryCount:          &retryCount,
+		CurrentPeriodStart:  &start,
+		CurrentPeriodEnd:    &end,
+		NextBillingAt:       &end,
+		ClearPendingPayment: true,
+		LastPaymentID:       &payment.UUID,
+	})
+	if err != nil || !updated {
+		return err
+	}
+	subscription.Status = status
+	subscription.Cycle = cycle
+	subscription.RetryCount = retryCount
+	subscription.CurrentPeriodStart = &start
+	subscription.CurrentPeriodEnd = &end
+	subscription.NextBillingAt = &end
+	return s.produceEvent(subscription, eventSubscriptionRenewed, &payment.UUID, nil)
+}
+
+// fail schedules the next retry of a failed charge following the dunning
+// schedule, the subscription is cancelled once the schedule is exhausted.
+func (s *SubscriptionService) fail(ctx context.Context, subscription *models.Subscription, paymentID *uuid.UUID, reason string) error {
+	retryCount := subscription.RetryCount + 1
+	schedule := s.retrySchedule()
+	if retryCount > len(schedule) {
+		return s.cancel(ctx, subscription, fmt.Sprintf("charge failed after %d retries: %s", len(schedule), reason), paymentID)
+	}
+
+	status := constants.SubscriptionPastDue
+	nextBillingAt := time.Now().Add(time.Duration(schedule[retryCount-1]) * time.Hour)
+	updated, err := s.update(ctx, subscription, paymentID, &dto.UpdateSubscriptionRequest{
+		Status:              &status,
+		RetryCount:          &retryCount,
+		NextBillingAt:       &nextBillingAt,
+		ClearPendingPayment: true,
+		FailureReason:       &reason,
+	})
+	if err != nil || !updated {
+		return err
+	}
+	subscription.Status = status
+	subscription.RetryCount = retryCount
+	subscription.NextBillingAt = &nextBillingAt
+	subscription.FailureReason = &reason
+	return s.produceEvent(subscription, eventSubscriptionPastDue, paymentID, &reason)
+}
+
+func (s *SubscriptionService) cancel(ctx context.Context, subscription *models.Subscription, reason string, paymentID *uuid.UUID) error {
+	status := constants.SubscriptionCancelled
+	now := time.Now()
+	updated, err := s.update(ctx, subscription, paymentID, &dto.UpdateSubscriptionRequest{
+		Status:              &status,
+		ClearPendingPayment: true,
+		CancelledAt:         &now,
+		CancelReason:        &reason,
+	})
+	if err != nil || !updated {
+		return err
+	}
+	subscription.Status = status
+	subscription.CancelledAt = &now
+	subscription.CancelReason = &reason
+	return s.produceEvent(subscription, eventSubscriptionCancelled, paymentID, &reason)
+}
+
+// update applies the outcome of paymentID only while it is still the pending
+// payment of the subscription, another instance that already applied it makes
+// this a no-op and the caller must not emit the event again.
+func (s *SubscriptionService) update(
+	ctx context.Context,
+	subscription *models.Subscription,
+	paymentID *uuid.UUID,
+	request *dto.UpdateSubscriptionRequest,
+) (bool, error) {
+	if paymentID == nil || subscription.PendingPaymentID == nil || *subscription.PendingPaymentID != *paymentID {
+		err := s.repository.GetSubscription().Update(ctx, subscription.ID, request)
+		return err == nil, err
+	}
+	return s.repository.GetSubscription().UpdatePending(ctx, subscription.ID, *paymentID, request)
+}
+
+func (s *SubscriptionService) periodEnd(plan *models.SubscriptionPlan, start time.Time) time.Time {
+	switch plan.Interval {
+	case constants.IntervalDay:
+		return start.AddDate(0, 0, plan.IntervalCount)
+	case constants.IntervalWeek:
+		return start.AddDate(0, 0, 7*plan.IntervalCount)
+	case constants.IntervalYear:
+		return start.AddDate(plan.IntervalCount, 0, 0)
+	}
+	return start.AddDate(0, plan.IntervalCount, 0)
+}
+
+func (s *SubscriptionService) produceEvent(
+	subscription *models.Subscription,
+	name string,
+	paymentID *uuid.UUID,
+	reason *string,
+) error {
+	message := dto.KafkaSubscriptionMessage{
+		Event: dto.KafkaEvent{
+			Name: name,
+		},
+		MetaData: dto.KafkaMetaData{
+			Sender:    "payment-service",
+			SendingAt: time.Now().Format(time.RFC3339),
+		},
+		Body: dto.KafkaSubscriptionBody{
+			Type: "JSON",
+			Data: &dto.KafkaSubscriptionData{
+				SubscriptionID:     subscription.UUID,
+				CustomerID:         subscription.CustomerID,
+				PlanCode:           subscription.Plan.Code,
+				Status:             subscription.Status.String(),
+				Cycle:              subscription.Cycle,
+				CurrentPeriodStart: subscription.CurrentPeriodStart,
+				CurrentPeriodEnd:   subscription.CurrentPeriodEnd,
+				NextBillingAt:      subscription.NextBillingAt,
+				PaymentID:          paymentID,
+				Reason:             reason,
+			},
+		},
+	}
+	topic := config.Config.Kafka.SubscriptionTopic
+	if topic == "" {
+		topic = config.Config.Kafka.Topic
+	}
+	messageJson, _ := json.Marshal(message)
+	return s.kafka.GetKafkaProducer().ProduceMessage(topic, messageJson)
+}
+
+func (s *SubscriptionService) toPlanResponse(plan *models.SubscriptionPlan) dto.SubscriptionPlanResponse {
+	return dto.SubscriptionPlanResponse{
+		UUID:          plan.UUID,
+		Code:          plan.Code,
+		Name:          plan.Name,
+		Description:   plan.Description,
+		Amount:        money.New(plan.Amount, plan.Currency).Major(),
+		Currency:      plan.Currency,
+		Interval:      plan.Interval,
+		IntervalCount: plan.IntervalCount,
+		TrialDays:     plan.TrialDays,
+		IsActive:      plan.IsActive,
+		CreatedAt:     plan.CreatedAt,
+	}
+}
+
+func (s *SubscriptionService) toResponse(subscription *models.Subscription) *dto.SubscriptionResponse {
+	return &dto.SubscriptionResponse{
+		UUID:               subscription.UUID,
+		CustomerID:         subscription.CustomerID,
+		Plan:               s.toPlanResponse(&subscription.Plan),
+		PaymentMethod:      subscription.PaymentMethod,
+		Status:             subscription.Status,
+		Cycle:              subscription.Cycle,
+		RetryCount:         subscription.RetryCount,
+		CurrentPeriodStart: subscription.CurrentPeriodStart,
+		CurrentPeriodEnd:   subscription.CurrentPeriodEnd,
+		NextBillingAt:      subscription.NextBillingAt,
+		LastPaymentID:      subscription.LastPaymentID,
+		FailureReason:      subscription.FailureReason,
+		CancelledAt:        subscription.CancelledAt,
+		CancelReason:       subscription.CancelReason,
+		CreatedAt:          subscription.CreatedAt,
+		UpdatedAt:          subscription.UpdatedAt,
+	}
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	clients "payment-service/clients/gateway"
+	"payment-service/common/fx"
+	"payment-service/common/money"
+	"payment-service/config"
+	"payment-service/constants"
+	errPayment "payment-service/constants/error/payment"
+	"payment-service/controllers/kafka"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories/fake"
+	repositories "payment-service/repositories/payment"
+	repositories3 "payment-service/repositories/payment_history"
+	repositories4 "payment-service/repositories/payment_split"
+	repositories2 "payment-service/repositories/subscription"
+	services "payment-service/services/payment"
+)
+
+type fakeSubscriptionRepository struct {
+	repositories2.ISubscriptionRepository
+	awaiting []models.Subscription
+	due      []models.Subscription
+	updates  map[uint]*dto.UpdateSubscriptionRequest
+	// applied holds the subscriptions whose pending payment another instance
+	// has already applied.
+	applied map[uint]bool
+}
+
+func (f *fakeSubscriptionRepository) FindAwaitingPayment(context.Context) ([]models.Subscription, error) {
+	return f.awaiting, nil
+}
+
+func (f *fakeSubscriptionRepository) FindDue(context.Context, time.Time) ([]models.Subscription, error) {
+	return f.due, nil
+}
+
+func (f *fakeSubscriptionRepository) Claim(context.Context, *models.Subscription, time.Time) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeSubscriptionRepository) Update(_ context.Context, id uint, request *dto.UpdateSubscriptionRequest) error {
+	f.updates[id] = request
+	return nil
+}
+
+func (f *fakeSubscriptionRepository) UpdatePending(
+	_ context.Context,
+	id uint,
+	_ uuid.UUID,
+	request *dto.UpdateSubscriptionRequest,
+) (bool, error) {
+	if f.applied[id] {
+		return false, nil
+	}
+	f.updates[id] = request
+	return true, nil
+}
+
+type fakePaymentRepository struct {
+	repositories.IPaymentRepository
+	payments map[string]*models.Payment
+	created  []*dto.PaymentRequest
+}
+
+func (f *fakePaymentRepository) LockOrder(context.Context, *gorm.DB, string) error {
+	return nil
+}
+
+func (f *fakePaymentRepository) FindByOrderID(context.Context, string) (*models.Payment, error) {
+	return nil, errPayment.ErrPaymentNotFound
+}
+
+func (f *fakePaymentRepository) Create(_ context.Context, _ *gorm.DB, request *dto.PaymentRequest) (*models.Payment, error) {
+	f.created = append(f.created, request)
+	return &models.Payment{
+		ID:             uint(len(f.created)),
+		UUID:           uuid.New(),
+		SubscriptionID: request.SubscriptionID,
+		Amount:         money.FromMajor(request.Amount, request.Currency).Amount,
+		Currency:       request.Currency,
+		Status:         request.Status,
+	}, nil
+}
+
+type fakePaymentHistoryRepository struct {
+	repositories3.IPaymentHistoryRepository
+}
+
+func (f *fakePaymentHistoryRepository) Create(context.Context, *gorm.DB, *dto.PaymentHistoryRequest) error {
+	return nil
+}
+
+type fakePaymentSplitRepository struct {
+	repositories4.IPaymentSplitRepository
+}
+
+func (f *fakePaymentSplitRepository) Create(context.Context, *gorm.DB, []dto.PaymentSplitRequest) error {
+	return nil
+}
+
+func (f *fakePaymentRepository) FindByUUID(_ context.Context, id string) (*models.Payment, error) {
+	payment, ok := f.payments[id]
+	if !ok {
+		return nil, errPayment.ErrPaymentNotFound
+	}
+	return payment, nil
+}
+
+type fakePaymentService struct {
+	services.IPaymentService
+	charges []*dto.ChargeRequest
+}
+
+func (f *fakePaymentService) Charge(_ context.Context, request *dto.ChargeRequest) (*dto.PaymentResponse, error) {
+	f.charges = append(f.charges, request)
+	return &dto.PaymentResponse{UUID: uuid.New()}, nil
+}
+
+// fakeCharger charges saved cards directly like the Midtrans Core API.
+type fakeCharger struct {
+	clients.PaymentGateway
+}
+
+func (f *fakeCharger) Provider() string {
+	return constants.ProviderMidtrans
+}
+
+func (f *fakeCharger) SupportsCurrency(currency string) bool {
+	return currency == constants.DefaultCurrency
+}
+
+func (f *fakeCharger) Charge(request *dto.PaymentRequest) (*dto.GatewayCharge, error) {
+	return &dto.GatewayCharge{TransactionID: "tx-" + request.GatewayOrderID}, nil
+}
+
+type fakeKafka struct {
+	messages [][]byte
+}
+
+func (f *fakeKafka) GetKafkaProducer() kafka.IKafka {
+	return f
+}
+
+func (f *fakeKafka) ProduceMessage(_ string, message []byte) error {
+	f.messages = append(f.messages, message)
+	return nil
+}
+
+func TestPeriodEnd(t *testing.T) {
+	service := &SubscriptionService{}
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		interval string
+		count    int
+		want     time.Time
+	}{
+		{interval: constants.IntervalDay, count: 10, want: time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC)},
+		{interval: constants.IntervalWeek, count: 2, want: time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC)},
+		{interval: constants.IntervalMonth, count: 1, want: time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)},
+		{interval: constants.IntervalYear, count: 1, want: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got := service.periodEnd(&models.SubscriptionPlan{Interval: tt.interval, IntervalCount: tt.count}, start)
+		if !got.Equal(tt.want) {
+			t.Errorf("periodEnd(%d %s) = %v, want %v", tt.count, tt.interval, got, tt.want)
+		}
+	}
+}
+
+func TestBill(t *testing.T) {
+	schedule := config.Config.Subscription.RetryScheduleInHours
+	defer func() { config.Config.Subscription.RetryScheduleInHours = schedule }()
+	config.Config.Subscription.RetryScheduleInHours = []int{24, 72}
+
+	plan := models.SubscriptionPlan{Code: "pro", Name: "Pro", Amount: 9900000, Currency: "IDR", Interval: constants.IntervalMonth, IntervalCount: 1}
+	periodEnd := time.Now()
+	settledID, expiredID, exhaustedID, appliedID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	settled := constants.Settlement
+	expired := constants.Expire
+	repository := &fakeSubscriptionRepository{
+		awaiting: []models.Subscription{
+			{ID: 1, Plan: plan, Status: constants.SubscriptionActive, Cycle: 3, CurrentPeriodEnd: &periodEnd, PendingPaymentID: &settledID},
+			{ID: 2, Plan: plan, Status: constants.SubscriptionActive, Cycle: 3, PendingPaymentID: &expiredID},
+			{ID: 3, Plan: plan, Status: constants.SubscriptionPastDue, Cycle: 3, RetryCount: 2, PendingPaymentID: &exhaustedID},
+			{ID: 5, Plan: plan, Status: constants.SubscriptionActive, Cycle: 3, CurrentPeriodEnd: &periodEnd, PendingPaymentID: &appliedID},
+		},
+		due:     []models.Subscription{{ID: 4, UUID: uuid.New(), Plan: plan, PaymentMethod: constants.PaymentMethodCard, PaymentToken: "token"}},
+		updates: map[uint]*dto.UpdateSubscriptionRequest{},
+		applied: map[uint]bool{5: true},
+	}
+	payment := &fakePaymentService{}
+	kafka := &fakeKafka{}
+	service := NewSubscriptionService(&fake.Registry{
+		Subscription: repository,
+		Payment: &fakePaymentRepository{payments: map[string]*models.Payment{
+			settledID.String():   {UUID: settledID, Status: &settled},
+			expiredID.String():   {UUID: expiredID, Status: &expired},
+			exhaustedID.String(): {UUID: exhaustedID, Status: &expired},
+			appliedID.String():   {UUID: appliedID, Status: &settled},
+		}},
+	}, kafka, nil, payment)
+
+	result, err := service.Bill(context.Background())
+	if err != nil {
+		t.Fatalf("Bill unexpected error: %v", err)
+	}
+	if result.Renewed != 2 || result.Failed != 2 || result.Charged != 1 {
+		t.Errorf("result = %+v, want 2 renewed, 2 failed and 1 charged", result)
+	}
+
+	// A renewal continues from the end of the current period.
+	renewed := repository.updates[1]
+	if *renewed.Cycle != 4 || !renewed.CurrentPeriodStart.Equal(periodEnd) || !renewed.NextBillingAt.Equal(periodEnd.AddDate(0, 1, 0)) {
+		t.Errorf("renewal = %+v, want cycle 4 billed a month after the current period", renewed)
+	}
+
+	pastDue := repository.updates[2]
+	retryAt := time.Now().Add(24 * time.Hour)
+	if *pastDue.Status != constants.SubscriptionPastDue || *pastDue.RetryCount != 1 ||
+		pastDue.NextBillingAt.Sub(retryAt).Abs() > time.Minute {
+		t.Errorf("failed charge = %+v, want past due retried in 24 hours", pastDue)
+	}
+	if cancelled := repository.updates[3]; *cancelled.Status != constants.SubscriptionCancelled {
+		t.Errorf("exhausted schedule left the subscription %s, want cancelled", *cancelled.Status)
+	}
+	// A payment another instance has applied is neither applied nor announced
+	// again.
+	if _, ok := repository.updates[5]; ok || len(kafka.messages) != 3 {
+		t.Errorf("applied payment updated the subscription again with %d events, want 3", len(kafka.messages))
+	}
+
+	if len(payment.charges) != 1 {
+		t.Fatalf("charged %d subscriptions, want 1", len(payment.charges))
+	}
+	charge := payment.charges[0]
+	if charge.Amount != 99000 || charge.PaymentToken != "token" || *charge.SubscriptionID != 4 {
+		t.Errorf("charge = %+v, want 99000 with the saved token for subscription 4", charge)
+	}
+	if repository.updates[4].PendingPaymentID == nil {
+		t.Error("charged subscription does not wait for its payment")
+	}
+}
+
+func TestBillStoresSubscriptionOfCharge(t *testing.T) {
+	plan := models.SubscriptionPlan{Code: "pro", Name: "Pro", Amount: 9900000, Currency: "IDR", Interval: constants.IntervalMonth, IntervalCount: 1}
+	subscriptions := &fakeSubscriptionRepository{
+		due:     []models.Subscription{{ID: 4, UUID: uuid.New(), Plan: plan, PaymentMethod: constants.PaymentMethodCard, PaymentToken: "token"}},
+		updates: map[uint]*dto.UpdateSubscriptionRequest{},
+	}
+	payments := &fakePaymentRepository{}
+	registry := &fake.Registry{
+		DB:             fake.DB(),
+		Subscription:   subscriptions,
+		Payment:        payments,
+		PaymentHistory: &fakePaymentHistoryRepository{},
+		PaymentSplit:   &fakePaymentSplitRepository{},
+	}
+	gateway := clients.NewGatewayRegistry(config.Gateway{DefaultProvider: constants.ProviderMidtrans}, &fakeCharger{})
+	payment := services.NewPaymentService(registry, nil, &fakeKafka{}, gateway, fx.NewStaticProvider(constants.DefaultCurrency, nil), nil, nil)
+	service := NewSubscriptionService(registry, &fakeKafka{}, gateway, payment)
+
+	result, err := service.Bill(context.Background())
+	if err != nil {
+		t.Fatalf("Bill unexpected error: %v", err)
+	}
+	if result.Charged != 1 || len(payments.created) != 1 {
+		t.Fatalf("result = %+v with %d payments, want 1 charged", result, len(payments.created))
+	}
+	// The renewal finds its subscription through the stored payment.
+	if stored := payments.created[0]; stored.SubscriptionID == nil || *stored.SubscriptionID != 4 {
+		t.Errorf("stored payment subscription = %v, want 4", stored.SubscriptionID)
+	}
+}
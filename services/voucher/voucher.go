@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"payment-service/common/money"
+	"payment-service/common/utils"
+	"payment-service/constants"
+	errVoucher "payment-service/constants/error/voucher"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories"
+	"strings"
+	"time"
+)
+
+type IVoucherService interface {
+	GetAllWithPagination(context.Context, *dto.VoucherRequestParam) (*utils.PaginationResult, error)
+	GetByUUID(context.Context, string) (*dto.VoucherResponse, error)
+	Create(context.Context, *dto.VoucherRequest) (*dto.VoucherResponse, error)
+	Deactivate(context.Context, string) (*dto.VoucherResponse, error)
+	Quote(context.Context, string, money.Money, *uuid.UUID) (*dto.VoucherQuote, error)
+	Reserve(context.Context, *gorm.DB, *dto.VoucherQuote, *models.Payment) error
+	Redeem(context.Context, *gorm.DB, *models.Payment) error
+	Release(context.Context, *gorm.DB, *models.Payment) error
+	Retake(context.Context, *gorm.DB, *models.Payment) error
+}
+
+func NewVoucherService(repository repositories.IRepositoryRegistry) IVoucherService {
+	return &VoucherService{repository: repository}
+}
+
+type VoucherService struct {
+	repository repositories.IRepositoryRegistry
+}
+
+func (v *VoucherService) GetAllWithPagination(ctx context.Context, param *dto.VoucherRequestParam) (*utils.PaginationResult, error) {
+	vouchers, total, err := v.repository.GetVoucher().FindAllWithPagination(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]dto.VoucherResponse, 0, len(vouchers))
+	for _, voucher := range vouchers {
+		response, err := v.toResponse(ctx, &voucher)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *response)
+	}
+
+	paginationParam := utils.PaginationParam{
+		Count: total,
+		Page:  param.Page,
+		Limit: param.Limit,
+		Data:  results,
+	}
+	response := utils.GeneratePagination(paginationParam)
+	return &response, nil
+}
+
+func (v *VoucherService) GetByUUID(ctx context.Context, uuid string) (*dto.VoucherResponse, error) {
+	voucher, err := v.repository.GetVoucher().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	return v.toResponse(ctx, voucher)
+}
+
+func (v *VoucherService) Create(ctx context.Context, request *dto.VoucherRequest) (*dto.VoucherResponse, error) {
+	if request.Type == constants.VoucherPercentage && request.Value > 100 {
+		return nil, errVoucher.ErrVoucherValueInvalid
+	}
+	if request.StartsAt != nil && request.EndsAt != nil && !request.EndsAt.After(*request.StartsAt) {
+		return nil, errVoucher.ErrVoucherPeriodInvalid
+	}
+	request.Code = strings.ToUpper(strings.TrimSpace(request.Code))
+	_, err := v.repository.GetVoucher().FindByCode(ctx, request.Code)
+	if err == nil {
+		return nil, errVoucher.ErrVoucherCodeExist
+	}
+	if !errors.Is(err, errVoucher.ErrVoucherNotFound) {
+		return nil, err
+	}
+	voucher, err := v.repository.GetVoucher().Create(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return v.toResponse(ctx, voucher)
+}
+
+func (v *VoucherService) Deactivate(ctx context.Context, uuid string) (*dto.VoucherResponse, error) {
+	voucher, err := v.repository.GetVoucher().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	err = v.repository.GetVoucher().Deactivate(ctx, voucher.ID)
+	if err != nil {
+		return nil, err
+	}
+	voucher.IsActive = false
+	return v.toResponse(ctx, voucher)
+}
+
+// Quote works out the discount of a voucher on an amount and checks that it
+// may still be used, Reserve checks the usage limits again under a lock.
+func (v *VoucherService) Quote(
+	ctx context.Context,
+	code string,
+	amount money.Money,
+	customerID *uuid.UUID,
+) (*dto.VoucherQuote, error) {
+	voucher, err := v.repository.GetVoucher().FindByCode(ctx, strings.TrimSpace(code))
+	if err != nil {
+		return nil, err
+	}
+	err = v.check(ctx, v.repository.GetTx(), voucher, amount, customerID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.VoucherQuote{
+		VoucherID: voucher.ID,
+		Code:      voucher.Code,
+		Amount:    amount,
+		Discount:  v.discount(voucher, amount),
+	}, nil
+}
+
+func (v *VoucherService) Reserve(ctx context.Context, tx *gorm.DB, quote *dto.VoucherQuote, payment *models.Payment) error {
+	voucher, err := v.repository.GetVoucher().Lock(ctx, tx, quote.VoucherID)
+	if err != nil {
+		return err
+	}
+	// The payment amount is discounted and may carry tax by now, the minimum
+	// spend applies to the amount quoted on like in Quote.
+	err = v.check(ctx, tx, voucher, quote.Amount, payment.CustomerID)
+	if err != nil {
+		return err
+	}
+	return v.repository.GetVoucher().CreateRedemption(ctx, tx, &dto.VoucherRedemptionRequest{
+		VoucherID:  voucher.ID,
+		PaymentID:  payment.ID,
+		CustomerID: payment.CustomerID,
+		Discount:   quote.Discount,
+	})
+}
+
+func (v *VoucherService) Redeem(ctx context.Context, tx *gorm.DB, payment *models.Payment) error {
+	return v.repository.GetVoucher().UpdateRedemption(ctx, tx, payment.ID, constants.RedemptionReserved, constants.RedemptionRedeemed)
+}
+
+// Release gives the voucher of a payment closed unpaid back to the customer.
+func (v *VoucherService) Release(ctx context.Context, tx *gorm.DB, payment *models.Payment) error {
+	return v.repository.GetVoucher().UpdateRedemption(ctx, tx, payment.ID, constants.RedemptionReserved, constants.RedemptionReleased)
+}
+
+// Retake reserves the released voucher of a payment that is paid after it
+// expired. The voucher may have been used up by others in the meantime, so
+// the usage limits are checked again under the lock.
+func (v *VoucherService) Retake(ctx context.Context, tx *gorm.DB, payment *models.Payment) error {
+	voucher, err := v.repository.GetVoucher().FindByCode(ctx, *payment.VoucherCode)
+	if err != nil {
+		return err
+	}
+	voucher, err = v.repository.GetVoucher().Lock(ctx, tx, voucher.ID)
+	if err != nil {
+		return err
+	}
+	err = v.checkUsage(ctx, tx, voucher, payment.CustomerID)
+	if err != nil {
+		return err
+	}
+	return v.repository.GetVoucher().UpdateRedemption(ctx, tx, payment.ID, constants.RedemptionReleased, constants.RedemptionReserved)
+}
+
+func (v *VoucherService) check(
+	ctx context.Context,
+	tx *gorm.DB,
+	voucher *models.Voucher,
+	amount money.Money,
+	customerID *uuid.UUID,
+) error {
+	now := time.Now()
+	switch {
+	case !voucher.IsActive:
+		return errVoucher.ErrVoucherInactive
+	case voucher.StartsAt != nil && now.Before(*voucher.StartsAt):
+		return errVoucher.ErrVoucherNotValid
+	case voucher.EndsAt != nil && !now.Before(*voucher.EndsAt):
+		return errVoucher.ErrVoucherNotValid
+	case amount.Currency != voucher.Currency:
+		return errVoucher.ErrVoucherCurrency
+	case amount.Amount < voucher.MinSpend:
+		return errVoucher.ErrVoucherMinSpend
+	}
+	return v.checkUsage(ctx, tx, voucher, customerID)
+}
+
+func (v *VoucherService) checkUsage(ctx context.Context, tx *gorm.DB, voucher *models.Voucher, customerID *uuid.UUID) error {
+	if voucher.PerUserLimit != nil && customerID == nil {
+		return errVoucher.ErrVoucherCustomerRequired
+	}
+	if voucher.UsageLimit != nil {
+		used, err := v.repository.GetVoucher().CountUsage(ctx, tx, voucher.ID, nil)
+		if err != nil {
+			return err
+		}
+		if used >= int64(*voucher.UsageLimit) {
+			return errVoucher.ErrVoucherUsageLimit
+		}
+	}
+	if voucher.PerUserLimit != nil {
+		used, err := v.repository.GetVoucher().CountUsage(ctx, tx, voucher.ID, customerID)
+		if err != nil {
+			return err
+		}
+		if used >= int64(*voucher.PerUserLimit) {
+			return errVoucher.ErrVoucherUserLimit
+		}
+	}
+	return nil
+}
+
+// discount never exceeds the amount and is kept to whole units, gateways that
+// do not accept cents would otherwise reject the discounted amount.
+func (v *VoucherService) discount(voucher *models.Voucher, amount money.Money) money.Money {
+	var discount money.Money
+	if voucher.Percentage != nil {
+		discount = amount.MultiplyRate(*voucher.Percentage / 100)
+	} else {
+		discount = money.New(*voucher.Amount, voucher.Currency)
+	}
+	if voucher.MaxDiscount != nil && discount.Amount > *voucher.MaxDiscount {
+		discount = money.New(*voucher.MaxDiscount, voucher.Currency)
+	}
+	discount = discount.Round(0)
+	if discount.Amount > amount.Amount {
+		return amount
+	}
+	return discount
+}
+
+func (v *VoucherService) toResponse(ctx context.Context, voucher *models.Voucher) (*dto.VoucherResponse, error) {
+	used, err := v.repository.GetVoucher().CountUsage(ctx, v.repository.GetTx(), voucher.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	var value float64
+	if voucher.Percentage != nil {
+		value = *voucher.Percentage
+	} else {
+		value = money.New(*voucher.Amount, voucher.Currency).Major()
+	}
+	var maxDiscount *float64
+	if voucher.MaxDiscount != nil {
+		amount := money.New(*voucher.MaxDiscount, voucher.Currency).Major()
+		maxDiscount = &amount
+	}
+	return &dto.VoucherResponse{
+		UUID:         voucher.UUID,
+		Code:         voucher.Code,
+		Type:         voucher.Type,
+		Value:        value,
+		Currency:     voucher.Currency,
+		MinSpend:     money.New(voucher.MinSpend, voucher.Currency).Major(),
+		MaxDiscount:  maxDiscount,
+		UsageLimit:   voucher.UsageLimit,
+		PerUserLimit: voucher.PerUserLimit,
+		UsedCount:    used,
+		StartsAt:     voucher.StartsAt,
+		EndsAt:       voucher.EndsAt,
+		IsActive:     voucher.IsActive,
+		CreatedAt:    voucher.CreatedAt,
+	}, nil
+}
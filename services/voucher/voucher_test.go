@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"payment-service/common/money"
+	"payment-service/constants"
+	errVoucher "payment-service/constants/error/voucher"
+	"payment-service/domain/dto"
+	"payment-service/domain/models"
+	"payment-service/repositories/fake"
+	repositories "payment-service/repositories/voucher"
+)
+
+type fakeVoucherRepository struct {
+	repositories.IVoucherRepository
+	voucher     *models.Voucher
+	redemptions []dto.VoucherRedemptionRequest
+	// retaken holds the payments whose released redemption was reserved again.
+	retaken []uint
+}
+
+func (f *fakeVoucherRepository) FindByCode(_ context.Context, code string) (*models.Voucher, error) {
+	if code != f.voucher.Code {
+		return nil, errVoucher.ErrVoucherNotFound
+	}
+	return f.voucher, nil
+}
+
+func (f *fakeVoucherRepository) Lock(_ context.Context, _ *gorm.DB, _ uint) (*models.Voucher, error) {
+	return f.voucher, nil
+}
+
+func (f *fakeVoucherRepository) CountUsage(_ context.Context, _ *gorm.DB, _ uint, customerID *uuid.UUID) (int64, error) {
+	var used int64
+	for _, redemption := range f.redemptions {
+		if customerID == nil || (redemption.CustomerID != nil && *redemption.CustomerID == *customerID) {
+			used++
+		}
+	}
+	return used, nil
+}
+
+func (f *fakeVoucherRepository) CreateRedemption(_ context.Context, _ *gorm.DB, request *dto.VoucherRedemptionRequest) error {
+	f.redemptions = append(f.redemptions, *request)
+	return nil
+}
+
+func (f *fakeVoucherRepository) UpdateRedemption(_ context.Context, _ *gorm.DB, paymentID uint, from, to string) error {
+	if from == constants.RedemptionReleased && to == constants.RedemptionReserved {
+		f.retaken = append(f.retaken, paymentID)
+	}
+	return nil
+}
+
+func newTestVoucher(voucher *models.Voucher) (*VoucherService, *fakeVoucherRepository) {
+	repository := &fakeVoucherRepository{voucher: voucher}
+	return &VoucherService{repository: &fake.Registry{Voucher: repository}}, repository
+}
+
+func limit(value int) *int {
+	return &value
+}
+
+func TestQuoteChecksVoucher(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	customerID := uuid.New()
+	percentage := 10.0
+	tests := []struct {
+		name       string
+		voucher    models.Voucher
+		amount     money.Money
+		customerID *uuid.UUID
+		want       error
+	}{
+		{
+			name:    "inactive",
+			voucher: models.Voucher{IsActive: false},
+			amount:  money.New(1000000, "IDR"),
+			want:    errVoucher.ErrVoucherInactive,
+		},
+		{
+			name:    "not started",
+			voucher: models.Voucher{IsActive: true, StartsAt: &future},
+			amount:  money.New(1000000, "IDR"),
+			want:    errVoucher.ErrVoucherNotValid,
+		},
+		{
+			name:    "ended",
+			voucher: models.Voucher{IsActive: true, EndsAt: &past},
+			amount:  money.New(1000000, "IDR"),
+			want:    errVoucher.ErrVoucherNotValid,
+		},
+		{
+			name:    "other currency",
+			voucher: models.Voucher{IsActive: true},
+			amount:  money.New(10000, "USD"),
+			want:    errVoucher.ErrVoucherCurrency,
+		},
+		{
+			name:    "below minimum spend",
+			voucher: models.Voucher{IsActive: true, MinSpend: 5000000},
+			amount:  money.New(1000000, "IDR"),
+			want:    errVoucher.ErrVoucherMinSpend,
+		},
+		{
+			name:    "per user limit without customer",
+			voucher: models.Voucher{IsActive: true, PerUserLimit: limit(1)},
+			amount:  money.New(1000000, "IDR"),
+			want:    errVoucher.ErrVoucherCustomerRequired,
+		},
+		{
+			name:       "usable",
+			voucher:    models.Voucher{IsActive: true, StartsAt: &past, EndsAt: &future, PerUserLimit: limit(1)},
+			amount:     money.New(1000000, "IDR"),
+			customerID: &customerID,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.voucher.Code = "HEMAT10"
+			tt.voucher.Currency = "IDR"
+			tt.voucher.Percentage = &percentage
+			service, _ := newTestVoucher(&tt.voucher)
+			quote, err := service.Quote(context.Background(), " HEMAT10 ", tt.amount, tt.customerID)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Quote error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && quote.Discount.Amount != 100000 {
+				t.Errorf("Quote discount = %d, want 100000", quote.Discount.Amount)
+			}
+		})
+	}
+}
+
+func TestReserveEnforcesLimits(t *testing.T) {
+	first := uuid.New()
+	second := uuid.New()
+	amount := int64(500000)
+	voucher := &models.Voucher{
+		ID:           1,
+		Code:         "HEMAT5K",
+		Amount:       &amount,
+		Currency:     "IDR",
+		MinSpend:     1000000,
+		UsageLimit:   limit(3),
+		PerUserLimit: limit(2),
+		IsActive:     true,
+	}
+	service, repository := newTestVoucher(voucher)
+	quote := &dto.VoucherQuote{VoucherID: voucher.ID, Code: voucher.Code, Amount: money.New(1000000, "IDR"), Discount: money.New(amount, "IDR")}
+
+	reserve := func(customerID uuid.UUID) error {
+		// The payment amount is discounted and taxed, the minimum spend is
+		// checked against the amount quoted on.
+		payment := &models.Payment{ID: uint(len(repository.redemptions) + 1), Amount: 555000, Currency: "IDR", CustomerID: &customerID}
+		return service.Reserve(context.Background(), nil, quote, payment)
+	}
+	steps := []struct {
+		customerID uuid.UUID
+		want       error
+	}{
+		{customerID: first},
+		{customerID: first},
+		{customerID: first, want: errVoucher.ErrVoucherUserLimit},
+		{customerID: second},
+		{customerID: second, want: errVoucher.ErrVoucherUsageLimit},
+	}
+	for i, step := range steps {
+		if err := reserve(step.customerID); !errors.Is(err, step.want) {
+			t.Fatalf("Reserve #%d error = %v, want %v", i+1, err, step.want)
+		}
+	}
+	if len(repository.redemptions) != 3 {
+		t.Errorf("Reserve stored %d redemptions, want 3", len(repository.redemptions))
+	}
+}
+
+func TestRetakeChecksLimits(t *testing.T) {
+	customerID := uuid.New()
+	code := "HEMAT5K"
+	tests := []struct {
+		name  string
+		limit int
+		want  error
+	}{
+		{name: "voucher still available", limit: 2},
+		{name: "voucher used up since", limit: 1, want: errVoucher.ErrVoucherUsageLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voucher := &models.Voucher{ID: 1, Code: code, Currency: "IDR", UsageLimit: limit(tt.limit), IsActive: true}
+			service, repository := newTestVoucher(voucher)
+			other := uuid.New()
+			repository.redemptions = []dto.VoucherRedemptionRequest{{VoucherID: 1, PaymentID: 2, CustomerID: &other}}
+
+			payment := &models.Payment{ID: 1, VoucherCode: &code, CustomerID: &customerID}
+			err := service.Retake(context.Background(), nil, payment)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Retake error = %v, want %v", err, tt.want)
+			}
+			if retaken := len(repository.retaken) == 1; retaken != (tt.want == nil) {
+				t.Errorf("retaken = %v, want the redemption reserved again only within the limit", repository.retaken)
+			}
+		})
+	}
+}
+
+func TestDiscount(t *testing.T) {
+	service, _ := newTestVoucher(nil)
+	percentage := 15.0
+	fixed := int64(2000000)
+	maxDiscount := int64(100000)
+	tests := []struct {
+		name    string
+		voucher models.Voucher
+		amount  int64
+		want    int64
+	}{
+		{name: "percentage in whole units", voucher: models.Voucher{Percentage: &percentage}, amount: 333300, want: 50000},
+		{name: "capped", voucher: models.Voucher{Percentage: &percentage, MaxDiscount: &maxDiscount}, amount: 1000000, want: 100000},
+		{name: "fixed", voucher: models.Voucher{Amount: &fixed}, amount: 5000000, want: 2000000},
+		{name: "never above amount", voucher: models.Voucher{Amount: &fixed}, amount: 1000000, want: 1000000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.voucher.Currency = "IDR"
+			got := service.discount(&tt.voucher, money.New(tt.amount, "IDR"))
+			if got.Amount != tt.want {
+				t.Errorf("discount of %d = %d, want %d", tt.amount, got.Amount, tt.want)
+			}
+		})
+	}
+}